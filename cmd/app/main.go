@@ -4,11 +4,21 @@
 // @host localhost:8081
 // @BasePath /
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT в формате "Bearer <token>"
+
 package main
 
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -17,24 +27,33 @@ import (
 	"time"
 
 	_ "testTask/docs"
+	"testTask/internal/auth"
 	"testTask/internal/config"
 	handlerhttp "testTask/internal/handler/http"
-	"testTask/internal/repository/postgres"
+	"testTask/internal/notifier"
+	"testTask/internal/publisher"
 	"testTask/internal/service"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
 func main() {
-	// Logger
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+	// Logger: у app subscriptions в stdout только результат команды
+	logOutput := os.Stdout
+	cli := len(os.Args) > 1 && os.Args[1] == cliCommand
+	if cli {
+		logOutput = os.Stderr
+	}
+
+	logger := slog.New(slog.NewJSONHandler(logOutput, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
 	}))
 	slog.SetDefault(logger)
 
-	slog.Info("starting application")
+	if !cli {
+		slog.Info("starting application")
+	}
 
 	// Config
 	cfg, err := config.LoadConfig()
@@ -47,36 +66,146 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	// DB
-	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
+	// Migrations: app migrate up|down|status|force
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(ctx, cfg, os.Args[2:]); err != nil {
+			slog.Error("migrate failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	db, err := pgxpool.New(dbCtx, cfg.DatabaseURL())
-	if err != nil {
-		slog.Error("failed to create db pool", "error", err)
-		os.Exit(1)
+	// Admin CLI: app subscriptions create|list|get|update|delete|total
+	if cli {
+		err := runSubscriptions(ctx, cfg, os.Args[2:])
+		if errors.Is(err, errSubscriptionsUsage) {
+			fmt.Fprintln(os.Stderr, subscriptionsUsage)
+			os.Exit(2)
+		}
+		if err != nil {
+			slog.Error("subscriptions command failed", "error", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	if err := db.Ping(dbCtx); err != nil {
-		slog.Error("failed to ping database", "error", err)
+	// Storage
+	repos, closeStorage, err := openStorage(ctx, cfg)
+	if err != nil {
+		slog.Error("failed to open storage", "error", err)
 		os.Exit(1)
 	}
-
-	defer db.Close()
-	slog.Info("connected to database")
+	defer closeStorage()
 
 	// Layers
-	repo := postgres.NewSubscriptionRepository(db)
-	svc := service.NewSubscriptionService(repo)
+	svc := newSubscriptionService(cfg, repos)
 	h := handlerhttp.NewHandler(svc)
+	auditHandler := handlerhttp.NewAuditHandler(svc)
+	webhooksHandler := handlerhttp.NewWebhookHandler(service.NewWebhookService(repos.webhooks, repos.deliveries))
+	servicesHandler := handlerhttp.NewServiceHandler(service.NewCatalogService(repos.services))
+	ratesHandler := handlerhttp.NewExchangeRateHandler(service.NewExchangeRateService(repos.rates))
+
+	// Auth
+	jwtConfig := auth.JWTConfig{
+		HMACSecret: []byte(cfg.JWTHMACSecret),
+		Issuer:     cfg.JWTIssuer,
+		Audience:   cfg.JWTAudience,
+	}
+	if cfg.JWTRSAPublicKeyFile != "" {
+		jwtConfig.RSAPublicKeyPEM, err = os.ReadFile(cfg.JWTRSAPublicKeyFile)
+		if err != nil {
+			slog.Error("failed to read JWT public key", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	jwtVerifier, err := auth.NewJWTVerifier(jwtConfig)
+	if err != nil {
+		slog.Error("failed to configure JWT", "error", err)
+		os.Exit(1)
+	}
+	if !jwtVerifier.Enabled() {
+		slog.Warn("JWT keys are not configured, only API keys are accepted")
+	}
+
+	authService := service.NewAuthService(repos.apiKeys, jwtVerifier, cfg.MultiTenant)
+	apiKeysHandler := handlerhttp.NewAPIKeyHandler(authService)
 
 	// Router
 	router := chi.NewRouter()
+	router.Use(handlerhttp.RequestIDMiddleware)
 	router.Use(handlerhttp.LoggingMiddleware)
+	router.NotFound(handlerhttp.NotFoundHandler)
+	router.MethodNotAllowed(handlerhttp.MethodNotAllowedHandler)
 
-	h.RegisterRoutes(router)
 	router.Get("/swagger/*", httpSwagger.WrapHandler)
 
+	router.Group(func(r chi.Router) {
+		r.Use(handlerhttp.AuthMiddleware(authService, cfg.TrustedUserHeader))
+
+		h.RegisterRoutes(r)
+		auditHandler.RegisterRoutes(r)
+		webhooksHandler.RegisterRoutes(r)
+		servicesHandler.RegisterRoutes(r)
+		ratesHandler.RegisterRoutes(r)
+		apiKeysHandler.RegisterRoutes(r)
+	})
+
+	// Outbox
+	relayDone := make(chan struct{})
+	if cfg.OutboxPublisher == config.OutboxPublisherNone {
+		slog.Warn("outbox relay is disabled, events are not published")
+		close(relayDone)
+	} else {
+		eventPublisher, err := newPublisher(cfg)
+		if err != nil {
+			slog.Error("failed to configure event publisher", "error", err)
+			os.Exit(1)
+		}
+
+		relay := service.NewOutboxRelay(repos.outbox, eventPublisher, cfg.OutboxPollInterval)
+		go func() {
+			defer close(relayDone)
+			relay.Run(ctx)
+		}()
+	}
+
+	// Webhooks
+	dispatcherDone := make(chan struct{})
+	dispatcher := service.NewWebhookDispatcher(
+		repos.deliveries,
+		publisher.NewWebhookClient(cfg.WebhookTimeout),
+		cfg.WebhookMaxAttempts,
+		cfg.WebhookPollInterval,
+	)
+	go func() {
+		defer close(dispatcherDone)
+		dispatcher.Run(ctx)
+	}()
+
+	// Renewal reminders
+	reminderNotifier, err := newNotifier(cfg)
+	if err != nil {
+		slog.Error("failed to configure reminder notifier", "error", err)
+		os.Exit(1)
+	}
+
+	schedulerDone := make(chan struct{})
+	scheduler := service.NewReminderScheduler(
+		repos.reminders,
+		repos.prices,
+		repos.outbox,
+		repos.deliveries,
+		repos.transactor,
+		reminderNotifier,
+		cfg.ReminderDaysBefore,
+		cfg.ReminderInterval,
+	)
+	go func() {
+		defer close(schedulerDone)
+		scheduler.Run(ctx)
+	}()
+
 	// HTTP Server
 	server := &http.Server{
 		Addr:         ":" + cfg.AppPort,
@@ -107,4 +236,56 @@ func main() {
 	} else {
 		slog.Info("server stopped gracefully")
 	}
+
+	<-relayDone
+	<-dispatcherDone
+	<-schedulerDone
+}
+
+func newSubscriptionService(cfg config.Config, repos *repositories) *service.SubscriptionService {
+	return service.NewSubscriptionService(
+		repos.subscriptions,
+		repos.services,
+		repos.rates,
+		repos.prices,
+		repos.audit,
+		repos.outbox,
+		repos.deliveries,
+		repos.transactor,
+		cfg.SoftDeleteRetention,
+	)
+}
+
+func newPublisher(cfg config.Config) (publisher.Publisher, error) {
+	const timeout = 10 * time.Second
+
+	switch cfg.OutboxPublisher {
+	case config.OutboxPublisherWebhook:
+		return publisher.NewWebhookPublisher(cfg.OutboxWebhookURL, []byte(cfg.OutboxWebhookSecret), timeout), nil
+	case config.OutboxPublisherNATS:
+		return publisher.NewNATSPublisher(cfg.OutboxNATSURL, cfg.OutboxNATSSubject, timeout)
+	default:
+		return publisher.NewLogPublisher(), nil
+	}
+}
+
+func newNotifier(cfg config.Config) (notifier.Notifier, error) {
+	const timeout = 10 * time.Second
+
+	switch cfg.ReminderNotifier {
+	case config.ReminderNotifierSMTP:
+		return notifier.NewSMTPNotifier(
+			cfg.ReminderSMTPAddr,
+			cfg.ReminderSMTPUsername,
+			cfg.ReminderSMTPPassword,
+			cfg.ReminderSMTPFrom,
+			cfg.ReminderSMTPTo,
+		)
+	case config.ReminderNotifierWebhook:
+		return notifier.NewWebhookNotifier(cfg.ReminderWebhookURL, []byte(cfg.ReminderWebhookSecret), timeout), nil
+	case config.ReminderNotifierNone:
+		return notifier.NewNopNotifier(), nil
+	default:
+		return notifier.NewLogNotifier(), nil
+	}
 }
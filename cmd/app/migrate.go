@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"testTask/internal/config"
+	"testTask/internal/repository/postgres"
+	"testTask/migrations"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const migrateUsage = "usage: app migrate up | down [N] | status | force VERSION"
+
+// runMigrate выполняет подкоманду app migrate над встроенными миграциями
+func runMigrate(ctx context.Context, cfg config.Config, args []string) error {
+	if cfg.Storage != config.StoragePostgres {
+		return errors.New("migrate requires STORAGE=postgres")
+	}
+
+	// аргументы проверяются до подключения к БД
+	var (
+		command string
+		number  int64
+		err     error
+	)
+	switch {
+	case len(args) == 1 && (args[0] == "up" || args[0] == "status"):
+		command = args[0]
+	case len(args) == 1 && args[0] == "down":
+		command, number = "down", 1
+	case len(args) == 2 && args[0] == "down":
+		command = "down"
+		if number, err = strconv.ParseInt(args[1], 10, 0); err != nil || number < 1 {
+			return fmt.Errorf("invalid number of migrations: %s", args[1])
+		}
+	case len(args) == 2 && args[0] == "force":
+		command = "force"
+		if number, err = strconv.ParseInt(args[1], 10, 64); err != nil || number < 0 {
+			return fmt.Errorf("invalid migration version: %s", args[1])
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	db, err := openPostgres(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if command == "up" {
+		return migrateUp(ctx, db)
+	}
+
+	migrator, err := postgres.NewMigrator(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "down":
+		reverted, err := migrator.Down(ctx, int(number))
+		if err != nil {
+			return err
+		}
+		slog.Info("migrations reverted", "count", reverted)
+
+	case "status":
+		current, statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printMigrationStatus(current, statuses)
+
+	case "force":
+		if err := migrator.Force(ctx, number); err != nil {
+			return err
+		}
+		slog.Info("schema version forced", "version", number)
+	}
+
+	return nil
+}
+
+// migrateUp применяет миграции при запуске приложения (MIGRATE_ON_START)
+func migrateUp(ctx context.Context, db *pgxpool.Pool) error {
+	migrator, err := postgres.NewMigrator(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	slog.Info("database schema is up to date", "applied", applied)
+
+	return nil
+}
+
+func printMigrationStatus(current postgres.SchemaVersion, statuses []postgres.MigrationStatus) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "schema version:\t%d", current.Version)
+	if current.Dirty {
+		fmt.Fprint(w, " (dirty)")
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w)
+
+	for _, s := range statuses {
+		state := "pending"
+		if s.Applied {
+			state = "applied"
+		}
+		fmt.Fprintf(w, "%04d_%s\t%s\n", s.Version, s.Name, state)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"testTask/internal/domain"
+	handlerhttp "testTask/internal/handler/http"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+)
+
+// output результат команды app subscriptions: таблица для человека, JSON в формате
+// ответов API или CSV для таблиц
+type output struct {
+	w      io.Writer
+	format string
+}
+
+func newOutput(w io.Writer, format string) (*output, error) {
+	switch format {
+	case outputTable, outputJSON, outputCSV:
+		return &output{w: w, format: format}, nil
+	default:
+		return nil, fmt.Errorf("invalid output format %q, expected table, json or csv", format)
+	}
+}
+
+// write выводит value в JSON или строки rows с заголовком header
+func (o *output) write(value any, header []string, rows [][]string) error {
+	switch o.format {
+	case outputJSON:
+		enc := json.NewEncoder(o.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(value)
+
+	case outputCSV:
+		w := csv.NewWriter(o.w)
+		lower := make([]string, 0, len(header))
+		for _, h := range header {
+			lower = append(lower, strings.ToLower(h))
+		}
+		if err := w.Write(lower); err != nil {
+			return err
+		}
+		if err := w.WriteAll(rows); err != nil {
+			return err
+		}
+		return w.Error()
+
+	default:
+		w := tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+	}
+}
+
+// subscriptions выводит подписки; value - то, что выводится в JSON
+func (o *output) subscriptions(subs []domain.Subscription, value any) error {
+	header := []string{"ID", "SERVICE", "USER_ID", "PRICE", "CURRENCY", "PERIOD", "START", "END", "STATUS", "DELETED"}
+
+	rows := make([][]string, 0, len(subs))
+	for _, s := range subs {
+		rows = append(rows, []string{
+			s.ID.String(),
+			s.ServiceName,
+			s.UserID.String(),
+			strconv.Itoa(s.Price),
+			s.Currency,
+			string(s.BillingPeriod),
+			s.StartDate.Format(handlerhttp.DateFormatFromRequest),
+			formatMonth(s.EndDate),
+			string(s.Status),
+			formatTime(s.DeletedAt),
+		})
+	}
+
+	if value == nil {
+		value = []domain.Subscription{}
+	}
+
+	return o.write(value, header, rows)
+}
+
+func formatMonth(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(handlerhttp.DateFormatFromRequest)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"testTask/internal/config"
+	"testTask/internal/repository"
+	"testTask/internal/repository/memory"
+	"testTask/internal/repository/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// repositories реализации репозиториев выбранного хранилища (STORAGE)
+type repositories struct {
+	transactor    repository.Transactor
+	subscriptions repository.SubscriptionRepository
+	services      repository.ServiceRepository
+	rates         repository.ExchangeRateRepository
+	prices        repository.PriceHistoryRepository
+	audit         repository.AuditRepository
+	outbox        repository.OutboxRepository
+	webhooks      repository.WebhookRepository
+	deliveries    repository.WebhookDeliveryRepository
+	apiKeys       repository.APIKeyRepository
+	reminders     repository.ReminderRepository
+}
+
+// openStorage подключает хранилище; возвращаемая функция освобождает его при остановке
+func openStorage(ctx context.Context, cfg config.Config) (*repositories, func(), error) {
+	if cfg.Storage == config.StorageMemory {
+		slog.Warn("using in-memory storage, data is lost on restart")
+		return newMemoryRepositories(memory.NewStore()), func() {}, nil
+	}
+
+	db, err := openPostgres(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.MigrateOnStart {
+		if err := migrateUp(ctx, db); err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+	}
+
+	return newPostgresRepositories(db), db.Close, nil
+}
+
+// openPostgres создает пул соединений и проверяет доступность БД
+func openPostgres(ctx context.Context, cfg config.Config) (*pgxpool.Pool, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	poolConfig, err := pgxpool.ParseConfig(cfg.DatabaseURL())
+	if err != nil {
+		return nil, fmt.Errorf("invalid database config: %w", err)
+	}
+	if cfg.DBRowLevelSecurity {
+		postgres.EnableRowLevelSecurity(poolConfig)
+	}
+
+	db, err := pgxpool.NewWithConfig(dbCtx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create db pool: %w", err)
+	}
+
+	if err := db.Ping(dbCtx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	slog.Info("connected to database")
+
+	return db, nil
+}
+
+func newPostgresRepositories(db *pgxpool.Pool) *repositories {
+	return &repositories{
+		transactor:    postgres.NewTransactor(db),
+		subscriptions: postgres.NewSubscriptionRepository(db),
+		services:      postgres.NewServiceRepository(db),
+		rates:         postgres.NewExchangeRateRepository(db),
+		prices:        postgres.NewPriceHistoryRepository(db),
+		audit:         postgres.NewAuditRepository(db),
+		outbox:        postgres.NewOutboxRepository(db),
+		webhooks:      postgres.NewWebhookRepository(db),
+		deliveries:    postgres.NewWebhookDeliveryRepository(db),
+		apiKeys:       postgres.NewAPIKeyRepository(db),
+		reminders:     postgres.NewReminderRepository(db),
+	}
+}
+
+func newMemoryRepositories(store *memory.Store) *repositories {
+	return &repositories{
+		transactor:    memory.NewTransactor(store),
+		subscriptions: memory.NewSubscriptionRepository(store),
+		services:      memory.NewServiceRepository(store),
+		rates:         memory.NewExchangeRateRepository(store),
+		prices:        memory.NewPriceHistoryRepository(store),
+		audit:         memory.NewAuditRepository(store),
+		outbox:        memory.NewOutboxRepository(store),
+		webhooks:      memory.NewWebhookRepository(store),
+		deliveries:    memory.NewWebhookDeliveryRepository(store),
+		apiKeys:       memory.NewAPIKeyRepository(store),
+		reminders:     memory.NewReminderRepository(store),
+	}
+}
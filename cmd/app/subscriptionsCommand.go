@@ -0,0 +1,501 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"testTask/internal/config"
+	"testTask/internal/domain"
+	"testTask/internal/dto"
+	handlerhttp "testTask/internal/handler/http"
+	"testTask/internal/service"
+
+	"github.com/google/uuid"
+)
+
+const subscriptionsUsage = `usage: app subscriptions <command> [flags]
+
+commands:
+  create  --service NAME --price N --user UUID --start MONTH [--end MONTH] [--trial MONTH]
+          [--currency CODE] [--period week|month|quarter|year] [--service-id UUID]
+  list    [--user UUID] [--service NAME] [--q TEXT] [--active-on MONTH] [--include-deleted]
+          [--sort start_date|price|service_name|created_at] [--desc] [--limit N] [--all]
+  get     ID
+  update  ID [--service NAME | --service-id UUID] [--price N] [--currency CODE] [--period P]
+          [--start MONTH] [--end MONTH|none] [--trial MONTH|none]
+  delete  ID
+  total   --from MONTH --to MONTH [--user UUID] [--service NAME] [--currency CODE]
+          [--group-by service_name,user_id] [--breakdown]
+
+flags of every command:
+  --output table|json|csv  output format (default table)
+  --tenant ID              tenant (default "default")
+  --actor NAME             actor in the audit log (default OS user)
+
+MONTH is YYYY-MM or MM-YYYY; prices are in minor units (kopecks, cents)`
+
+// errSubscriptionsUsage неизвестная команда: вместо ошибки выводится справка
+var errSubscriptionsUsage = errors.New("invalid subscriptions command")
+
+// cliCommand подкоманда администрирования подписок из shell, без HTTP
+const cliCommand = "subscriptions"
+
+// cliOptions флаги, общие для всех команд app subscriptions
+type cliOptions struct {
+	output string
+	tenant string
+	actor  string
+}
+
+func (o *cliOptions) register(fs *flag.FlagSet) {
+	actor := "unknown"
+	if u, err := user.Current(); err == nil {
+		actor = u.Username
+	}
+
+	fs.StringVar(&o.output, "output", outputTable, "output format: table, json or csv")
+	fs.StringVar(&o.tenant, "tenant", domain.DefaultTenant, "tenant id")
+	fs.StringVar(&o.actor, "actor", actor, "actor recorded in the audit log")
+}
+
+// principal администратор арендатора; в журнале аудита - "cli:<actor>"
+func (o *cliOptions) principal(cfg config.Config) (*domain.Principal, error) {
+	if err := domain.ValidateTenantID(o.tenant); err != nil {
+		return nil, err
+	}
+	if !cfg.MultiTenant && o.tenant != domain.DefaultTenant {
+		return nil, errors.New("--tenant requires MULTI_TENANT=true")
+	}
+
+	return &domain.Principal{
+		Subject:     "cli:" + o.actor,
+		Method:      domain.AuthMethodCLI,
+		Admin:       true,
+		TenantID:    o.tenant,
+		TenantFixed: true,
+	}, nil
+}
+
+// subscriptionsCommand команда над подписками; flags регистрирует ее флаги
+type subscriptionsCommand struct {
+	flags    func(fs *flag.FlagSet, c *cliCall)
+	validate func(c *cliCall) error
+	run      func(ctx context.Context, svc *service.SubscriptionService, c *cliCall) error
+	// команда принимает ID подписки первым аргументом
+	withID bool
+}
+
+// cliCall разобранные аргументы одного вызова команды
+type cliCall struct {
+	id  uuid.UUID
+	out *output
+	// флаги, явно заданные в командной строке
+	set map[string]bool
+
+	sub       domain.Subscription
+	list      domain.ListFilter
+	total     domain.TotalFilter
+	all       bool
+	breakdown bool
+}
+
+var subscriptionsCommands = map[string]subscriptionsCommand{
+	"create": {flags: subscriptionFlags, run: cliCreate},
+	"list":   {flags: listFlags, validate: validateList, run: cliList},
+	"get":    {run: cliGet, withID: true},
+	"update": {flags: subscriptionFlags, run: cliUpdate, withID: true},
+	"delete": {run: cliDelete, withID: true},
+	"total":  {flags: totalFlags, validate: validateTotal, run: cliTotal},
+}
+
+// runSubscriptions выполняет app subscriptions <command> через SubscriptionService
+// от имени администратора арендатора, с записью изменений в журнал аудита и outbox
+func runSubscriptions(ctx context.Context, cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return errSubscriptionsUsage
+	}
+
+	cmd, ok := subscriptionsCommands[args[0]]
+	if !ok {
+		return errSubscriptionsUsage
+	}
+
+	// аргументы проверяются до подключения к хранилищу
+	fs := flag.NewFlagSet(cliCommand+" "+args[0], flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var (
+		opts cliOptions
+		call = &cliCall{set: make(map[string]bool)}
+	)
+	opts.register(fs)
+	if cmd.flags != nil {
+		cmd.flags(fs, call)
+	}
+
+	rest := args[1:]
+	if cmd.withID {
+		if len(rest) == 0 || strings.HasPrefix(rest[0], "-") {
+			return fmt.Errorf("%s: subscription id is required", args[0])
+		}
+
+		id, err := uuid.Parse(rest[0])
+		if err != nil {
+			return fmt.Errorf("invalid subscription id %q", rest[0])
+		}
+		call.id = id
+		rest = rest[1:]
+	}
+
+	if err := fs.Parse(rest); err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("%s: unexpected argument %q", args[0], fs.Arg(0))
+	}
+	fs.Visit(func(f *flag.Flag) { call.set[f.Name] = true })
+
+	if cmd.validate != nil {
+		if err := cmd.validate(call); err != nil {
+			return err
+		}
+	}
+
+	out, err := newOutput(os.Stdout, opts.output)
+	if err != nil {
+		return err
+	}
+	call.out = out
+
+	if cfg.Storage != config.StoragePostgres {
+		return errors.New("subscriptions commands require STORAGE=postgres")
+	}
+
+	p, err := opts.principal(cfg)
+	if err != nil {
+		return err
+	}
+	ctx = domain.WithPrincipal(ctx, p)
+
+	repos, closeStorage, err := openStorage(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStorage()
+
+	return cmd.run(ctx, newSubscriptionService(cfg, repos), call)
+}
+
+// monthFlag флаг с месяцем YYYY-MM или MM-YYYY; "none" - без даты, если allowNone
+func monthFlag(fs *flag.FlagSet, name, usage string, dest **time.Time, allowNone bool) {
+	fs.Func(name, usage, func(v string) error {
+		if allowNone && v == "none" {
+			*dest = nil
+			return nil
+		}
+
+		t, err := parseMonth(v)
+		if err != nil {
+			return err
+		}
+		*dest = &t
+
+		return nil
+	})
+}
+
+func parseMonth(v string) (time.Time, error) {
+	for _, layout := range []string{"2006-01", handlerhttp.DateFormatFromRequest} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid month %q, expected YYYY-MM or MM-YYYY", v)
+}
+
+func uuidFlag(fs *flag.FlagSet, name, usage string, dest *uuid.UUID) {
+	fs.Func(name, usage, func(v string) error {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return fmt.Errorf("invalid UUID %q", v)
+		}
+		*dest = id
+
+		return nil
+	})
+}
+
+func uuidPtrFlag(fs *flag.FlagSet, name, usage string, dest **uuid.UUID) {
+	fs.Func(name, usage, func(v string) error {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return fmt.Errorf("invalid UUID %q", v)
+		}
+		*dest = &id
+
+		return nil
+	})
+}
+
+func stringPtrFlag(fs *flag.FlagSet, name, usage string, dest **string) {
+	fs.Func(name, usage, func(v string) error {
+		*dest = &v
+		return nil
+	})
+}
+
+// subscriptionFlags поля подписки для create и update
+func subscriptionFlags(fs *flag.FlagSet, c *cliCall) {
+	fs.StringVar(&c.sub.ServiceName, "service", "", "service name or alias from the catalog")
+	uuidFlag(fs, "service-id", "service id from the catalog", &c.sub.ServiceID)
+	fs.IntVar(&c.sub.Price, "price", 0, "price in minor units; 0 - default price of the service")
+	fs.StringVar(&c.sub.Currency, "currency", "", "ISO 4217 currency code")
+	fs.Func("period", "billing period: week, month, quarter or year", func(v string) error {
+		c.sub.BillingPeriod = domain.BillingPeriod(v)
+		return nil
+	})
+	uuidFlag(fs, "user", "subscription owner", &c.sub.UserID)
+	fs.Func("start", "start month", func(v string) error {
+		t, err := parseMonth(v)
+		c.sub.StartDate = t
+		return err
+	})
+	monthFlag(fs, "end", "end month, none - open-ended", &c.sub.EndDate, true)
+	monthFlag(fs, "trial", "first paid month, none - no trial", &c.sub.TrialUntil, true)
+}
+
+func listFlags(fs *flag.FlagSet, c *cliCall) {
+	c.list = domain.ListFilter{Sort: domain.SortByCreatedAt, Limit: domain.DefaultListLimit}
+
+	uuidPtrFlag(fs, "user", "owner of subscriptions", &c.list.UserID)
+	stringPtrFlag(fs, "service", "service name or alias", &c.list.ServiceName)
+	fs.StringVar(&c.list.Search, "q", "", "substring of service name or alias")
+	monthFlag(fs, "active-on", "subscriptions active in this month", &c.list.ActiveOn, false)
+	fs.BoolVar(&c.list.IncludeDeleted, "include-deleted", false, "include deleted subscriptions")
+	fs.Func("sort", "start_date, price, service_name or created_at", func(v string) error {
+		c.list.Sort = domain.ListSort(v)
+		return nil
+	})
+	fs.BoolVar(&c.list.Desc, "desc", false, "descending order")
+	fs.IntVar(&c.list.Limit, "limit", domain.DefaultListLimit, "page size")
+	fs.BoolVar(&c.all, "all", false, "fetch all pages")
+}
+
+func totalFlags(fs *flag.FlagSet, c *cliCall) {
+	c.total = domain.TotalFilter{Currency: domain.DefaultCurrency}
+
+	fs.Func("from", "first month of the period", func(v string) error {
+		t, err := parseMonth(v)
+		c.total.From = t
+		return err
+	})
+	fs.Func("to", "last month of the period", func(v string) error {
+		t, err := parseMonth(v)
+		c.total.To = t
+		return err
+	})
+	uuidPtrFlag(fs, "user", "owner of subscriptions", &c.total.UserID)
+	stringPtrFlag(fs, "service", "service name or alias", &c.total.ServiceName)
+	fs.StringVar(&c.total.Currency, "currency", domain.DefaultCurrency, "report currency")
+	fs.Func("group-by", "service_name, user_id or both separated by comma", func(v string) error {
+		for _, g := range strings.Split(v, ",") {
+			if g = strings.TrimSpace(g); g != "" {
+				c.total.GroupBy = append(c.total.GroupBy, domain.TotalGroupBy(g))
+			}
+		}
+		return nil
+	})
+	fs.BoolVar(&c.breakdown, "breakdown", false, "monthly breakdown")
+}
+
+func cliCreate(ctx context.Context, svc *service.SubscriptionService, c *cliCall) error {
+	sub := c.sub
+	if sub.BillingPeriod == "" {
+		sub.BillingPeriod = domain.BillingPeriodMonth
+	}
+
+	if err := svc.Create(ctx, &sub); err != nil {
+		return err
+	}
+
+	return c.out.subscriptions([]domain.Subscription{sub}, &sub)
+}
+
+func validateList(c *cliCall) error {
+	return c.list.Validate()
+}
+
+func cliList(ctx context.Context, svc *service.SubscriptionService, c *cliCall) error {
+	var subs []domain.Subscription
+	for filter := c.list; ; {
+		page, err := svc.List(ctx, &filter)
+		if err != nil {
+			return err
+		}
+		subs = append(subs, page.Items...)
+
+		if !c.all || page.NextCursor == nil {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	return c.out.subscriptions(subs, subs)
+}
+
+func cliGet(ctx context.Context, svc *service.SubscriptionService, c *cliCall) error {
+	sub, err := svc.Get(ctx, c.id)
+	if err != nil {
+		return err
+	}
+
+	return c.out.subscriptions([]domain.Subscription{*sub}, sub)
+}
+
+// cliUpdate меняет только поля, заданные флагами, как PATCH /subscriptions/{id}
+func cliUpdate(ctx context.Context, svc *service.SubscriptionService, c *cliCall) error {
+	if c.set["user"] {
+		return errors.New("update: the owner of a subscription cannot be changed")
+	}
+
+	sub, err := svc.Get(ctx, c.id)
+	if err != nil {
+		return err
+	}
+
+	// сервис заново ищется в каталоге по новому ID или названию
+	if c.set["service-id"] {
+		sub.ServiceID = c.sub.ServiceID
+	} else if c.set["service"] {
+		sub.ServiceID = uuid.Nil
+		sub.ServiceName = c.sub.ServiceName
+	}
+	if c.set["price"] {
+		sub.Price = c.sub.Price
+	}
+	if c.set["currency"] {
+		sub.Currency = c.sub.Currency
+	}
+	if c.set["period"] {
+		sub.BillingPeriod = c.sub.BillingPeriod
+	}
+	if c.set["start"] {
+		sub.StartDate = c.sub.StartDate
+	}
+	if c.set["end"] {
+		sub.EndDate = c.sub.EndDate
+	}
+	if c.set["trial"] {
+		sub.TrialUntil = c.sub.TrialUntil
+	}
+
+	if err := svc.Update(ctx, sub); err != nil {
+		return err
+	}
+
+	return c.out.subscriptions([]domain.Subscription{*sub}, sub)
+}
+
+func cliDelete(ctx context.Context, svc *service.SubscriptionService, c *cliCall) error {
+	if err := svc.Delete(ctx, c.id); err != nil {
+		return err
+	}
+
+	return c.out.write(
+		map[string]string{"deleted": c.id.String()},
+		[]string{"DELETED"},
+		[][]string{{c.id.String()}},
+	)
+}
+
+func validateTotal(c *cliCall) error {
+	if c.breakdown && len(c.total.GroupBy) > 0 {
+		return errors.New("total: --breakdown cannot be combined with --group-by")
+	}
+
+	return c.total.Validate()
+}
+
+func cliTotal(ctx context.Context, svc *service.SubscriptionService, c *cliCall) error {
+	filter := c.total
+
+	switch {
+	case c.breakdown:
+		months, err := svc.CalculateMonthlyBreakdown(ctx, &filter)
+		if err != nil {
+			return err
+		}
+
+		resp := make([]dto.MonthlyTotalResponse, 0, len(months))
+		rows := make([][]string, 0, len(months))
+		for _, mt := range months {
+			resp = append(resp, dto.MonthlyTotalResponse{
+				Month:         mt.Month.Format(handlerhttp.DateFormatFromRequest),
+				Amount:        mt.Amount,
+				Currency:      mt.Currency,
+				Subscriptions: mt.Subscriptions,
+			})
+			rows = append(rows, []string{
+				mt.Month.Format(handlerhttp.DateFormatFromRequest),
+				strconv.Itoa(mt.Amount),
+				mt.Currency,
+				strconv.Itoa(mt.Subscriptions),
+			})
+		}
+
+		return c.out.write(resp, []string{"MONTH", "AMOUNT", "CURRENCY", "SUBSCRIPTIONS"}, rows)
+
+	case len(filter.GroupBy) > 0:
+		groups, err := svc.CalculateGroupedTotal(ctx, &filter)
+		if err != nil {
+			return err
+		}
+
+		resp := make([]dto.GroupedTotalResponse, 0, len(groups))
+		rows := make([][]string, 0, len(groups))
+		for _, g := range groups {
+			key := make(map[string]string, len(filter.GroupBy))
+			if g.ServiceName != nil {
+				key[string(domain.GroupByServiceName)] = *g.ServiceName
+			}
+			if g.UserID != nil {
+				key[string(domain.GroupByUserID)] = g.UserID.String()
+			}
+			resp = append(resp, dto.GroupedTotalResponse{Key: key, Total: g.Total, Currency: g.Currency})
+
+			row := make([]string, 0, len(filter.GroupBy)+2)
+			for _, field := range filter.GroupBy {
+				row = append(row, key[string(field)])
+			}
+			rows = append(rows, append(row, strconv.Itoa(g.Total), g.Currency))
+		}
+
+		header := make([]string, 0, len(filter.GroupBy)+2)
+		for _, field := range filter.GroupBy {
+			header = append(header, strings.ToUpper(string(field)))
+		}
+
+		return c.out.write(resp, append(header, "TOTAL", "CURRENCY"), rows)
+
+	default:
+		total, err := svc.CalculateTotal(ctx, &filter)
+		if err != nil {
+			return err
+		}
+
+		return c.out.write(
+			dto.TotalResponse{Total: total, Currency: filter.Currency},
+			[]string{"TOTAL", "CURRENCY"},
+			[][]string{{strconv.Itoa(total), filter.Currency}},
+		)
+	}
+}
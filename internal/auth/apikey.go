@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// Формат ключа: sk_<prefix>_<secret>. По prefix ключ ищется в БД,
+// secret хранится только в виде SHA-256 от всего ключа. Секрет случайный
+// и длинный, поэтому медленный хеш вроде bcrypt не нужен
+const apiKeyScheme = "sk"
+
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+// GenerateAPIKey выпускает новый ключ и возвращает его вместе с prefix и хешем для хранения
+func GenerateAPIKey() (key, prefix string, hash []byte, err error) {
+	prefixBytes := make([]byte, 6)
+	secretBytes := make([]byte, 32)
+
+	if _, err = rand.Read(prefixBytes); err != nil {
+		return "", "", nil, err
+	}
+	if _, err = rand.Read(secretBytes); err != nil {
+		return "", "", nil, err
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	key = apiKeyScheme + "_" + prefix + "_" + base64.RawURLEncoding.EncodeToString(secretBytes)
+
+	return key, prefix, HashAPIKey(key), nil
+}
+
+// ParseAPIKey возвращает prefix ключа для поиска в БД
+func ParseAPIKey(key string) (string, error) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyScheme || parts[1] == "" || parts[2] == "" {
+		return "", ErrInvalidAPIKey
+	}
+
+	return parts[1], nil
+}
+
+func HashAPIKey(key string) []byte {
+	sum := sha256.Sum256([]byte(key))
+	return sum[:]
+}
+
+// MatchAPIKey сравнивает ключ с сохраненным хешем за постоянное время
+func MatchAPIKey(key string, hash []byte) bool {
+	return subtle.ConstantTimeCompare(HashAPIKey(key), hash) == 1
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, hash, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey: %v", err)
+	}
+
+	if !strings.HasPrefix(key, apiKeyScheme+"_"+prefix+"_") {
+		t.Errorf("key %q does not start with prefix %q", key, prefix)
+	}
+
+	parsed, err := ParseAPIKey(key)
+	if err != nil || parsed != prefix {
+		t.Errorf("ParseAPIKey = %q, %v; want %q", parsed, err, prefix)
+	}
+
+	if !MatchAPIKey(key, hash) {
+		t.Error("key does not match its hash")
+	}
+
+	other, _, _, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey: %v", err)
+	}
+	if other == key || MatchAPIKey(other, hash) {
+		t.Error("different keys match the same hash")
+	}
+}
+
+func TestParseAPIKey(t *testing.T) {
+	tests := []struct {
+		key    string
+		prefix string
+	}{
+		{"sk_a1b2c3_secret", "a1b2c3"},
+		{"sk_a1b2c3_secret_with_underscores", "a1b2c3"},
+		{"", ""},
+		{"sk_a1b2c3", ""},
+		{"sk__secret", ""},
+		{"sk_a1b2c3_", ""},
+		{"pk_a1b2c3_secret", ""},
+		{"Bearer sk_a1b2c3_secret", ""},
+	}
+
+	for _, tt := range tests {
+		prefix, err := ParseAPIKey(tt.key)
+		if tt.prefix == "" {
+			if !errors.Is(err, ErrInvalidAPIKey) {
+				t.Errorf("ParseAPIKey(%q) error %v, want %v", tt.key, err, ErrInvalidAPIKey)
+			}
+			continue
+		}
+
+		if err != nil || prefix != tt.prefix {
+			t.Errorf("ParseAPIKey(%q) = %q, %v; want %q", tt.key, prefix, err, tt.prefix)
+		}
+	}
+}
+
+func TestMatchAPIKey(t *testing.T) {
+	hash := HashAPIKey("sk_a1b2c3_secret")
+
+	for key, want := range map[string]bool{
+		"sk_a1b2c3_secret":  true,
+		"sk_a1b2c3_secreT":  false,
+		"sk_a1b2c3_secret ": false,
+		"":                  false,
+	} {
+		if got := MatchAPIKey(key, hash); got != want {
+			t.Errorf("MatchAPIKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+
+	if MatchAPIKey("sk_a1b2c3_secret", nil) {
+		t.Error("key matches empty hash")
+	}
+}
@@ -0,0 +1,234 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// допустимое расхождение часов с выпускающим токены сервисом
+const clockSkew = 30 * time.Second
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims поля JWT, которые использует сервис
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	// "admin" дает доступ к администрированию API-ключей
+	Role string `json:"role"`
+	// арендатор, к данным которого ограничен токен
+	TenantID string `json:"tenant_id"`
+}
+
+// aud по RFC 7519 может быть строкой или массивом строк
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+
+	return nil
+}
+
+type JWTConfig struct {
+	// общий секрет для HS256/HS384/HS512
+	HMACSecret []byte
+	// PEM открытого ключа для RS256/RS384/RS512
+	RSAPublicKeyPEM []byte
+	// если заданы, iss и aud токена должны совпадать
+	Issuer   string
+	Audience string
+}
+
+// JWTVerifier проверяет подпись и сроки действия JWT. Алгоритм из заголовка
+// принимается только если для него настроен ключ, поэтому подделать токен
+// подменой alg (none, HS256 с открытым RSA-ключом) нельзя
+type JWTVerifier struct {
+	hmacSecret []byte
+	rsaKey     *rsa.PublicKey
+	issuer     string
+	audience   string
+	now        func() time.Time
+}
+
+func NewJWTVerifier(cfg JWTConfig) (*JWTVerifier, error) {
+	v := &JWTVerifier{
+		hmacSecret: cfg.HMACSecret,
+		issuer:     cfg.Issuer,
+		audience:   cfg.Audience,
+		now:        time.Now,
+	}
+
+	if len(cfg.RSAPublicKeyPEM) > 0 {
+		key, err := parseRSAPublicKey(cfg.RSAPublicKeyPEM)
+		if err != nil {
+			return nil, err
+		}
+		v.rsaKey = key
+	}
+
+	return v, nil
+}
+
+// Enabled настроен хотя бы один ключ проверки
+func (v *JWTVerifier) Enabled() bool {
+	return len(v.hmacSecret) > 0 || v.rsaKey != nil
+}
+
+func (v *JWTVerifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+
+	if err := v.verifySignature(header.Alg, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+
+	if err := v.validateClaims(&claims); err != nil {
+		return nil, err
+	}
+
+	return &claims, nil
+}
+
+func (v *JWTVerifier) verifySignature(alg, signed string, signature []byte) error {
+	hash, ok := map[string]crypto.Hash{
+		"HS256": crypto.SHA256, "RS256": crypto.SHA256,
+		"HS384": crypto.SHA384, "RS384": crypto.SHA384,
+		"HS512": crypto.SHA512, "RS512": crypto.SHA512,
+	}[alg]
+	if !ok {
+		return fmt.Errorf("%w: unsupported alg %q", ErrInvalidToken, alg)
+	}
+
+	switch {
+	case strings.HasPrefix(alg, "HS") && len(v.hmacSecret) > 0:
+		mac := hmac.New(hash.New, v.hmacSecret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+	case strings.HasPrefix(alg, "RS") && v.rsaKey != nil:
+		h := hash.New()
+		h.Write([]byte(signed))
+		if err := rsa.VerifyPKCS1v15(v.rsaKey, hash, h.Sum(nil), signature); err != nil {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+	default:
+		return fmt.Errorf("%w: no key configured for alg %q", ErrInvalidToken, alg)
+	}
+
+	return nil
+}
+
+func (v *JWTVerifier) validateClaims(c *Claims) error {
+	now := v.now()
+
+	if c.Subject == "" {
+		return fmt.Errorf("%w: sub is required", ErrInvalidToken)
+	}
+
+	if c.ExpiresAt == 0 {
+		return fmt.Errorf("%w: exp is required", ErrInvalidToken)
+	}
+
+	if now.After(time.Unix(c.ExpiresAt, 0).Add(clockSkew)) {
+		return fmt.Errorf("%w: token expired", ErrInvalidToken)
+	}
+
+	if c.NotBefore != 0 && now.Before(time.Unix(c.NotBefore, 0).Add(-clockSkew)) {
+		return fmt.Errorf("%w: token not valid yet", ErrInvalidToken)
+	}
+
+	if v.issuer != "" && c.Issuer != v.issuer {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+
+	if v.audience != "" && !c.hasAudience(v.audience) {
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+
+	return nil
+}
+
+func (c *Claims) hasAudience(aud string) bool {
+	for _, a := range c.Audience {
+		if a == aud {
+			return true
+		}
+	}
+
+	return false
+}
+
+func decodeSegment(seg string, dest any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, dest)
+}
+
+// принимает ключ в формате PKIX ("PUBLIC KEY") и PKCS#1 ("RSA PUBLIC KEY")
+func parseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("rsa public key: no PEM block found")
+	}
+
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("rsa public key: %w", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("rsa public key: not an RSA key")
+	}
+
+	return rsaKey, nil
+}
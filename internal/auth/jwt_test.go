@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testSecret = []byte("0123456789abcdef0123456789abcdef")
+	testNow    = time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+)
+
+// signToken собирает JWT с заголовком alg: HS* подписываются ключом key как
+// секретом HMAC, RS* - закрытым ключом rsaKey, остальные - без подписи
+func signToken(t *testing.T, alg string, key []byte, rsaKey *rsa.PrivateKey, claims map[string]any) string {
+	t.Helper()
+
+	segment := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	signed := segment(map[string]string{"alg": alg, "typ": "JWT"}) + "." + segment(claims)
+
+	var signature []byte
+	switch {
+	case strings.HasPrefix(alg, "HS"):
+		mac := hmac.New(crypto.SHA256.New, key)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case strings.HasPrefix(alg, "RS"):
+		h := crypto.SHA256.New()
+		h.Write([]byte(signed))
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, h.Sum(nil)); err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newTestVerifier(t *testing.T, cfg JWTConfig) *JWTVerifier {
+	t.Helper()
+
+	v, err := NewJWTVerifier(cfg)
+	if err != nil {
+		t.Fatalf("NewJWTVerifier: %v", err)
+	}
+	v.now = func() time.Time { return testNow }
+
+	return v
+}
+
+// claims действующего токена с изменениями из overrides; nil в overrides удаляет поле
+func testClaims(overrides map[string]any) map[string]any {
+	claims := map[string]any{
+		"sub": "60601fee-2bf1-4721-ae6f-7636e79a0cba",
+		"iss": "auth.example.com",
+		"aud": "subscriptions",
+		"exp": testNow.Add(time.Hour).Unix(),
+		"nbf": testNow.Add(-time.Hour).Unix(),
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(claims, k)
+			continue
+		}
+		claims[k] = v
+	}
+
+	return claims
+}
+
+func TestJWTVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	hmacVerifier := newTestVerifier(t, JWTConfig{HMACSecret: testSecret, Issuer: "auth.example.com", Audience: "subscriptions"})
+	rsaVerifier := newTestVerifier(t, JWTConfig{RSAPublicKeyPEM: publicPEM})
+
+	hs := func(overrides map[string]any) string {
+		return signToken(t, "HS256", testSecret, nil, testClaims(overrides))
+	}
+	rs := func(overrides map[string]any) string {
+		return signToken(t, "RS256", nil, rsaKey, testClaims(overrides))
+	}
+	skew := func(d time.Duration) int64 { return testNow.Add(d).Unix() }
+
+	tests := []struct {
+		name     string
+		verifier *JWTVerifier
+		token    string
+		ok       bool
+	}{
+		{"hs256", hmacVerifier, hs(nil), true},
+		{"rs256", rsaVerifier, rs(nil), true},
+		{"audience list", hmacVerifier, hs(map[string]any{"aud": []string{"billing", "subscriptions"}}), true},
+
+		{"malformed", hmacVerifier, "header.claims", false},
+		{"alg none", hmacVerifier, signToken(t, "none", nil, nil, testClaims(nil)), false},
+		{"alg none without signature", hmacVerifier, strings.TrimSuffix(signToken(t, "none", nil, nil, testClaims(nil)), "."), false},
+		{"unsupported alg", hmacVerifier, signToken(t, "ES256", nil, nil, testClaims(nil)), false},
+		// HS256, подписанный открытым RSA-ключом как секретом HMAC
+		{"hs256 with rsa public key", rsaVerifier, signToken(t, "HS256", publicPEM, nil, testClaims(nil)), false},
+		{"rs256 without rsa key", hmacVerifier, rs(nil), false},
+		{"bad hmac signature", hmacVerifier, signToken(t, "HS256", []byte("another secret"), nil, testClaims(nil)), false},
+		{"tampered claims", hmacVerifier, tamperClaims(t, hs(nil)), false},
+		{"tampered rs256 claims", rsaVerifier, tamperClaims(t, rs(nil)), false},
+
+		{"expired", hmacVerifier, hs(map[string]any{"exp": skew(-time.Minute)}), false},
+		{"expired within skew", hmacVerifier, hs(map[string]any{"exp": skew(-clockSkew / 2)}), true},
+		{"no exp", hmacVerifier, hs(map[string]any{"exp": nil}), false},
+		{"not valid yet", hmacVerifier, hs(map[string]any{"nbf": skew(time.Minute)}), false},
+		{"not valid yet within skew", hmacVerifier, hs(map[string]any{"nbf": skew(clockSkew / 2)}), true},
+		{"no nbf", hmacVerifier, hs(map[string]any{"nbf": nil}), true},
+		{"no sub", hmacVerifier, hs(map[string]any{"sub": nil}), false},
+
+		{"issuer mismatch", hmacVerifier, hs(map[string]any{"iss": "evil.example.com"}), false},
+		{"no issuer", hmacVerifier, hs(map[string]any{"iss": nil}), false},
+		{"audience mismatch", hmacVerifier, hs(map[string]any{"aud": "billing"}), false},
+		{"audience list mismatch", hmacVerifier, hs(map[string]any{"aud": []string{"billing"}}), false},
+		// без настроенных iss и aud они не проверяются
+		{"issuer not configured", rsaVerifier, rs(map[string]any{"iss": "evil.example.com", "aud": nil}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.verifier.Verify(tt.token)
+			if !tt.ok {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("error %v, want %v", err, ErrInvalidToken)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if claims.Subject != "60601fee-2bf1-4721-ae6f-7636e79a0cba" {
+				t.Errorf("subject %q", claims.Subject)
+			}
+		})
+	}
+}
+
+// tamperClaims заменяет claims токена, сохраняя заголовок и подпись
+func tamperClaims(t *testing.T, token string) string {
+	t.Helper()
+
+	parts := strings.Split(token, ".")
+	data, err := json.Marshal(testClaims(map[string]any{"role": "admin"}))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	return parts[0] + "." + base64.RawURLEncoding.EncodeToString(data) + "." + parts[2]
+}
+
+func TestJWTVerifierDisabled(t *testing.T) {
+	v := newTestVerifier(t, JWTConfig{})
+	if v.Enabled() {
+		t.Error("verifier without keys is enabled")
+	}
+
+	if _, err := v.Verify(signToken(t, "HS256", nil, nil, testClaims(nil))); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("error %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestNewJWTVerifierInvalidKey(t *testing.T) {
+	if _, err := NewJWTVerifier(JWTConfig{RSAPublicKeyPEM: []byte("not a pem")}); err == nil {
+		t.Error("invalid rsa public key accepted")
+	}
+}
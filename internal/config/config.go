@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,14 +13,89 @@ import (
 type Config struct {
 	AppPort string
 
+	// хранилище данных: postgres или memory (без БД, данные теряются при остановке)
+	Storage string
+
 	DBHost     string
 	DBPort     int
 	DBUser     string
 	DBPassword string
 	DBName     string
 	DBSSLMode  string
+
+	// применять миграции при запуске; реплики применяют их по очереди
+	MigrateOnStart bool
+
+	// JWT проверяется по HMAC-секрету и/или открытому RSA-ключу; без них
+	// принимаются только API-ключи
+	JWTHMACSecret       string
+	JWTRSAPublicKeyFile string
+	JWTIssuer           string
+	JWTAudience         string
+
+	// заголовок, в котором доверенный шлюз передает user_id пользователя;
+	// пустой - заголовок не принимается
+	TrustedUserHeader string
+
+	// данные разделяются по арендаторам; выключено - все данные принадлежат
+	// арендатору по умолчанию
+	MultiTenant bool
+	// дополнительно включает проверку арендатора политиками RLS в Postgres
+	DBRowLevelSecurity bool
+
+	// срок хранения удаленных подписок до окончательного удаления
+	SoftDeleteRetention time.Duration
+
+	// куда публикуются события из outbox: log, webhook, nats или none
+	OutboxPublisher     string
+	OutboxPollInterval  time.Duration
+	OutboxWebhookURL    string
+	OutboxWebhookSecret string
+	OutboxNATSURL       string
+	// префикс subject, события публикуются в "<префикс>.<тип события>"
+	OutboxNATSSubject string
+
+	// число попыток доставки на вебхук клиента до перехода в dead
+	WebhookMaxAttempts  int
+	WebhookPollInterval time.Duration
+	WebhookTimeout      time.Duration
+
+	// за сколько дней до списания отправляется напоминание
+	ReminderDaysBefore int
+	ReminderInterval   time.Duration
+	// канал напоминаний: log, smtp, webhook или none (только событие
+	// subscription.renewal_upcoming)
+	ReminderNotifier string
+	ReminderSMTPAddr string
+	// логин и пароль SMTP; без логина письма отправляются без авторизации
+	ReminderSMTPUsername string
+	ReminderSMTPPassword string
+	ReminderSMTPFrom     string
+	// адрес получателя; {user_id} заменяется на владельца подписки
+	ReminderSMTPTo        string
+	ReminderWebhookURL    string
+	ReminderWebhookSecret string
 }
 
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+const (
+	OutboxPublisherLog     = "log"
+	OutboxPublisherWebhook = "webhook"
+	OutboxPublisherNATS    = "nats"
+	OutboxPublisherNone    = "none"
+)
+
+const (
+	ReminderNotifierLog     = "log"
+	ReminderNotifierSMTP    = "smtp"
+	ReminderNotifierWebhook = "webhook"
+	ReminderNotifierNone    = "none"
+)
+
 func LoadConfig() (Config, error) {
 	_ = godotenv.Load()
 
@@ -29,15 +105,137 @@ func LoadConfig() (Config, error) {
 		return Config{}, errors.New("invalid DB_PORT")
 	}
 
+	multiTenant, err := getBool("MULTI_TENANT")
+	if err != nil {
+		return Config{}, err
+	}
+
+	rowLevelSecurity, err := getBool("DB_ROW_LEVEL_SECURITY")
+	if err != nil {
+		return Config{}, err
+	}
+
+	migrateOnStart, err := getBool("MIGRATE_ON_START")
+	if err != nil {
+		return Config{}, err
+	}
+
+	retention, err := time.ParseDuration(getEnv("SOFT_DELETE_RETENTION", "720h"))
+	if err != nil || retention < 0 {
+		return Config{}, errors.New("invalid SOFT_DELETE_RETENTION")
+	}
+
+	pollInterval, err := time.ParseDuration(getEnv("OUTBOX_POLL_INTERVAL", "1s"))
+	if err != nil || pollInterval <= 0 {
+		return Config{}, errors.New("invalid OUTBOX_POLL_INTERVAL")
+	}
+
+	webhookMaxAttempts, err := strconv.Atoi(getEnv("WEBHOOK_MAX_ATTEMPTS", "10"))
+	if err != nil || webhookMaxAttempts < 1 {
+		return Config{}, errors.New("invalid WEBHOOK_MAX_ATTEMPTS")
+	}
+
+	webhookPollInterval, err := time.ParseDuration(getEnv("WEBHOOK_POLL_INTERVAL", "1s"))
+	if err != nil || webhookPollInterval <= 0 {
+		return Config{}, errors.New("invalid WEBHOOK_POLL_INTERVAL")
+	}
+
+	webhookTimeout, err := time.ParseDuration(getEnv("WEBHOOK_TIMEOUT", "10s"))
+	if err != nil || webhookTimeout <= 0 {
+		return Config{}, errors.New("invalid WEBHOOK_TIMEOUT")
+	}
+
+	reminderDaysBefore, err := strconv.Atoi(getEnv("REMINDER_DAYS_BEFORE", "3"))
+	if err != nil || reminderDaysBefore < 0 {
+		return Config{}, errors.New("invalid REMINDER_DAYS_BEFORE")
+	}
+
+	reminderInterval, err := time.ParseDuration(getEnv("REMINDER_INTERVAL", "1h"))
+	if err != nil || reminderInterval <= 0 {
+		return Config{}, errors.New("invalid REMINDER_INTERVAL")
+	}
+
 	cfg := Config{
 		AppPort: getEnv("APP_PORT", "8080"),
 
+		Storage: getEnv("STORAGE", StoragePostgres),
+
 		DBHost:     getEnv("DB_HOST", "localhost"),
 		DBPort:     dbPort,
 		DBUser:     getEnv("DB_USER", "postgres"),
 		DBPassword: getEnv("DB_PASSWORD", "postgres"),
 		DBName:     getEnv("DB_NAME", "subscriptions"),
 		DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+		MigrateOnStart: migrateOnStart,
+
+		JWTHMACSecret:       os.Getenv("JWT_HMAC_SECRET"),
+		JWTRSAPublicKeyFile: os.Getenv("JWT_RSA_PUBLIC_KEY_FILE"),
+		JWTIssuer:           os.Getenv("JWT_ISSUER"),
+		JWTAudience:         os.Getenv("JWT_AUDIENCE"),
+
+		TrustedUserHeader: os.Getenv("TRUSTED_USER_HEADER"),
+
+		MultiTenant:        multiTenant,
+		DBRowLevelSecurity: rowLevelSecurity,
+
+		SoftDeleteRetention: retention,
+
+		OutboxPublisher:     getEnv("OUTBOX_PUBLISHER", OutboxPublisherLog),
+		OutboxPollInterval:  pollInterval,
+		OutboxWebhookURL:    os.Getenv("OUTBOX_WEBHOOK_URL"),
+		OutboxWebhookSecret: os.Getenv("OUTBOX_WEBHOOK_SECRET"),
+		OutboxNATSURL:       os.Getenv("OUTBOX_NATS_URL"),
+		OutboxNATSSubject:   getEnv("OUTBOX_NATS_SUBJECT", "subscriptions"),
+
+		WebhookMaxAttempts:  webhookMaxAttempts,
+		WebhookPollInterval: webhookPollInterval,
+		WebhookTimeout:      webhookTimeout,
+
+		ReminderDaysBefore:    reminderDaysBefore,
+		ReminderInterval:      reminderInterval,
+		ReminderNotifier:      getEnv("REMINDER_NOTIFIER", ReminderNotifierLog),
+		ReminderSMTPAddr:      os.Getenv("REMINDER_SMTP_ADDR"),
+		ReminderSMTPUsername:  os.Getenv("REMINDER_SMTP_USERNAME"),
+		ReminderSMTPPassword:  os.Getenv("REMINDER_SMTP_PASSWORD"),
+		ReminderSMTPFrom:      os.Getenv("REMINDER_SMTP_FROM"),
+		ReminderSMTPTo:        os.Getenv("REMINDER_SMTP_TO"),
+		ReminderWebhookURL:    os.Getenv("REMINDER_WEBHOOK_URL"),
+		ReminderWebhookSecret: os.Getenv("REMINDER_WEBHOOK_SECRET"),
+	}
+
+	switch cfg.Storage {
+	case StoragePostgres, StorageMemory:
+	default:
+		return Config{}, errors.New("invalid STORAGE")
+	}
+
+	switch cfg.OutboxPublisher {
+	case OutboxPublisherLog, OutboxPublisherNone:
+	case OutboxPublisherWebhook:
+		if cfg.OutboxWebhookURL == "" {
+			return Config{}, errors.New("OUTBOX_WEBHOOK_URL is required for OUTBOX_PUBLISHER=webhook")
+		}
+	case OutboxPublisherNATS:
+		if cfg.OutboxNATSURL == "" {
+			return Config{}, errors.New("OUTBOX_NATS_URL is required for OUTBOX_PUBLISHER=nats")
+		}
+	default:
+		return Config{}, errors.New("invalid OUTBOX_PUBLISHER")
+	}
+
+	switch cfg.ReminderNotifier {
+	case ReminderNotifierLog, ReminderNotifierNone:
+	case ReminderNotifierSMTP:
+		if cfg.ReminderSMTPAddr == "" || cfg.ReminderSMTPFrom == "" || cfg.ReminderSMTPTo == "" {
+			return Config{}, errors.New("REMINDER_SMTP_ADDR, REMINDER_SMTP_FROM and REMINDER_SMTP_TO are required for REMINDER_NOTIFIER=smtp")
+		}
+	case ReminderNotifierWebhook:
+		if cfg.ReminderWebhookURL == "" {
+			return Config{}, errors.New("REMINDER_WEBHOOK_URL is required for REMINDER_NOTIFIER=webhook")
+		}
+	default:
+		return Config{}, errors.New("invalid REMINDER_NOTIFIER")
 	}
 
 	return cfg, nil
@@ -50,6 +248,20 @@ func getEnv(key, def string) string {
 	return def
 }
 
+func getBool(key string) (bool, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errors.New("invalid " + key)
+	}
+
+	return b, nil
+}
+
 func (c Config) DatabaseURL() string {
 	u := &url.URL{
 		Scheme: "postgres",
@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction изменение подписки, записанное в журнал аудита
+type AuditAction string
+
+const (
+	AuditCreate              AuditAction = "create"
+	AuditUpdate              AuditAction = "update"
+	AuditDelete              AuditAction = "delete"
+	AuditRestore             AuditAction = "restore"
+	AuditPriceChangeSchedule AuditAction = "price_change_schedule"
+	AuditPriceChangeCancel   AuditAction = "price_change_cancel"
+	AuditPause               AuditAction = "pause"
+	AuditResume              AuditAction = "resume"
+	AuditCancel              AuditAction = "cancel"
+	// окончательное удаление очисткой после срока хранения
+	AuditPurge AuditAction = "purge"
+)
+
+// EventType событие, которое публикуется об изменении
+func (a AuditAction) EventType() EventType {
+	switch a {
+	case AuditCreate:
+		return EventSubscriptionCreated
+	case AuditDelete, AuditPurge:
+		return EventSubscriptionDeleted
+	default:
+		return EventSubscriptionUpdated
+	}
+}
+
+// актор записей, сделанных без аутентифицированного клиента
+const AuditActorSystem = "system"
+
+// AuditEntry неизменяемая запись журнала аудита. Before и After - состояние
+// до и после изменения в JSON; для создания Before пуст, для удаления пуст After
+type AuditEntry struct {
+	ID             int64
+	SubscriptionID uuid.UUID
+	// владелец подписки на момент изменения
+	OwnerID uuid.UUID
+	Action  AuditAction
+	// sub JWT или ID API-ключа, ActorType - способ аутентификации
+	Actor     string
+	ActorType string
+	// пользователь, от имени которого действовал клиент
+	ActorUserID *uuid.UUID
+	Before      json.RawMessage `swaggertype:"object"`
+	After       json.RawMessage `swaggertype:"object"`
+	RequestID   string
+	CreatedAt   time.Time
+}
+
+// NewAuditEntry запись об изменении подписки клиентом из контекста
+func NewAuditEntry(ctx context.Context, action AuditAction, sub *Subscription, before, after any) (*AuditEntry, error) {
+	e := &AuditEntry{
+		SubscriptionID: sub.ID,
+		OwnerID:        sub.UserID,
+		Action:         action,
+		Actor:          AuditActorSystem,
+		RequestID:      RequestIDFromContext(ctx),
+	}
+
+	if p := PrincipalFromContext(ctx); p != nil {
+		e.Actor = p.Subject
+		e.ActorType = p.Method
+		e.ActorUserID = p.UserID
+	}
+
+	var err error
+	if e.Before, err = marshalAuditState(before); err != nil {
+		return nil, err
+	}
+	if e.After, err = marshalAuditState(after); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func marshalAuditState(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal audit state: %w", err)
+	}
+
+	return data, nil
+}
+
+const (
+	DefaultAuditLimit = 50
+	MaxAuditLimit     = 500
+)
+
+// AuditFilter фильтры журнала аудита; записи возвращаются от новых к старым
+type AuditFilter struct {
+	SubscriptionID *uuid.UUID
+	// ограничение по владельцу подписок; задается service слоем по вызывающему клиенту
+	OwnerID *uuid.UUID
+	Actor   string
+	Action  AuditAction
+	From    *time.Time
+	To      *time.Time
+	// записи с ID меньше BeforeID, для постраничного чтения
+	BeforeID *int64
+	Limit    int
+}
+
+func (f *AuditFilter) Validate() error {
+	verr := &ValidationError{}
+
+	switch f.Action {
+	case "", AuditCreate, AuditUpdate, AuditDelete, AuditRestore, AuditPriceChangeSchedule, AuditPriceChangeCancel,
+		AuditPause, AuditResume, AuditCancel, AuditPurge:
+	default:
+		verr.Add("action", fmt.Sprintf("unsupported action %q", f.Action))
+	}
+
+	if f.From != nil && f.To != nil && f.To.Before(*f.From) {
+		verr.Add("to", "'to' must be after 'from'")
+	}
+
+	if f.Limit <= 0 || f.Limit > MaxAuditLimit {
+		verr.Add("limit", fmt.Sprintf("limit must be between 1 and %d", MaxAuditLimit))
+	}
+
+	return verr.Err()
+}
+
+// AuditPage страница журнала аудита; NextBeforeID == nil на последней странице
+type AuditPage struct {
+	Items        []AuditEntry
+	NextBeforeID *int64
+}
+
+type requestIDKey struct{}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext возвращает id запроса, по которому его можно найти в логах
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
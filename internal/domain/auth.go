@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnauthorized = errors.New("authentication required")
+	ErrForbidden    = errors.New("access denied")
+
+	ErrAPIKeyNotFound = fmt.Errorf("api key %w", ErrNotFound)
+)
+
+const (
+	AuthMethodJWT    = "jwt"
+	AuthMethodAPIKey = "api_key"
+	// фоновые процессы приложения
+	AuthMethodSystem = "system"
+	// административные команды app subscriptions
+	AuthMethodCLI = "cli"
+)
+
+// Principal аутентифицированный клиент API
+type Principal struct {
+	// sub из JWT или ID API-ключа
+	Subject string
+	Method  string
+	Admin   bool
+	// API-ключ доверенного шлюза, который передает пользователя заголовком
+	Gateway bool
+	// пользователь, от имени которого выполняется запрос; видит только свои подписки
+	UserID *uuid.UUID
+	// арендатор, данными которого ограничен запрос
+	TenantID string
+	// арендатор задан учетными данными, а не заголовком запроса
+	TenantFixed bool
+}
+
+// APIKey ключ доступа к API. Сам ключ показывается один раз при выпуске,
+// в БД хранится только его хеш
+type APIKey struct {
+	ID     uuid.UUID
+	Name   string
+	Prefix string
+	Hash   []byte `json:"-"`
+	Admin  bool
+	// ключ доверенного шлюза: может выполнять запросы от имени пользователя
+	// из заголовка TRUSTED_USER_HEADER
+	Gateway bool
+	// nil - ключ не привязан к арендатору; администратор выбирает его заголовком X-Tenant-ID
+	TenantID   *string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	RevokedAt  *time.Time
+	LastUsedAt *time.Time
+}
+
+func (k *APIKey) Validate() error {
+	verr := &ValidationError{}
+
+	if k.Name == "" {
+		verr.Add("name", "name is required")
+	}
+
+	if k.TenantID != nil {
+		if err := ValidateTenantID(*k.TenantID); err != nil {
+			verr.Add("tenant_id", err.Error())
+		}
+	}
+
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(time.Now()) {
+		verr.Add("expires_at", "expires_at must be in the future")
+	}
+
+	return verr.Err()
+}
+
+// Active ключ не отозван и не истек на момент now
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// SystemPrincipal клиент фоновых процессов: администратор арендатора tenantID
+func SystemPrincipal(tenantID string) *Principal {
+	return &Principal{
+		Subject:     AuditActorSystem,
+		Method:      AuthMethodSystem,
+		Admin:       true,
+		TenantID:    tenantID,
+		TenantFixed: true,
+	}
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext возвращает клиента, аутентифицированного middleware, или nil
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
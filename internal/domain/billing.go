@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// BillingPeriod периодичность списаний по подписке
+type BillingPeriod string
+
+const (
+	BillingPeriodWeek    BillingPeriod = "week"
+	BillingPeriodMonth   BillingPeriod = "month"
+	BillingPeriodQuarter BillingPeriod = "quarter"
+	BillingPeriodYear    BillingPeriod = "year"
+)
+
+func (p BillingPeriod) Validate() error {
+	switch p {
+	case BillingPeriodWeek, BillingPeriodMonth, BillingPeriodQuarter, BillingPeriodYear:
+		return nil
+	}
+
+	return fmt.Errorf("unsupported billing period %q", p)
+}
+
+// следующее списание после t
+func (p BillingPeriod) next(t time.Time) time.Time {
+	switch p {
+	case BillingPeriodWeek:
+		return t.AddDate(0, 0, 7)
+	case BillingPeriodQuarter:
+		return t.AddDate(0, 3, 0)
+	case BillingPeriodYear:
+		return t.AddDate(1, 0, 0)
+	default:
+		return t.AddDate(0, 1, 0)
+	}
+}
+
+// приводит дату к первому числу месяца
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// первое списание: для недельной оплаты - день начала подписки,
+// для остальных периодов - первое число месяца начала
+func (s *Subscription) firstCharge() time.Time {
+	if s.BillingPeriod == BillingPeriodWeek {
+		return time.Date(s.StartDate.Year(), s.StartDate.Month(), s.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	return monthStart(s.StartDate)
+}
+
+// ChargeDates возвращает даты списаний по подписке в пределах периода [from, to].
+// Границы - месяцы включительно, как и в API (MM-YYYY); месяц EndDate тоже оплачивается.
+// Списания в пробный период, во время пауз и после отмены не выставляются.
+// Тот же расчет выполняется в SQL в postgres.SubscriptionRepository.CalculateTotal.
+func (s *Subscription) ChargeDates(from, to time.Time) []time.Time {
+	periodStart := monthStart(from)
+
+	periodEnd := monthStart(to).AddDate(0, 1, 0)
+	if s.EndDate != nil {
+		if e := monthStart(*s.EndDate).AddDate(0, 1, 0); e.Before(periodEnd) {
+			periodEnd = e
+		}
+	}
+
+	var dates []time.Time
+	for charge := s.firstCharge(); charge.Before(periodEnd); charge = s.BillingPeriod.next(charge) {
+		if !charge.Before(periodStart) && s.billable(charge) {
+			dates = append(dates, charge)
+		}
+	}
+
+	return dates
+}
+
+// NextChargeDate возвращает ближайшее выставляемое списание в дни [from, to];
+// false - в этот период списаний нет
+func (s *Subscription) NextChargeDate(from, to time.Time) (time.Time, bool) {
+	from, to = dayStart(from), dayStart(to)
+
+	for _, charge := range s.ChargeDates(from, to) {
+		if !charge.Before(from) && !charge.After(to) {
+			return charge, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// первое списание позже дня date, включая невыставляемые;
+// false - подписка закончится раньше
+func (s *Subscription) chargeAfter(date time.Time) (time.Time, bool) {
+	day := dayStart(date)
+
+	var end time.Time
+	if s.EndDate != nil {
+		end = monthStart(*s.EndDate).AddDate(0, 1, 0)
+	}
+
+	for charge := s.firstCharge(); s.EndDate == nil || charge.Before(end); charge = s.BillingPeriod.next(charge) {
+		if charge.After(day) {
+			return charge, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// CostInPeriod возвращает сумму списаний по подписке за период [from, to]
+// с учетом истории изменения цены
+func (s *Subscription) CostInPeriod(from, to time.Time, changes []PriceChange) int {
+	var cost int
+	for _, charge := range s.ChargeDates(from, to) {
+		cost += s.PriceAt(changes, charge)
+	}
+
+	return cost
+}
@@ -0,0 +1,195 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func datePtr(year int, month time.Month, day int) *time.Time {
+	d := date(year, month, day)
+	return &d
+}
+
+func TestNextChargeDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		sub      Subscription
+		from, to time.Time
+		want     *time.Time
+	}{
+		{
+			// списания ежемесячной подписки - первого числа, а не в день начала
+			name: "monthly from month end",
+			sub:  Subscription{BillingPeriod: BillingPeriodMonth, StartDate: date(2025, time.January, 31)},
+			from: date(2025, time.February, 27),
+			to:   date(2025, time.March, 2),
+			want: datePtr(2025, time.March, 1),
+		},
+		{
+			name: "no charge in window",
+			sub:  Subscription{BillingPeriod: BillingPeriodMonth, StartDate: date(2025, time.January, 31)},
+			from: date(2025, time.February, 2),
+			to:   date(2025, time.February, 27),
+		},
+		{
+			name: "times are truncated to days",
+			sub:  Subscription{BillingPeriod: BillingPeriodMonth, StartDate: date(2025, time.January, 1)},
+			from: time.Date(2025, time.March, 1, 23, 0, 0, 0, time.UTC),
+			to:   time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+			want: datePtr(2025, time.March, 1),
+		},
+		{
+			name: "weekly from month end",
+			sub:  Subscription{BillingPeriod: BillingPeriodWeek, StartDate: date(2025, time.January, 31)},
+			from: date(2025, time.February, 26),
+			to:   date(2025, time.March, 2),
+			want: datePtr(2025, time.February, 28),
+		},
+		{
+			name: "weekly across year end",
+			sub:  Subscription{BillingPeriod: BillingPeriodWeek, StartDate: date(2025, time.December, 31)},
+			from: date(2026, time.January, 5),
+			to:   date(2026, time.January, 10),
+			want: datePtr(2026, time.January, 7),
+		},
+		{
+			name: "quarterly from month end",
+			sub:  Subscription{BillingPeriod: BillingPeriodQuarter, StartDate: date(2024, time.November, 30)},
+			from: date(2025, time.January, 30),
+			to:   date(2025, time.February, 2),
+			want: datePtr(2025, time.February, 1),
+		},
+		{
+			name: "yearly from leap day",
+			sub:  Subscription{BillingPeriod: BillingPeriodYear, StartDate: date(2024, time.February, 29)},
+			from: date(2025, time.January, 30),
+			to:   date(2025, time.February, 2),
+			want: datePtr(2025, time.February, 1),
+		},
+		{
+			name: "first charge in start month",
+			sub:  Subscription{BillingPeriod: BillingPeriodMonth, StartDate: date(2025, time.March, 20)},
+			from: date(2025, time.February, 27),
+			to:   date(2025, time.March, 2),
+			want: datePtr(2025, time.March, 1),
+		},
+		{
+			// TrialUntil - первый оплачиваемый день
+			name: "trial ends on charge day",
+			sub: Subscription{
+				BillingPeriod: BillingPeriodMonth,
+				StartDate:     date(2025, time.January, 1),
+				TrialUntil:    datePtr(2025, time.March, 1),
+			},
+			from: date(2025, time.February, 27),
+			to:   date(2025, time.March, 2),
+			want: datePtr(2025, time.March, 1),
+		},
+		{
+			name: "trial covers charge day",
+			sub: Subscription{
+				BillingPeriod: BillingPeriodMonth,
+				StartDate:     date(2025, time.January, 1),
+				TrialUntil:    datePtr(2025, time.March, 2),
+			},
+			from: date(2025, time.February, 27),
+			to:   date(2025, time.March, 2),
+		},
+		{
+			name: "trial ends inside window",
+			sub: Subscription{
+				BillingPeriod: BillingPeriodMonth,
+				StartDate:     date(2025, time.January, 1),
+				TrialUntil:    datePtr(2025, time.March, 2),
+			},
+			from: date(2025, time.February, 27),
+			to:   date(2025, time.April, 1),
+			want: datePtr(2025, time.April, 1),
+		},
+		{
+			name: "pause spans charge day",
+			sub: Subscription{
+				BillingPeriod: BillingPeriodMonth,
+				StartDate:     date(2025, time.January, 1),
+				Pauses:        []Pause{{From: date(2025, time.February, 20), Until: datePtr(2025, time.March, 5)}},
+			},
+			from: date(2025, time.February, 27),
+			to:   date(2025, time.April, 2),
+			want: datePtr(2025, time.April, 1),
+		},
+		{
+			name: "open pause",
+			sub: Subscription{
+				BillingPeriod: BillingPeriodMonth,
+				StartDate:     date(2025, time.January, 1),
+				Pauses:        []Pause{{From: date(2025, time.February, 20)}},
+			},
+			from: date(2025, time.February, 27),
+			to:   date(2025, time.April, 2),
+		},
+		{
+			name: "cancelled at period end",
+			sub: Subscription{
+				BillingPeriod: BillingPeriodMonth,
+				StartDate:     date(2025, time.January, 1),
+				CancelAt:      datePtr(2025, time.April, 1),
+			},
+			from: date(2025, time.March, 30),
+			to:   date(2025, time.April, 2),
+		},
+		{
+			name: "last paid charge before cancel",
+			sub: Subscription{
+				BillingPeriod: BillingPeriodMonth,
+				StartDate:     date(2025, time.January, 1),
+				CancelAt:      datePtr(2025, time.April, 1),
+			},
+			from: date(2025, time.February, 27),
+			to:   date(2025, time.April, 2),
+			want: datePtr(2025, time.March, 1),
+		},
+		{
+			// месяц end_date оплачивается
+			name: "end date month",
+			sub: Subscription{
+				BillingPeriod: BillingPeriodMonth,
+				StartDate:     date(2025, time.January, 1),
+				EndDate:       datePtr(2025, time.March, 1),
+			},
+			from: date(2025, time.February, 27),
+			to:   date(2025, time.April, 2),
+			want: datePtr(2025, time.March, 1),
+		},
+		{
+			name: "after end date",
+			sub: Subscription{
+				BillingPeriod: BillingPeriodMonth,
+				StartDate:     date(2025, time.January, 1),
+				EndDate:       datePtr(2025, time.February, 1),
+			},
+			from: date(2025, time.February, 27),
+			to:   date(2025, time.April, 2),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.sub.NextChargeDate(tt.from, tt.to)
+
+			if tt.want == nil {
+				if ok {
+					t.Errorf("NextChargeDate = %s, want no charge", got.Format(time.DateOnly))
+				}
+				return
+			}
+
+			if !ok || !got.Equal(*tt.want) {
+				t.Errorf("NextChargeDate = %s, %v; want %s", got.Format(time.DateOnly), ok, tt.want.Format(time.DateOnly))
+			}
+		})
+	}
+}
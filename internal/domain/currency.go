@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// DefaultCurrency валюта подписок и отчетов, если она не указана явно
+const DefaultCurrency = "RUB"
+
+// количество знаков минорных единиц для поддерживаемых валют ISO 4217
+var currencyExponents = map[string]int{
+	"RUB": 2,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"CNY": 2,
+	"KZT": 2,
+	"BYN": 2,
+	"TRY": 2,
+	"JPY": 0,
+}
+
+var (
+	ErrExchangeRateNotFound = fmt.Errorf("exchange rate %w", ErrNotFound)
+	// нет курса для пересчета суммы в запрошенную валюту - запрос нельзя выполнить,
+	// но это не отсутствие запрошенного ресурса
+	ErrNoExchangeRate = fmt.Errorf("%w: no exchange rate for currency pair", ErrValidation)
+)
+
+func ValidateCurrency(code string) error {
+	if _, ok := currencyExponents[code]; !ok {
+		return fmt.Errorf("unsupported currency %q", code)
+	}
+
+	return nil
+}
+
+// Money сумма в минорных единицах валюты (копейки, центы)
+type Money struct {
+	Amount   int
+	Currency string
+}
+
+// String сумма в основных единицах валюты: "499.00 RUB"
+func (m Money) String() string {
+	exp := currencyExponents[m.Currency]
+
+	return fmt.Sprintf("%.*f %s", exp, float64(m.Amount)/math.Pow10(exp), m.Currency)
+}
+
+// ExchangeRate курс: 1 единица BaseCurrency стоит Rate единиц QuoteCurrency
+type ExchangeRate struct {
+	BaseCurrency  string
+	QuoteCurrency string
+	Rate          float64
+	UpdatedAt     time.Time
+}
+
+func (r *ExchangeRate) Validate() error {
+	verr := &ValidationError{}
+
+	if err := ValidateCurrency(r.BaseCurrency); err != nil {
+		verr.Add("base", err.Error())
+	}
+
+	if err := ValidateCurrency(r.QuoteCurrency); err != nil {
+		verr.Add("quote", err.Error())
+	}
+
+	if r.BaseCurrency == r.QuoteCurrency {
+		verr.Add("quote", "base and quote currencies must differ")
+	}
+
+	if r.Rate <= 0 || math.IsInf(r.Rate, 0) || math.IsNaN(r.Rate) {
+		verr.Add("rate", "rate must be positive")
+	}
+
+	return verr.Err()
+}
+
+type currencyPair struct {
+	base  string
+	quote string
+}
+
+// ExchangeRates таблица курсов для пересчета сумм в валюту отчета
+type ExchangeRates map[currencyPair]float64
+
+func NewExchangeRates(rates []ExchangeRate) ExchangeRates {
+	table := make(ExchangeRates, len(rates))
+	for _, r := range rates {
+		table[currencyPair{base: r.BaseCurrency, quote: r.QuoteCurrency}] = r.Rate
+	}
+
+	return table
+}
+
+// Convert пересчитывает сумму в валюту to. Если прямого курса нет,
+// используется обратный. Результат округляется до минорной единицы.
+func (t ExchangeRates) Convert(m Money, to string) (int, error) {
+	if m.Currency == to {
+		return m.Amount, nil
+	}
+
+	rate, ok := t[currencyPair{base: m.Currency, quote: to}]
+	if !ok {
+		inverse, ok := t[currencyPair{base: to, quote: m.Currency}]
+		if !ok {
+			return 0, fmt.Errorf("%w %s/%s", ErrNoExchangeRate, m.Currency, to)
+		}
+		rate = 1 / inverse
+	}
+
+	scale := math.Pow10(currencyExponents[to] - currencyExponents[m.Currency])
+
+	return int(math.Round(float64(m.Amount) * rate * scale)), nil
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// Категории ошибок. Конкретные ошибки сущностей оборачивают их, поэтому
+// вызывающий код может проверять как errors.Is(err, ErrSubscriptionNotFound),
+// так и errors.Is(err, ErrNotFound)
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+)
+
+// FieldError ошибка значения одного поля запроса
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ValidationError ошибки валидации сразу по всем некорректным полям
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func NewValidationError(field, message string) *ValidationError {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+": "+f.Message)
+	}
+
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Is позволяет проверять любую ValidationError через errors.Is(err, ErrValidation)
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Err возвращает nil, если ошибок не набралось
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+
+	return e
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType тип доменного события; значения - часть контракта с потребителями
+type EventType string
+
+const (
+	EventSubscriptionCreated EventType = "subscription.created"
+	// изменение полей, цены или восстановление удаленной подписки
+	EventSubscriptionUpdated EventType = "subscription.updated"
+	EventSubscriptionDeleted EventType = "subscription.deleted"
+	// до очередного списания по подписке осталось несколько дней
+	EventSubscriptionRenewalUpcoming EventType = "subscription.renewal_upcoming"
+)
+
+func (t EventType) Valid() bool {
+	switch t {
+	case EventSubscriptionCreated, EventSubscriptionUpdated, EventSubscriptionDeleted, EventSubscriptionRenewalUpcoming:
+		return true
+	default:
+		return false
+	}
+}
+
+// Event доменное событие об изменении подписки. Сохраняется в outbox в транзакции
+// изменения и публикуется фоновым процессом не меньше одного раза, поэтому
+// потребители должны быть идемпотентны по ID
+type Event struct {
+	ID             uuid.UUID `json:"id"`
+	Type           EventType `json:"type"`
+	TenantID       string    `json:"tenant_id"`
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	OccurredAt     time.Time `json:"occurred_at"`
+	// состояние подписки после изменения, для напоминаний - Reminder
+	Data json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// NewSubscriptionEvent событие об изменении подписки sub арендатора из контекста
+func NewSubscriptionEvent(ctx context.Context, typ EventType, sub *Subscription) (*Event, error) {
+	tenantID, err := TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newEvent(typ, tenantID, sub.ID, sub)
+}
+
+// NewRenewalEvent событие о предстоящем списании; данные события - напоминание
+func NewRenewalEvent(r *Reminder) (*Event, error) {
+	return newEvent(EventSubscriptionRenewalUpcoming, r.TenantID, r.SubscriptionID, r)
+}
+
+func newEvent(typ EventType, tenantID string, subscriptionID uuid.UUID, data any) (*Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("marshal event data: %w", err)
+	}
+
+	return &Event{
+		ID:             uuid.New(),
+		Type:           typ,
+		TenantID:       tenantID,
+		SubscriptionID: subscriptionID,
+		OccurredAt:     time.Now(),
+		Data:           raw,
+	}, nil
+}
+
+// OutboxMessage событие, ожидающее публикации
+type OutboxMessage struct {
+	ID       int64
+	Event    Event
+	Attempts int
+}
@@ -1,27 +1,240 @@
 package domain
 
 import (
-	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
 
+// TotalGroupBy поле, по которому группируется подсчет стоимости
+type TotalGroupBy string
+
+const (
+	GroupByServiceName TotalGroupBy = "service_name"
+	GroupByUserID      TotalGroupBy = "user_id"
+)
+
+// MaxTotalMonths наибольшая длина периода подсчета сумм в месяцах, включая from и to:
+// подсчет перебирает все списания каждой подписки за период
+const MaxTotalMonths = 120
+
 type TotalFilter struct {
+	// ограничение по владельцу подписок; задается service слоем по вызывающему клиенту
+	OwnerID     *uuid.UUID
 	UserID      *uuid.UUID
 	ServiceName *string
 	From        time.Time
 	To          time.Time
+	GroupBy     []TotalGroupBy
+	// валюта, в которую пересчитываются суммы
+	Currency string
 }
 
 func (f *TotalFilter) Validate() error {
-	if f.From.IsZero() || f.To.IsZero() {
-		return errors.New("'from' and 'to' dates are required")
+	verr := &ValidationError{}
+
+	if f.From.IsZero() {
+		verr.Add("from", "date is required")
+	}
+
+	if f.To.IsZero() {
+		verr.Add("to", "date is required")
+	}
+
+	switch {
+	case f.From.IsZero() || f.To.IsZero():
+	case f.To.Before(f.From):
+		verr.Add("to", "'to' must be after 'from'")
+	case monthsBetween(f.From, f.To) >= MaxTotalMonths:
+		verr.Add("to", fmt.Sprintf("period must be at most %d months", MaxTotalMonths))
+	}
+
+	if err := ValidateCurrency(f.Currency); err != nil {
+		verr.Add("currency", err.Error())
+	}
+
+	seen := make(map[TotalGroupBy]bool, len(f.GroupBy))
+	for _, g := range f.GroupBy {
+		if g != GroupByServiceName && g != GroupByUserID {
+			verr.Add("group_by", fmt.Sprintf("unsupported group_by value %q", g))
+			continue
+		}
+		if seen[g] {
+			verr.Add("group_by", fmt.Sprintf("duplicate group_by value %q", g))
+		}
+		seen[g] = true
 	}
 
-	if f.To.Before(f.From) {
-		return errors.New("'to' must be after 'from'")
+	return verr.Err()
+}
+
+// число месяцев от месяца from до месяца to
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
+
+// MonthlyTotal сумма списаний и число активных подписок за один календарный месяц.
+// Репозиторий возвращает суммы в валюте подписок, сервис - в валюте отчета
+type MonthlyTotal struct {
+	Month         time.Time
+	Amount        int
+	Currency      string
+	Subscriptions int
+}
+
+// GroupedTotal сумма списаний по одной группе. Заполнены только те поля,
+// по которым выполнялась группировка (TotalFilter.GroupBy).
+// Репозиторий возвращает суммы в валюте подписок, сервис - в валюте отчета
+type GroupedTotal struct {
+	ServiceName *string
+	UserID      *uuid.UUID
+	Total       int
+	Currency    string
+}
+
+// ListSort поле сортировки списка подписок
+type ListSort string
+
+const (
+	SortByStartDate   ListSort = "start_date"
+	SortByPrice       ListSort = "price"
+	SortByServiceName ListSort = "service_name"
+	SortByCreatedAt   ListSort = "created_at"
+)
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+	MaxSearchLength  = 100
+)
+
+// ListCursor позиция в списке подписок: значение поля сортировки и ID
+// последней подписки предыдущей страницы. Привязан к сортировке, с которой получен
+type ListCursor struct {
+	Sort  ListSort
+	Desc  bool
+	Value string
+	ID    uuid.UUID
+}
+
+// ValidValue значение курсора можно сравнить с полем сортировки Sort:
+// оно в том же формате, что и в CursorAfter
+func (c *ListCursor) ValidValue() bool {
+	switch c.Sort {
+	case SortByStartDate:
+		date, err := time.Parse(time.DateOnly, c.Value)
+		return err == nil && date.Year() > 0
+	case SortByPrice:
+		_, err := strconv.ParseInt(c.Value, 10, 64)
+		return err == nil
+	case SortByServiceName:
+		return utf8.ValidString(c.Value) && !strings.ContainsRune(c.Value, 0)
+	case SortByCreatedAt:
+		date, err := time.Parse(time.RFC3339Nano, c.Value)
+		return err == nil && date.Year() > 0
 	}
 
-	return nil
+	return false
+}
+
+// ListFilter фильтры, сортировка и пагинация списка подписок.
+// Даты - первые числа месяцев (MM-YYYY), границы диапазонов включительные
+type ListFilter struct {
+	// ограничение по владельцу подписок; задается service слоем по вызывающему клиенту
+	OwnerID     *uuid.UUID
+	UserID      *uuid.UUID
+	ServiceName *string
+	// подстрока названия или синонима сервиса без учета регистра
+	Search string
+	// подписка активна в этом месяце
+	ActiveOn  *time.Time
+	StartFrom *time.Time
+	StartTo   *time.Time
+	EndFrom   *time.Time
+	EndTo     *time.Time
+	PriceMin  *int
+	PriceMax  *int
+	// true - только бессрочные подписки, false - только с датой окончания
+	OpenEnded *bool
+	// включать удаленные подписки; доступно только администратору
+	IncludeDeleted bool
+
+	Sort   ListSort
+	Desc   bool
+	Limit  int
+	Cursor *ListCursor
+}
+
+func (f *ListFilter) Validate() error {
+	verr := &ValidationError{}
+
+	if f.StartFrom != nil && f.StartTo != nil && f.StartTo.Before(*f.StartFrom) {
+		verr.Add("start_to", "'start_to' must be after 'start_from'")
+	}
+
+	if f.EndFrom != nil && f.EndTo != nil && f.EndTo.Before(*f.EndFrom) {
+		verr.Add("end_to", "'end_to' must be after 'end_from'")
+	}
+
+	if f.PriceMin != nil && *f.PriceMin < 0 {
+		verr.Add("price_min", "'price_min' cannot be negative")
+	}
+
+	if f.PriceMin != nil && f.PriceMax != nil && *f.PriceMax < *f.PriceMin {
+		verr.Add("price_max", "'price_max' must not be less than 'price_min'")
+	}
+
+	if f.OpenEnded != nil && *f.OpenEnded && (f.EndFrom != nil || f.EndTo != nil) {
+		verr.Add("open_ended", "end date range cannot be combined with open_ended=true")
+	}
+
+	if utf8.RuneCountInString(f.Search) > MaxSearchLength {
+		verr.Add("q", fmt.Sprintf("search query must be at most %d characters", MaxSearchLength))
+	}
+
+	switch f.Sort {
+	case SortByStartDate, SortByPrice, SortByServiceName, SortByCreatedAt:
+	default:
+		verr.Add("sort", fmt.Sprintf("unsupported sort value %q", f.Sort))
+	}
+
+	if f.Limit <= 0 || f.Limit > MaxListLimit {
+		verr.Add("limit", fmt.Sprintf("limit must be between 1 and %d", MaxListLimit))
+	}
+
+	if f.Cursor != nil && (f.Cursor.Sort != f.Sort || f.Cursor.Desc != f.Desc) {
+		verr.Add("cursor", "cursor was issued for a different sort order")
+	} else if f.Cursor != nil && !f.Cursor.ValidValue() {
+		verr.Add("cursor", "invalid cursor")
+	}
+
+	return verr.Err()
+}
+
+// CursorAfter курсор, указывающий на позицию сразу после подписки sub
+func (f *ListFilter) CursorAfter(sub *Subscription) ListCursor {
+	var value string
+
+	switch f.Sort {
+	case SortByStartDate:
+		value = sub.StartDate.Format(time.DateOnly)
+	case SortByPrice:
+		value = strconv.Itoa(sub.Price)
+	case SortByServiceName:
+		value = sub.ServiceName
+	case SortByCreatedAt:
+		value = sub.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	return ListCursor{Sort: f.Sort, Desc: f.Desc, Value: value, ID: sub.ID}
+}
+
+// SubscriptionPage страница списка подписок; NextCursor == nil на последней странице
+type SubscriptionPage struct {
+	Items      []Subscription
+	NextCursor *ListCursor
 }
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTotalFilterPeriod(t *testing.T) {
+	month := func(year int, m time.Month) time.Time { return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		field    string // поле ошибки; "" - период допустим
+	}{
+		{"one month", month(2025, time.January), month(2025, time.January), ""},
+		{"year", month(2025, time.January), month(2025, time.December), ""},
+		{"max months", month(2016, time.January), month(2025, time.December), ""},
+		{"too long", month(2015, time.December), month(2025, time.December), "to"},
+		{"centuries", month(1000, time.January), month(9999, time.December), "to"},
+		{"to before from", month(2025, time.February), month(2025, time.January), "to"},
+		{"no from", time.Time{}, month(2025, time.January), "from"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &TotalFilter{From: tt.from, To: tt.to, Currency: DefaultCurrency}
+
+			err := f.Validate()
+			if tt.field == "" {
+				if err != nil {
+					t.Errorf("Validate: %v", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) || len(verr.Fields) != 1 || verr.Fields[0].Field != tt.field {
+				t.Errorf("error %v, want validation error on %s", err, tt.field)
+			}
+		})
+	}
+}
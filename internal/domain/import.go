@@ -0,0 +1,27 @@
+package domain
+
+// MaxImportRows наибольшее число подписок в одном импорте
+const MaxImportRows = 10000
+
+// ImportRow подписка из строки Row импортируемого файла
+type ImportRow struct {
+	Row          int
+	Subscription Subscription
+}
+
+// ImportRowError ошибки по полям строки Row; строка не импортируется
+type ImportRowError struct {
+	Row    int
+	Fields []FieldError
+}
+
+// ImportResult отчет об импорте. Строки с ошибками пропускаются, остальные
+// сохраняются вместе; при пробном импорте Imported = 0
+type ImportResult struct {
+	// строк с данными в файле
+	Rows     int
+	Valid    int
+	Imported int
+	// ошибки в порядке строк
+	Errors []ImportRowError
+}
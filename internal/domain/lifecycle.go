@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// SubscriptionStatus состояние подписки
+type SubscriptionStatus string
+
+const (
+	// активная подписка до TrialUntil; отдельно не хранится
+	StatusTrial     SubscriptionStatus = "trial"
+	StatusActive    SubscriptionStatus = "active"
+	StatusPaused    SubscriptionStatus = "paused"
+	StatusCancelled SubscriptionStatus = "cancelled"
+)
+
+var ErrInvalidStatusTransition = fmt.Errorf("invalid subscription status transition: %w", ErrConflict)
+
+// Pause приостановка подписки: списания в дни [From, Until) не выставляются
+type Pause struct {
+	From time.Time
+	// nil - подписка приостановлена сейчас
+	Until *time.Time
+}
+
+// начало дня t в UTC; даты списаний, пауз и отмены хранятся с точностью до дня
+func dayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// StatusAt состояние подписки на момент now с учетом пробного периода.
+// Тот же расчет выполняется в SQL в postgres.SubscriptionRepository
+func (s *Subscription) StatusAt(now time.Time) SubscriptionStatus {
+	switch s.Status {
+	case StatusPaused, StatusCancelled:
+		return s.Status
+	}
+
+	if s.TrialUntil != nil && dayStart(now).Before(*s.TrialUntil) {
+		return StatusTrial
+	}
+
+	return StatusActive
+}
+
+// billable списание в день charge выставляется: оно не попадает в пробный период
+// и паузы и предшествует отмене
+func (s *Subscription) billable(charge time.Time) bool {
+	if s.TrialUntil != nil && charge.Before(*s.TrialUntil) {
+		return false
+	}
+
+	if s.CancelAt != nil && !charge.Before(*s.CancelAt) {
+		return false
+	}
+
+	for _, p := range s.Pauses {
+		if !charge.Before(p.From) && (p.Until == nil || charge.Before(*p.Until)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Pause приостанавливает подписку с дня now до Resume
+func (s *Subscription) Pause(now time.Time) error {
+	if st := s.StatusAt(now); st != StatusActive && st != StatusTrial {
+		return fmt.Errorf("cannot pause %s subscription: %w", st, ErrInvalidStatusTransition)
+	}
+
+	today := dayStart(now)
+
+	// пауза, снятая в тот же день, продолжается
+	if n := len(s.Pauses); n > 0 && s.Pauses[n-1].From.Equal(today) {
+		s.Pauses[n-1].Until = nil
+	} else {
+		s.Pauses = append(s.Pauses, Pause{From: today})
+	}
+
+	s.Status = StatusPaused
+	return nil
+}
+
+// Resume возобновляет приостановленную подписку с дня now
+func (s *Subscription) Resume(now time.Time) error {
+	if s.Status != StatusPaused {
+		return fmt.Errorf("cannot resume %s subscription: %w", s.StatusAt(now), ErrInvalidStatusTransition)
+	}
+
+	s.endPause(now)
+	// в пробном периоде подписка возвращается в StatusTrial
+	s.Status = StatusActive
+	s.Status = s.StatusAt(now)
+
+	return nil
+}
+
+// Cancel отменяет подписку в конце оплаченного периода: первое списание
+// после дня now и все следующие не выставляются, end_date сокращается
+// до месяца последнего оплаченного дня
+func (s *Subscription) Cancel(now time.Time) error {
+	if s.Status == StatusCancelled {
+		return fmt.Errorf("cannot cancel cancelled subscription: %w", ErrInvalidStatusTransition)
+	}
+
+	s.endPause(now)
+	s.Status = StatusCancelled
+
+	next, ok := s.chargeAfter(now)
+	if !ok {
+		// списаний больше не будет, подписка и так заканчивается
+		return nil
+	}
+	s.CancelAt = &next
+
+	// подписка, которая еще не началась, остается с прежним end_date:
+	// ее списания исключает CancelAt
+	end := monthStart(next.AddDate(0, 0, -1))
+	if !end.Before(monthStart(s.StartDate)) && (s.EndDate == nil || end.Before(*s.EndDate)) {
+		s.EndDate = &end
+	}
+
+	return nil
+}
+
+func (s *Subscription) endPause(now time.Time) {
+	if n := len(s.Pauses); n > 0 && s.Pauses[n-1].Until == nil {
+		today := dayStart(now)
+		s.Pauses[n-1].Until = &today
+	}
+}
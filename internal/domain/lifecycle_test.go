@@ -0,0 +1,325 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// ежемесячная подписка с 15 января 2025: списания первого числа каждого месяца
+func monthlySubscription() *Subscription {
+	return &Subscription{
+		Price:         100,
+		BillingPeriod: BillingPeriodMonth,
+		StartDate:     date(2025, time.January, 15),
+		Status:        StatusActive,
+	}
+}
+
+func TestStatusAt(t *testing.T) {
+	trial := datePtr(2025, time.March, 1)
+
+	tests := []struct {
+		name string
+		sub  Subscription
+		now  time.Time
+		want SubscriptionStatus
+	}{
+		{"active", Subscription{Status: StatusActive}, date(2025, time.February, 10), StatusActive},
+		{"in trial", Subscription{Status: StatusActive, TrialUntil: trial}, date(2025, time.February, 10), StatusTrial},
+		{
+			"last trial day",
+			Subscription{Status: StatusActive, TrialUntil: trial},
+			time.Date(2025, time.February, 28, 23, 59, 0, 0, time.UTC),
+			StatusTrial,
+		},
+		// TrialUntil - первый оплачиваемый день
+		{"trial ends", Subscription{Status: StatusActive, TrialUntil: trial}, date(2025, time.March, 1), StatusActive},
+		{"paused in trial", Subscription{Status: StatusPaused, TrialUntil: trial}, date(2025, time.February, 10), StatusPaused},
+		{"cancelled in trial", Subscription{Status: StatusCancelled, TrialUntil: trial}, date(2025, time.February, 10), StatusCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sub.StatusAt(tt.now); got != tt.want {
+				t.Errorf("StatusAt(%s) = %s, want %s", tt.now.Format(time.DateTime), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBillable(t *testing.T) {
+	tests := []struct {
+		name   string
+		mod    func(s *Subscription)
+		charge time.Time
+		want   bool
+	}{
+		{"no restrictions", nil, date(2025, time.March, 1), true},
+		{"in trial", func(s *Subscription) { s.TrialUntil = datePtr(2025, time.March, 1) }, date(2025, time.February, 1), false},
+		{"trial end day", func(s *Subscription) { s.TrialUntil = datePtr(2025, time.March, 1) }, date(2025, time.March, 1), true},
+		{"before cancel", func(s *Subscription) { s.CancelAt = datePtr(2025, time.April, 1) }, date(2025, time.March, 1), true},
+		{"cancel day", func(s *Subscription) { s.CancelAt = datePtr(2025, time.April, 1) }, date(2025, time.April, 1), false},
+		{
+			"pause start day",
+			func(s *Subscription) {
+				s.Pauses = []Pause{{From: date(2025, time.March, 1), Until: datePtr(2025, time.March, 10)}}
+			},
+			date(2025, time.March, 1),
+			false,
+		},
+		{
+			"resume day",
+			func(s *Subscription) {
+				s.Pauses = []Pause{{From: date(2025, time.February, 20), Until: datePtr(2025, time.March, 1)}}
+			},
+			date(2025, time.March, 1),
+			true,
+		},
+		{
+			"open pause",
+			func(s *Subscription) { s.Pauses = []Pause{{From: date(2025, time.February, 20)}} },
+			date(2025, time.June, 1),
+			false,
+		},
+		{
+			"between pauses",
+			func(s *Subscription) {
+				s.Pauses = []Pause{
+					{From: date(2025, time.February, 20), Until: datePtr(2025, time.February, 25)},
+					{From: date(2025, time.March, 20)},
+				}
+			},
+			date(2025, time.March, 1),
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := monthlySubscription()
+			if tt.mod != nil {
+				tt.mod(sub)
+			}
+
+			if got := sub.billable(tt.charge); got != tt.want {
+				t.Errorf("billable(%s) = %v, want %v", tt.charge.Format(time.DateOnly), got, tt.want)
+			}
+		})
+	}
+}
+
+// chargeDays даты списаний подписки за 2025 год в формате YYYY-MM-DD
+func chargeDays(s *Subscription) []string {
+	var days []string
+	for _, d := range s.ChargeDates(date(2025, time.January, 1), date(2025, time.December, 1)) {
+		days = append(days, d.Format(time.DateOnly))
+	}
+
+	return days
+}
+
+func TestPauseSpanningChargeDate(t *testing.T) {
+	sub := monthlySubscription()
+	sub.EndDate = datePtr(2025, time.May, 1)
+
+	if err := sub.Pause(time.Date(2025, time.February, 20, 15, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	if sub.Status != StatusPaused {
+		t.Errorf("status after Pause %s, want %s", sub.Status, StatusPaused)
+	}
+
+	// приостановленная подписка не оплачивается до возобновления
+	if got, want := chargeDays(sub), []string{"2025-01-01", "2025-02-01"}; !slices.Equal(got, want) {
+		t.Errorf("charges while paused %v, want %v", got, want)
+	}
+
+	if err := sub.Resume(date(2025, time.April, 10)); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if sub.Status != StatusActive {
+		t.Errorf("status after Resume %s, want %s", sub.Status, StatusActive)
+	}
+
+	want := Pause{From: date(2025, time.February, 20), Until: datePtr(2025, time.April, 10)}
+	if len(sub.Pauses) != 1 || !sub.Pauses[0].From.Equal(want.From) || !sub.Pauses[0].Until.Equal(*want.Until) {
+		t.Errorf("pauses %+v, want [%+v]", sub.Pauses, want)
+	}
+
+	// списания 1 марта и 1 апреля приходятся на паузу
+	if got, want := chargeDays(sub), []string{"2025-01-01", "2025-02-01", "2025-05-01"}; !slices.Equal(got, want) {
+		t.Errorf("charges after Resume %v, want %v", got, want)
+	}
+}
+
+func TestPauseResumeTransitions(t *testing.T) {
+	t.Run("resume in trial returns to trial", func(t *testing.T) {
+		sub := monthlySubscription()
+		sub.TrialUntil = datePtr(2025, time.March, 1)
+
+		if err := sub.Pause(date(2025, time.February, 5)); err != nil {
+			t.Fatalf("Pause: %v", err)
+		}
+		if err := sub.Resume(date(2025, time.February, 10)); err != nil {
+			t.Fatalf("Resume: %v", err)
+		}
+		if sub.Status != StatusTrial {
+			t.Errorf("status %s, want %s", sub.Status, StatusTrial)
+		}
+	})
+
+	t.Run("pause on the day of resume continues the pause", func(t *testing.T) {
+		sub := monthlySubscription()
+		day := date(2025, time.March, 5)
+
+		for _, step := range []func(time.Time) error{sub.Pause, sub.Resume, sub.Pause} {
+			if err := step(day); err != nil {
+				t.Fatalf("transition: %v", err)
+			}
+		}
+
+		if len(sub.Pauses) != 1 || sub.Pauses[0].Until != nil {
+			t.Errorf("pauses %+v, want one open pause", sub.Pauses)
+		}
+	})
+
+	t.Run("invalid transitions", func(t *testing.T) {
+		now := date(2025, time.March, 5)
+
+		active := monthlySubscription()
+		if err := active.Resume(now); !errors.Is(err, ErrInvalidStatusTransition) {
+			t.Errorf("Resume active: %v, want ErrInvalidStatusTransition", err)
+		}
+
+		paused := monthlySubscription()
+		paused.Status = StatusPaused
+		if err := paused.Pause(now); !errors.Is(err, ErrInvalidStatusTransition) {
+			t.Errorf("Pause paused: %v, want ErrInvalidStatusTransition", err)
+		}
+
+		cancelled := monthlySubscription()
+		cancelled.Status = StatusCancelled
+		if err := cancelled.Pause(now); !errors.Is(err, ErrInvalidStatusTransition) {
+			t.Errorf("Pause cancelled: %v, want ErrInvalidStatusTransition", err)
+		}
+		if err := cancelled.Cancel(now); !errors.Is(err, ErrInvalidStatusTransition) {
+			t.Errorf("Cancel cancelled: %v, want ErrInvalidStatusTransition", err)
+		}
+	})
+}
+
+func TestCancel(t *testing.T) {
+	tests := []struct {
+		name         string
+		mod          func(s *Subscription)
+		now          time.Time
+		wantCancelAt *time.Time
+		wantEndDate  *time.Time
+		wantCharges  []string
+	}{
+		{
+			// оплаченный март дорабатывает, апрельское списание уже не выставляется
+			name:         "cancel at period end",
+			now:          date(2025, time.March, 10),
+			wantCancelAt: datePtr(2025, time.April, 1),
+			wantEndDate:  datePtr(2025, time.March, 1),
+			wantCharges:  []string{"2025-01-01", "2025-02-01", "2025-03-01"},
+		},
+		{
+			// списание дня отмены уже выставлено
+			name:         "cancel on charge day",
+			now:          time.Date(2025, time.March, 1, 18, 0, 0, 0, time.UTC),
+			wantCancelAt: datePtr(2025, time.April, 1),
+			wantEndDate:  datePtr(2025, time.March, 1),
+			wantCharges:  []string{"2025-01-01", "2025-02-01", "2025-03-01"},
+		},
+		{
+			name:         "yearly subscription",
+			mod:          func(s *Subscription) { s.BillingPeriod = BillingPeriodYear; s.StartDate = date(2024, time.May, 1) },
+			now:          date(2025, time.February, 10),
+			wantCancelAt: datePtr(2025, time.May, 1),
+			wantEndDate:  datePtr(2025, time.April, 1),
+			wantCharges:  nil,
+		},
+		{
+			name:        "no charges left before end date",
+			mod:         func(s *Subscription) { s.EndDate = datePtr(2025, time.March, 1) },
+			now:         date(2025, time.March, 10),
+			wantEndDate: datePtr(2025, time.March, 1),
+			wantCharges: []string{"2025-01-01", "2025-02-01", "2025-03-01"},
+		},
+		{
+			// end_date не сокращается раньше начала, списания исключает CancelAt
+			name:         "not started yet",
+			mod:          func(s *Subscription) { s.StartDate = date(2025, time.June, 1) },
+			now:          date(2025, time.March, 10),
+			wantCancelAt: datePtr(2025, time.June, 1),
+			wantCharges:  nil,
+		},
+		{
+			name: "weekly subscription",
+			mod: func(s *Subscription) {
+				s.BillingPeriod = BillingPeriodWeek
+				s.StartDate = date(2025, time.November, 28)
+			},
+			now:          date(2025, time.December, 3),
+			wantCancelAt: datePtr(2025, time.December, 5),
+			wantEndDate:  datePtr(2025, time.December, 1),
+			wantCharges:  []string{"2025-11-28"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := monthlySubscription()
+			if tt.mod != nil {
+				tt.mod(sub)
+			}
+
+			if err := sub.Cancel(tt.now); err != nil {
+				t.Fatalf("Cancel: %v", err)
+			}
+
+			if sub.Status != StatusCancelled {
+				t.Errorf("status %s, want %s", sub.Status, StatusCancelled)
+			}
+			if !equalDatePtr(sub.CancelAt, tt.wantCancelAt) {
+				t.Errorf("cancel_at %v, want %v", sub.CancelAt, tt.wantCancelAt)
+			}
+			if !equalDatePtr(sub.EndDate, tt.wantEndDate) {
+				t.Errorf("end_date %v, want %v", sub.EndDate, tt.wantEndDate)
+			}
+			if got := chargeDays(sub); !slices.Equal(got, tt.wantCharges) {
+				t.Errorf("charges %v, want %v", got, tt.wantCharges)
+			}
+		})
+	}
+}
+
+func TestCancelEndsPause(t *testing.T) {
+	sub := monthlySubscription()
+	if err := sub.Pause(date(2025, time.February, 20)); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+
+	if err := sub.Cancel(date(2025, time.March, 10)); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+
+	if len(sub.Pauses) != 1 || !equalDatePtr(sub.Pauses[0].Until, datePtr(2025, time.March, 10)) {
+		t.Errorf("pauses %+v, want pause closed on 2025-03-10", sub.Pauses)
+	}
+	if got, want := chargeDays(sub), []string{"2025-01-01", "2025-02-01"}; !slices.Equal(got, want) {
+		t.Errorf("charges %v, want %v", got, want)
+	}
+}
+
+func equalDatePtr(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
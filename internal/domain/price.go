@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrPriceChangeNotFound = fmt.Errorf("price change %w", ErrNotFound)
+
+// PriceChange изменение цены подписки, действующее с месяца EffectiveFrom.
+// До первого изменения действует Subscription.Price
+type PriceChange struct {
+	SubscriptionID uuid.UUID
+	Price          int // в минорных единицах валюты подписки
+	EffectiveFrom  time.Time
+	CreatedAt      time.Time
+}
+
+func (c *PriceChange) Validate(sub *Subscription) error {
+	verr := &ValidationError{}
+
+	if c.Price <= 0 {
+		verr.Add("price", "price must be positive")
+	}
+
+	switch {
+	case c.EffectiveFrom.IsZero():
+		verr.Add("effective_from", "effective_from is required")
+	case monthStart(c.EffectiveFrom).Before(monthStart(sub.StartDate)):
+		verr.Add("effective_from", "price change cannot take effect before subscription start date")
+	case sub.EndDate != nil && monthStart(c.EffectiveFrom).After(monthStart(*sub.EndDate)):
+		verr.Add("effective_from", "price change cannot take effect after subscription end date")
+	}
+
+	return verr.Err()
+}
+
+// PriceAt возвращает цену, действующую на дату списания.
+// changes - история изменений цены этой подписки в любом порядке
+func (s *Subscription) PriceAt(changes []PriceChange, date time.Time) int {
+	price := s.Price
+
+	var effective time.Time
+	for _, c := range changes {
+		from := monthStart(c.EffectiveFrom)
+		if !from.After(date) && !from.Before(effective) {
+			price = c.Price
+			effective = from
+		}
+	}
+
+	return price
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reminder напоминание о предстоящем списании по подписке
+type Reminder struct {
+	TenantID       string        `json:"tenant_id"`
+	SubscriptionID uuid.UUID     `json:"subscription_id"`
+	UserID         uuid.UUID     `json:"user_id"`
+	ServiceName    string        `json:"service_name"`
+	BillingPeriod  BillingPeriod `json:"billing_period"`
+	ChargeDate     time.Time     `json:"charge_date"`
+	// цена, действующая на дату списания, в минорных единицах валюты Currency
+	Amount   int    `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// NewReminder напоминание о списании по подписке sub в день chargeDate.
+// changes - история изменений цены подписки
+func NewReminder(tenantID string, sub *Subscription, chargeDate time.Time, changes []PriceChange) *Reminder {
+	return &Reminder{
+		TenantID:       tenantID,
+		SubscriptionID: sub.ID,
+		UserID:         sub.UserID,
+		ServiceName:    sub.ServiceName,
+		BillingPeriod:  sub.BillingPeriod,
+		ChargeDate:     chargeDate,
+		Amount:         sub.PriceAt(changes, chargeDate),
+		Currency:       sub.Currency,
+	}
+}
+
+func (r *Reminder) Money() Money {
+	return Money{Amount: r.Amount, Currency: r.Currency}
+}
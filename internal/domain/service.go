@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrServiceNotFound  = fmt.Errorf("service %w", ErrNotFound)
+	ErrServiceNameTaken = fmt.Errorf("%w: service name or alias is already used by another service", ErrConflict)
+	ErrServiceInUse     = fmt.Errorf("%w: service has subscriptions", ErrConflict)
+)
+
+// Service сервис из каталога. Подписки ссылаются на него по ID,
+// название ищется без учета регистра по Name и Aliases
+type Service struct {
+	ID           uuid.UUID
+	Name         string
+	Category     string
+	DefaultPrice *int // в минорных единицах валюты Currency
+	Currency     string
+	Website      string
+	Aliases      []string
+}
+
+// NormalizeServiceName убирает лишние пробелы в названии сервиса
+func NormalizeServiceName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+func (s *Service) Validate() error {
+	verr := &ValidationError{}
+
+	if s.Name == "" {
+		verr.Add("name", "service name is required")
+	}
+
+	if s.DefaultPrice != nil && *s.DefaultPrice <= 0 {
+		verr.Add("default_price", "default price must be positive")
+	}
+
+	if err := ValidateCurrency(s.Currency); err != nil {
+		verr.Add("currency", err.Error())
+	}
+
+	if s.Website != "" {
+		u, err := url.Parse(s.Website)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			verr.Add("website", "website must be an http(s) URL")
+		}
+	}
+
+	seen := map[string]bool{strings.ToLower(s.Name): true}
+	for _, alias := range s.Aliases {
+		if alias == "" {
+			verr.Add("aliases", "alias cannot be empty")
+			continue
+		}
+		if seen[strings.ToLower(alias)] {
+			verr.Add("aliases", "aliases must differ from each other and from the name")
+		}
+		seen[strings.ToLower(alias)] = true
+	}
+
+	return verr.Err()
+}
@@ -1,37 +1,76 @@
 package domain
 
 import (
-	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrSubscriptionNotFound   = fmt.Errorf("subscription %w", ErrNotFound)
+	ErrSubscriptionNotDeleted = fmt.Errorf("subscription is not deleted: %w", ErrConflict)
+)
+
 type Subscription struct {
-	ID          uuid.UUID
-	ServiceName string
-	Price       int
-	UserID      uuid.UUID
-	StartDate   time.Time
-	EndDate     *time.Time
+	ID            uuid.UUID
+	ServiceID     uuid.UUID
+	ServiceName   string // название сервиса из каталога
+	Price         int    // в минорных единицах валюты Currency
+	Currency      string
+	BillingPeriod BillingPeriod
+	UserID        uuid.UUID
+	StartDate     time.Time
+	EndDate       *time.Time
+	// хранится active, paused или cancelled; при чтении активная подписка
+	// в пробном периоде получает StatusTrial (см. StatusAt)
+	Status SubscriptionStatus
+	// первый день после пробного периода; списания до него бесплатны
+	TrialUntil *time.Time
+	// первое списание, которое не выставляется после отмены
+	CancelAt *time.Time
+	// приостановки в порядке начала
+	Pauses    []Pause
+	CreatedAt time.Time
+	// время удаления; удаленная подписка не участвует в списках и подсчетах
+	// и окончательно удаляется по истечении срока хранения
+	DeletedAt *time.Time
 }
 
 func (s *Subscription) Validate() error {
-	if s.ServiceName == "" {
-		return errors.New("service name is required")
+	verr := &ValidationError{}
+
+	if s.ServiceID == uuid.Nil {
+		verr.Add("service_id", "service is required")
+	}
+
+	if s.UserID == uuid.Nil {
+		verr.Add("user_id", "user_id is required")
 	}
 
 	if s.Price <= 0 {
-		return errors.New("price must be positive")
+		verr.Add("price", "price must be positive")
+	}
+
+	if err := ValidateCurrency(s.Currency); err != nil {
+		verr.Add("currency", err.Error())
+	}
+
+	if err := s.BillingPeriod.Validate(); err != nil {
+		verr.Add("billing_period", err.Error())
 	}
 
 	if s.StartDate.IsZero() {
-		return errors.New("start date is required")
+		verr.Add("start_date", "start date is required")
 	}
 
 	if s.EndDate != nil && s.EndDate.Before(s.StartDate) {
-		return errors.New("end date cannot be before start date")
+		verr.Add("end_date", "end date cannot be before start date")
+	}
+
+	if s.TrialUntil != nil && s.TrialUntil.Before(s.StartDate) {
+		verr.Add("trial_until", "trial cannot end before start date")
 	}
 
-	return nil
+	return verr.Err()
 }
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// DefaultTenant арендатор в однопользовательском режиме и для данных,
+// созданных до появления арендаторов
+const DefaultTenant = "default"
+
+// ErrNoTenant запрос дошел до хранилища без арендатора - ошибка в коде, а не клиента
+var ErrNoTenant = errors.New("tenant is not set in context")
+
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+func ValidateTenantID(id string) error {
+	if !tenantIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid tenant id %q", id)
+	}
+
+	return nil
+}
+
+// TenantFromContext возвращает арендатора клиента из контекста запроса
+func TenantFromContext(ctx context.Context) (string, error) {
+	p := PrincipalFromContext(ctx)
+	if p == nil || p.TenantID == "" {
+		return "", ErrNoTenant
+	}
+
+	return p.TenantID, nil
+}
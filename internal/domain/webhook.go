@@ -0,0 +1,197 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrWebhookNotFound         = fmt.Errorf("webhook %w", ErrNotFound)
+	ErrWebhookDeliveryNotFound = fmt.Errorf("webhook delivery %w", ErrNotFound)
+	ErrWebhookDeliveryNotDead  = fmt.Errorf("webhook delivery is not dead: %w", ErrConflict)
+)
+
+// минимальная длина секрета подписи
+const MinWebhookSecretLength = 16
+
+// Webhook адрес клиента, на который отправляются события о подписках.
+// Вебхук пользователя получает события только о его подписках, вебхук
+// администратора (OwnerID == nil) - обо всех подписках арендатора
+type Webhook struct {
+	ID      uuid.UUID
+	OwnerID *uuid.UUID
+	URL     string
+	// ключ HMAC-подписи тела запроса; клиенту показывается только при создании
+	Secret     string `json:"-"`
+	EventTypes []EventType
+	Active     bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (w *Webhook) Validate() error {
+	verr := &ValidationError{}
+
+	if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		verr.Add("url", "url must be an absolute http or https URL")
+	} else if !publicHost(u.Hostname()) {
+		verr.Add("url", "url must point to a public address")
+	}
+
+	if len(w.Secret) < MinWebhookSecretLength {
+		verr.Add("secret", fmt.Sprintf("secret must be at least %d characters", MinWebhookSecretLength))
+	}
+
+	if len(w.EventTypes) == 0 {
+		verr.Add("event_types", "at least one event type is required")
+	}
+
+	seen := make(map[EventType]bool, len(w.EventTypes))
+	for _, t := range w.EventTypes {
+		if !t.Valid() {
+			verr.Add("event_types", fmt.Sprintf("unsupported event type %q", t))
+			continue
+		}
+		if seen[t] {
+			verr.Add("event_types", fmt.Sprintf("duplicate event type %q", t))
+		}
+		seen[t] = true
+	}
+
+	return verr.Err()
+}
+
+// непубличные сети, не покрытые методами netip.Addr
+var internalPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	// CGNAT
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// PublicAddr адрес доступен из интернета: не loopback, не частная сеть, не link-local
+// (в том числе адрес метаданных облака 169.254.169.254) и не multicast.
+// Вебхуки доставляются только на публичные адреса
+func PublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+
+	for _, p := range internalPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// publicHost отсекает заведомо внутренние хосты; имена проверяются повторно
+// после разрешения при соединении
+func publicHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return PublicAddr(addr)
+	}
+
+	return true
+}
+
+// WebhookEvent событие для постановки в очередь вебхуков вместе с владельцем подписки
+type WebhookEvent struct {
+	Event   *Event
+	OwnerID uuid.UUID
+}
+
+// DeliveryStatus состояние доставки события на вебхук
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// попытки исчерпаны; доставку можно повторить вручную
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+// WebhookDelivery доставка одного события на один вебхук
+type WebhookDelivery struct {
+	ID        int64
+	WebhookID uuid.UUID
+	EventID   uuid.UUID
+	EventType EventType
+	// тело запроса - событие в JSON, одинаковое во всех попытках
+	Payload        json.RawMessage `swaggertype:"object"`
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  *time.Time
+	LastStatusCode *int
+	LastError      *string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// WebhookJob доставка, которую пора выполнить, вместе с адресом и секретом вебхука
+type WebhookJob struct {
+	Delivery WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+// DeliveryAttempt результат попытки доставки
+type DeliveryAttempt struct {
+	Status DeliveryStatus
+	// 0 - ответ не получен
+	StatusCode int
+	Error      string
+	// задержка до следующей попытки для Status == DeliveryPending
+	RetryAfter time.Duration
+}
+
+const (
+	DefaultDeliveryLimit = 50
+	MaxDeliveryLimit     = 500
+)
+
+// DeliveryFilter фильтр журнала доставок вебхука; записи возвращаются от новых к старым
+type DeliveryFilter struct {
+	Status DeliveryStatus
+	// доставки с ID меньше BeforeID, для постраничного чтения
+	BeforeID *int64
+	Limit    int
+}
+
+func (f *DeliveryFilter) Validate() error {
+	verr := &ValidationError{}
+
+	switch f.Status {
+	case "", DeliveryPending, DeliveryDelivered, DeliveryDead:
+	default:
+		verr.Add("status", fmt.Sprintf("unsupported status %q", f.Status))
+	}
+
+	if f.Limit <= 0 || f.Limit > MaxDeliveryLimit {
+		verr.Add("limit", fmt.Sprintf("limit must be between 1 and %d", MaxDeliveryLimit))
+	}
+
+	return verr.Err()
+}
+
+// DeliveryPage страница журнала доставок; NextBeforeID == nil на последней странице
+type DeliveryPage struct {
+	Items        []WebhookDelivery
+	NextBeforeID *int64
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00:ec2::254", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"100.64.0.1", false},
+		{"224.0.0.1", false},
+		// IPv4, записанный как IPv6
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := PublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("PublicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookValidateURL(t *testing.T) {
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://hooks.example.com/subscriptions", true},
+		{"http://93.184.216.34:8080/hook", true},
+		{"ftp://hooks.example.com", false},
+		{"/hooks", false},
+		{"http://localhost:8080/hook", false},
+		{"http://api.localhost/hook", false},
+		{"http://127.0.0.1/hook", false},
+		{"http://[::1]/hook", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://10.0.0.5/hook", false},
+	}
+
+	for _, tt := range tests {
+		w := &Webhook{
+			URL:        tt.url,
+			Secret:     "0123456789abcdef",
+			EventTypes: []EventType{EventSubscriptionCreated},
+		}
+
+		err := w.Validate()
+		if tt.ok && err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", tt.url, err)
+		}
+		var verr *ValidationError
+		if !tt.ok && !errors.As(err, &verr) {
+			t.Errorf("Validate(%q) = %v, want validation error", tt.url, err)
+		}
+	}
+}
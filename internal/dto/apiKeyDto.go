@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"testTask/internal/domain"
+	"time"
+)
+
+type IssueAPIKeyRequest struct {
+	// назначение ключа, например имя сервиса-клиента
+	Name string `json:"name"`
+	// доступ к /admin/api-keys
+	Admin bool `json:"admin"`
+	// ключ доверенного шлюза: запросы выполняются от имени пользователя
+	// из заголовка TRUSTED_USER_HEADER
+	Gateway bool `json:"gateway"`
+	// арендатор, к которому привязан ключ; без него в режиме MULTI_TENANT
+	// работает только ключ администратора, выбирающий арендатора заголовком X-Tenant-ID
+	TenantID *string `json:"tenant_id"`
+	// срок действия в формате RFC 3339; без него ключ бессрочный
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (r *IssueAPIKeyRequest) Validate() error {
+	if r.Name == "" {
+		return domain.NewValidationError("name", "name is required")
+	}
+	return nil
+}
+
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Admin      bool       `json:"admin"`
+	Gateway    bool       `json:"gateway"`
+	TenantID   *string    `json:"tenant_id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+func NewAPIKeyResponse(k *domain.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         k.ID.String(),
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Admin:      k.Admin,
+		Gateway:    k.Gateway,
+		TenantID:   k.TenantID,
+		CreatedAt:  k.CreatedAt,
+		ExpiresAt:  k.ExpiresAt,
+		RevokedAt:  k.RevokedAt,
+		LastUsedAt: k.LastUsedAt,
+	}
+}
+
+type IssueAPIKeyResponse struct {
+	APIKeyResponse
+	// значение ключа; показывается только один раз
+	Key string `json:"key"`
+}
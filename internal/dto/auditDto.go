@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"encoding/json"
+	"strconv"
+	"testTask/internal/domain"
+	"time"
+)
+
+type AuditEntryResponse struct {
+	ID             int64  `json:"id"`
+	SubscriptionID string `json:"subscription_id"`
+	OwnerID        string `json:"owner_id"`
+	// create, update, delete, restore, price_change_schedule, price_change_cancel, pause, resume, cancel, purge
+	Action string `json:"action"`
+	// sub JWT или ID API-ключа; system - изменение без клиента, cli:<имя> - команда app subscriptions
+	Actor string `json:"actor"`
+	// jwt, api_key, system или cli
+	ActorType   string  `json:"actor_type"`
+	ActorUserID *string `json:"actor_user_id"`
+	// состояние до изменения; null при создании
+	Before json.RawMessage `json:"before" swaggertype:"object"`
+	// состояние после изменения; null при удалении
+	After     json.RawMessage `json:"after" swaggertype:"object"`
+	RequestID string          `json:"request_id"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func NewAuditEntryResponse(e *domain.AuditEntry) AuditEntryResponse {
+	resp := AuditEntryResponse{
+		ID:             e.ID,
+		SubscriptionID: e.SubscriptionID.String(),
+		OwnerID:        e.OwnerID.String(),
+		Action:         string(e.Action),
+		Actor:          e.Actor,
+		ActorType:      e.ActorType,
+		Before:         e.Before,
+		After:          e.After,
+		RequestID:      e.RequestID,
+		CreatedAt:      e.CreatedAt,
+	}
+
+	if e.ActorUserID != nil {
+		userID := e.ActorUserID.String()
+		resp.ActorUserID = &userID
+	}
+
+	return resp
+}
+
+type AuditPageResponse struct {
+	Items []AuditEntryResponse `json:"items"`
+	// курсор следующей страницы; null, если страница последняя
+	NextCursor *string `json:"next_cursor"`
+}
+
+func NewAuditPageResponse(page *domain.AuditPage) AuditPageResponse {
+	resp := AuditPageResponse{Items: make([]AuditEntryResponse, 0, len(page.Items))}
+
+	for i := range page.Items {
+		resp.Items = append(resp.Items, NewAuditEntryResponse(&page.Items[i]))
+	}
+
+	if page.NextBeforeID != nil {
+		cursor := strconv.FormatInt(*page.NextBeforeID, 10)
+		resp.NextCursor = &cursor
+	}
+
+	return resp
+}
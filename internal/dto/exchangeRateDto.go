@@ -0,0 +1,15 @@
+package dto
+
+import "testTask/internal/domain"
+
+type SetExchangeRateRequest struct {
+	// сколько единиц валюты quote стоит 1 единица валюты base
+	Rate float64 `json:"rate"`
+}
+
+func (r *SetExchangeRateRequest) Validate() error {
+	if r.Rate <= 0 {
+		return domain.NewValidationError("rate", "rate must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package dto
+
+// ImportResponse отчет об импорте подписок из CSV
+type ImportResponse struct {
+	// true - подписки только проверены, ничего не сохранено
+	DryRun bool `json:"dry_run"`
+	// строк с данными в файле, без заголовка
+	Rows int `json:"rows"`
+	// строк без ошибок
+	Valid int `json:"valid"`
+	// сохраненных подписок; 0 при dry_run
+	Imported int `json:"imported"`
+	// строки с ошибками; такие строки не импортируются
+	Errors []ImportRowError `json:"errors"`
+}
+
+type ImportRowError struct {
+	// номер строки файла; заголовок - строка 1
+	Row    int                `json:"row" example:"2"`
+	Errors []ImportFieldError `json:"errors"`
+}
+
+type ImportFieldError struct {
+	// колонка файла или поле подписки
+	Field   string `json:"field" example:"start_date"`
+	Message string `json:"message" example:"invalid date, expected MM-YYYY"`
+}
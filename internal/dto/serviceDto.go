@@ -0,0 +1,32 @@
+package dto
+
+import "testTask/internal/domain"
+
+type CreateServiceRequest struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	// цена по умолчанию для новых подписок, в минорных единицах currency
+	DefaultPrice *int `json:"default_price"`
+	// код валюты ISO 4217; по умолчанию RUB
+	Currency string `json:"currency"`
+	Website  string `json:"website"`
+	// другие названия сервиса, например на другом языке
+	Aliases []string `json:"aliases"`
+}
+
+func (r *CreateServiceRequest) Validate() error {
+	if r.Name == "" {
+		return domain.NewValidationError("name", "name is required")
+	}
+	return nil
+}
+
+// поля, которые не переданы, не меняются; aliases заменяются целиком
+type UpdateServiceRequest struct {
+	Name         *string   `json:"name"`
+	Category     *string   `json:"category"`
+	DefaultPrice *int      `json:"default_price"`
+	Currency     *string   `json:"currency"`
+	Website      *string   `json:"website"`
+	Aliases      *[]string `json:"aliases"`
+}
@@ -1,34 +1,79 @@
 package dto
 
-import "errors"
+import "testTask/internal/domain"
 
 type CreateSubscriptionRequest struct {
-	ServiceName string  `json:"service_name"`
-	Price       int     `json:"price"`
-	UserID      string  `json:"user_id"`
-	StartDate   string  `json:"start_date"`
-	EndDate     *string `json:"end_date"`
+	// UUID сервиса из каталога; можно не указывать, если задан service_name
+	ServiceID string `json:"service_id"`
+	// название или синоним сервиса из каталога
+	ServiceName string `json:"service_name"`
+	// цена в минорных единицах валюты (копейки, центы); 0 - цена сервиса по умолчанию в его валюте
+	Price int `json:"price"`
+	// код валюты ISO 4217; по умолчанию валюта сервиса из каталога
+	Currency string `json:"currency"`
+	// week, month, quarter или year; по умолчанию month
+	BillingPeriod string `json:"billing_period"`
+	// для пользователя подставляется автоматически; обязателен для администратора
+	UserID string `json:"user_id"`
+	// первый оплачиваемый месяц, MM-YYYY
+	StartDate string `json:"start_date" binding:"required" example:"07-2025"`
+	// последний оплачиваемый месяц, MM-YYYY; null - бессрочная подписка
+	EndDate *string `json:"end_date"`
+	// первый платный месяц, MM-YYYY; списания до него бесплатны
+	TrialUntil *string `json:"trial_until"`
 }
 
 func (r *CreateSubscriptionRequest) Validate() error {
-	if r.ServiceName == "" {
-		return errors.New("service_name is required")
+	verr := &domain.ValidationError{}
+	if r.ServiceName == "" && r.ServiceID == "" {
+		verr.Add("service_id", "service_id or service_name is required")
 	}
-	if r.Price <= 0 {
-		return errors.New("price must be positive")
-	}
-	if r.UserID == "" {
-		return errors.New("user_id is required")
+	if r.Price < 0 {
+		verr.Add("price", "price cannot be negative")
 	}
 	if r.StartDate == "" {
-		return errors.New("start_date is required")
+		verr.Add("start_date", "start_date is required")
 	}
-	return nil
+	return verr.Err()
 }
 
 type UpdateSubscriptionRequest struct {
+	ServiceID   *string `json:"service_id"`
 	ServiceName *string `json:"service_name"`
-	Price       *int    `json:"price"`
-	StartDate   *string `json:"start_date"`
-	EndDate     *string `json:"end_date"`
+	// для начавшейся подписки - цена с текущего месяца, записывается в историю цен
+	Price         *int    `json:"price"`
+	Currency      *string `json:"currency"`
+	BillingPeriod *string `json:"billing_period"`
+	StartDate     *string `json:"start_date"`
+	EndDate       *string `json:"end_date"`
+	TrialUntil    *string `json:"trial_until"`
+}
+
+type SchedulePriceChangeRequest struct {
+	// новая цена в минорных единицах валюты подписки
+	Price int `json:"price"`
+	// месяц, с которого действует новая цена, MM-YYYY
+	EffectiveFrom string `json:"effective_from"`
+}
+
+func (r *SchedulePriceChangeRequest) Validate() error {
+	verr := &domain.ValidationError{}
+	if r.Price <= 0 {
+		verr.Add("price", "price must be positive")
+	}
+	if r.EffectiveFrom == "" {
+		verr.Add("effective_from", "effective_from is required")
+	}
+	return verr.Err()
+}
+
+type SubscriptionListResponse struct {
+	Items []domain.Subscription `json:"items"`
+	// курсор следующей страницы; null, если страница последняя
+	NextCursor *string `json:"next_cursor"`
+}
+
+type PurgeResponse struct {
+	// число окончательно удаленных подписок
+	Purged int64 `json:"purged"`
 }
@@ -0,0 +1,21 @@
+package dto
+
+// суммы в ответах - в минорных единицах валюты отчета
+type TotalResponse struct {
+	Total    int    `json:"total"`
+	Currency string `json:"currency"`
+}
+
+type MonthlyTotalResponse struct {
+	Month         string `json:"month"`
+	Amount        int    `json:"amount"`
+	Currency      string `json:"currency"`
+	Subscriptions int    `json:"subscriptions"`
+}
+
+// GroupedTotalResponse в key только поля, перечисленные в group_by
+type GroupedTotalResponse struct {
+	Key      map[string]string `json:"key"`
+	Total    int               `json:"total"`
+	Currency string            `json:"currency"`
+}
@@ -0,0 +1,121 @@
+package dto
+
+import (
+	"encoding/json"
+	"strconv"
+	"testTask/internal/domain"
+	"time"
+)
+
+type CreateWebhookRequest struct {
+	// адрес, на который отправляются события, http или https; только публичный
+	URL string `json:"url"`
+	// ключ подписи HMAC-SHA256, не короче 16 символов; без него генерируется
+	Secret string `json:"secret"`
+	// subscription.created, subscription.updated, subscription.deleted, subscription.renewal_upcoming
+	EventTypes []string `json:"event_types"`
+	// по умолчанию true
+	Active *bool `json:"active"`
+}
+
+// поля, которые не переданы, не меняются; event_types заменяются целиком
+type UpdateWebhookRequest struct {
+	URL        *string   `json:"url"`
+	Secret     *string   `json:"secret"`
+	EventTypes *[]string `json:"event_types"`
+	Active     *bool     `json:"active"`
+}
+
+type WebhookResponse struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// владелец вебхука; null - вебхук администратора для всех подписок арендатора
+	OwnerID    *string   `json:"owner_id"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func NewWebhookResponse(w *domain.Webhook) WebhookResponse {
+	resp := WebhookResponse{
+		ID:         w.ID.String(),
+		URL:        w.URL,
+		EventTypes: make([]string, 0, len(w.EventTypes)),
+		Active:     w.Active,
+		CreatedAt:  w.CreatedAt,
+		UpdatedAt:  w.UpdatedAt,
+	}
+
+	if w.OwnerID != nil {
+		ownerID := w.OwnerID.String()
+		resp.OwnerID = &ownerID
+	}
+
+	for _, t := range w.EventTypes {
+		resp.EventTypes = append(resp.EventTypes, string(t))
+	}
+
+	return resp
+}
+
+type CreateWebhookResponse struct {
+	WebhookResponse
+	// ключ подписи; показывается только один раз
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID        int64  `json:"id"`
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	// тело запроса
+	Payload json.RawMessage `json:"payload" swaggertype:"object"`
+	// pending, delivered или dead
+	Status   string `json:"status"`
+	Attempts int    `json:"attempts"`
+	// время следующей попытки для pending
+	NextAttemptAt *time.Time `json:"next_attempt_at"`
+	// код ответа последней попытки; null - ответ не получен
+	LastStatusCode *int       `json:"last_status_code"`
+	LastError      *string    `json:"last_error"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+}
+
+func NewWebhookDeliveryResponse(d *domain.WebhookDelivery) WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{
+		ID:             d.ID,
+		EventID:        d.EventID.String(),
+		EventType:      string(d.EventType),
+		Payload:        d.Payload,
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+}
+
+type WebhookDeliveryPageResponse struct {
+	Items []WebhookDeliveryResponse `json:"items"`
+	// курсор следующей страницы; null, если страница последняя
+	NextCursor *string `json:"next_cursor"`
+}
+
+func NewWebhookDeliveryPageResponse(page *domain.DeliveryPage) WebhookDeliveryPageResponse {
+	resp := WebhookDeliveryPageResponse{Items: make([]WebhookDeliveryResponse, 0, len(page.Items))}
+
+	for i := range page.Items {
+		resp.Items = append(resp.Items, NewWebhookDeliveryResponse(&page.Items[i]))
+	}
+
+	if page.NextBeforeID != nil {
+		cursor := strconv.FormatInt(*page.NextBeforeID, 10)
+		resp.NextCursor = &cursor
+	}
+
+	return resp
+}
@@ -0,0 +1,124 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testTask/internal/domain"
+	"testTask/internal/dto"
+	"testTask/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type APIKeyHandler struct {
+	service *service.AuthService
+}
+
+func NewAPIKeyHandler(svc *service.AuthService) *APIKeyHandler {
+	return &APIKeyHandler{service: svc}
+}
+
+// RegisterRoutes регистрирует маршруты, доступные только администраторам
+func (h *APIKeyHandler) RegisterRoutes(r chi.Router) {
+	r.With(RequireAdmin).Post("/admin/api-keys", h.Issue)
+	r.With(RequireAdmin).Get("/admin/api-keys", h.List)
+	r.With(RequireAdmin).Delete("/admin/api-keys/{id}", h.Revoke)
+}
+
+// Issue godoc
+// @Summary Выпуск API-ключа
+// @Description Значение ключа возвращается только в этом ответе, в БД хранится его хеш.
+// @Description Ключ передается в заголовке X-API-Key.
+// @Description Администратор, привязанный к арендатору, выпускает ключи только для него
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param key body dto.IssueAPIKeyRequest true "Тело запроса"
+// @Success 201 {object} dto.IssueAPIKeyResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /admin/api-keys [post]
+func (h *APIKeyHandler) Issue(w http.ResponseWriter, r *http.Request) {
+	var req dto.IssueAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidBody(w, r)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	key := &domain.APIKey{
+		Name:      req.Name,
+		Admin:     req.Admin,
+		Gateway:   req.Gateway,
+		TenantID:  req.TenantID,
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	value, err := h.service.IssueAPIKey(r.Context(), key)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, dto.IssueAPIKeyResponse{APIKeyResponse: dto.NewAPIKeyResponse(key), Key: value}, http.StatusCreated)
+}
+
+// List godoc
+// @Summary Список API-ключей
+// @Description Все выпущенные ключи, включая отозванные. Значения ключей не возвращаются.
+// @Description Администратору, привязанному к арендатору, - только ключи этого арендатора
+// @Tags admin
+// @Produce json
+// @Success 200 {object} []dto.APIKeyResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /admin/api-keys [get]
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.ListAPIKeys(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := make([]dto.APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		resp = append(resp, dto.NewAPIKeyResponse(&keys[i]))
+	}
+
+	writeJSON(w, resp, http.StatusOK)
+}
+
+// Revoke godoc
+// @Summary Отзыв API-ключа
+// @Description Ключ перестает приниматься сразу; запись о нем остается в списке
+// @Tags admin
+// @Param id path string true "UUID ключа"
+// @Success 204 {string} string "No Content"
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeInvalidField(w, r, "id", "invalid UUID")
+		return
+	}
+
+	if err := h.service.RevokeAPIKey(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"testTask/internal/domain"
+	"testTask/internal/dto"
+	"testTask/internal/service"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type AuditHandler struct {
+	service *service.SubscriptionService
+}
+
+func NewAuditHandler(svc *service.SubscriptionService) *AuditHandler {
+	return &AuditHandler{service: svc}
+}
+
+func (h *AuditHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/subscriptions/{id}/history", h.History)
+	r.With(RequireAdmin).Get("/admin/audit", h.List)
+}
+
+// собирает фильтр журнала аудита из query-параметров запроса
+func parseAuditFilter(r *http.Request) (domain.AuditFilter, error) {
+	q := r.URL.Query()
+	verr := &domain.ValidationError{}
+
+	filter := domain.AuditFilter{
+		Actor:  q.Get("actor"),
+		Action: domain.AuditAction(q.Get("action")),
+		Limit:  domain.DefaultAuditLimit,
+	}
+
+	if v := q.Get("subscription_id"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			verr.Add("subscription_id", "invalid UUID")
+		}
+		filter.SubscriptionID = &id
+	}
+
+	times := []struct {
+		name string
+		dest **time.Time
+	}{
+		{"from", &filter.From},
+		{"to", &filter.To},
+	}
+	for _, t := range times {
+		if v := q.Get(t.name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				verr.Add(t.name, "invalid time, expected RFC 3339")
+				continue
+			}
+			*t.dest = &parsed
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			verr.Add("limit", "must be an integer")
+		}
+		filter.Limit = limit
+	}
+
+	if v := q.Get("cursor"); v != "" {
+		before, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			verr.Add("cursor", "invalid cursor")
+		}
+		filter.BeforeID = &before
+	}
+
+	if err := verr.Err(); err != nil {
+		return domain.AuditFilter{}, err
+	}
+
+	if err := filter.Validate(); err != nil {
+		return domain.AuditFilter{}, err
+	}
+
+	return filter, nil
+}
+
+// History godoc
+// @Summary История изменений подписки
+// @Description Журнал изменений подписки от новых записей к старым: кто, когда и что изменил,
+// @Description с состоянием до и после изменения. Доступен и для удаленной подписки
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "UUID подписки"
+// @Param action query string false "Действие: create, update, delete, restore, price_change_schedule, price_change_cancel, pause, resume, cancel, purge"
+// @Param limit query int false "Размер страницы, по умолчанию 50, максимум 500"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Success 200 {object} dto.AuditPageResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /subscriptions/{id}/history [get]
+func (h *AuditHandler) History(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeInvalidField(w, r, "id", "invalid UUID")
+		return
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	page, err := h.service.History(r.Context(), id, &filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, dto.NewAuditPageResponse(page), http.StatusOK)
+}
+
+// List godoc
+// @Summary Журнал аудита
+// @Description Изменения всех подписок арендатора от новых записей к старым
+// @Tags admin
+// @Produce json
+// @Param subscription_id query string false "UUID подписки"
+// @Param actor query string false "sub JWT или ID API-ключа"
+// @Param action query string false "Действие: create, update, delete, restore, price_change_schedule, price_change_cancel, pause, resume, cancel, purge"
+// @Param from query string false "Не раньше, RFC 3339"
+// @Param to query string false "Не позже, RFC 3339"
+// @Param limit query int false "Размер страницы, по умолчанию 50, максимум 500"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Success 200 {object} dto.AuditPageResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /admin/audit [get]
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	page, err := h.service.Audit(r.Context(), &filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, dto.NewAuditPageResponse(page), http.StatusOK)
+}
@@ -0,0 +1,148 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testTask/internal/domain"
+	"testTask/internal/dto"
+	"testTask/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ExchangeRateHandler struct {
+	service *service.ExchangeRateService
+}
+
+func NewExchangeRateHandler(svc *service.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{service: svc}
+}
+
+func (h *ExchangeRateHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/exchange-rates", h.List)
+	r.Get("/exchange-rates/{base}/{quote}", h.Get)
+	r.With(RequireAdmin).Put("/exchange-rates/{base}/{quote}", h.Set)
+	r.With(RequireAdmin).Delete("/exchange-rates/{base}/{quote}", h.Delete)
+}
+
+// коды валют из пути запроса в верхнем регистре
+func currencyPair(r *http.Request) (string, string) {
+	return strings.ToUpper(chi.URLParam(r, "base")), strings.ToUpper(chi.URLParam(r, "quote"))
+}
+
+// List godoc
+// @Summary Список курсов валют
+// @Description Все курсы, используемые для пересчета стоимости подписок в валюту отчета
+// @Tags exchange-rates
+// @Produce json
+// @Success 200 {object} []domain.ExchangeRate
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /exchange-rates [get]
+func (h *ExchangeRateHandler) List(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.service.List(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, rates, http.StatusOK)
+}
+
+// Get godoc
+// @Summary Курс валюты
+// @Description Курс пары валют: сколько единиц quote стоит 1 единица base
+// @Tags exchange-rates
+// @Produce json
+// @Param base path string true "Код базовой валюты ISO 4217"
+// @Param quote path string true "Код котируемой валюты ISO 4217"
+// @Success 200 {object} domain.ExchangeRate
+// @Failure 401 {object} Problem
+// @Failure 404 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /exchange-rates/{base}/{quote} [get]
+func (h *ExchangeRateHandler) Get(w http.ResponseWriter, r *http.Request) {
+	base, quote := currencyPair(r)
+
+	rate, err := h.service.Get(r.Context(), base, quote)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, rate, http.StatusOK)
+}
+
+// Set godoc
+// @Summary Установка курса валюты
+// @Description Создать или обновить курс пары валют
+// @Description Курсы общие для всех арендаторов: доступно только администратору без привязки к арендатору
+// @Tags exchange-rates
+// @Accept json
+// @Produce json
+// @Param base path string true "Код базовой валюты ISO 4217"
+// @Param quote path string true "Код котируемой валюты ISO 4217"
+// @Param rate body dto.SetExchangeRateRequest true "Тело запроса"
+// @Success 200 {object} domain.ExchangeRate
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /exchange-rates/{base}/{quote} [put]
+func (h *ExchangeRateHandler) Set(w http.ResponseWriter, r *http.Request) {
+	var req dto.SetExchangeRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidBody(w, r)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	base, quote := currencyPair(r)
+	rate := &domain.ExchangeRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          req.Rate,
+	}
+
+	if err := rate.Validate(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := h.service.Set(r.Context(), rate); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, rate, http.StatusOK)
+}
+
+// Delete godoc
+// @Summary Удаление курса валюты
+// @Description Доступно только администратору без привязки к арендатору
+// @Tags exchange-rates
+// @Param base path string true "Код базовой валюты ISO 4217"
+// @Param quote path string true "Код котируемой валюты ISO 4217"
+// @Success 204 {string} string "No Content"
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /exchange-rates/{base}/{quote} [delete]
+func (h *ExchangeRateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	base, quote := currencyPair(r)
+
+	if err := h.service.Delete(r.Context(), base, quote); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,11 +1,41 @@
 package http
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
+	"strings"
+	"testTask/internal/domain"
+	"testTask/internal/service"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+const RequestIDHeader = "X-Request-ID"
+
+// максимальная длина request id, принимаемого от клиента
+const maxRequestIDLength = 128
+
+// RequestIDFromContext возвращает id запроса, выданный RequestIDMiddleware
+func RequestIDFromContext(ctx context.Context) string {
+	return domain.RequestIDFromContext(ctx)
+}
+
+// RequestIDMiddleware берет id запроса из заголовка X-Request-ID или генерирует новый
+// и возвращает его в ответе, чтобы ошибку клиента можно было найти в логах
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(domain.WithRequestID(r.Context(), id)))
+	})
+}
+
 type responseWriter struct {
 	http.ResponseWriter
 	status int
@@ -24,6 +54,7 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(rw, r)
 
 		slog.Info("http request",
+			"request_id", RequestIDFromContext(r.Context()),
 			"method", r.Method,
 			"path", r.URL.Path,
 			"status", rw.status,
@@ -31,3 +62,68 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		)
 	})
 }
+
+const (
+	APIKeyHeader = "X-API-Key"
+	TenantHeader = "X-Tenant-ID"
+)
+
+// AuthMiddleware пропускает только запросы с валидным JWT (Authorization: Bearer)
+// или API-ключом (X-API-Key) и кладет клиента в контекст запроса.
+// Если задан userHeader, запросы с API-ключом выполняются от имени пользователя
+// из этого заголовка (ключ принадлежит доверенному шлюзу).
+// Арендатор запроса определяется учетными данными или заголовком X-Tenant-ID
+func AuthMiddleware(svc *service.AuthService, userHeader string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				principal *domain.Principal
+				err       = domain.ErrUnauthorized
+			)
+
+			if key := r.Header.Get(APIKeyHeader); key != "" {
+				principal, err = svc.AuthenticateAPIKey(r.Context(), key)
+			} else if token, ok := bearerToken(r); ok {
+				principal, err = svc.AuthenticateToken(token)
+			}
+
+			if err == nil && userHeader != "" {
+				if userID := r.Header.Get(userHeader); userID != "" {
+					err = svc.ActAsUser(principal, userID)
+				}
+			}
+
+			if err == nil {
+				err = svc.ResolveTenant(principal, r.Header.Get(TenantHeader))
+			}
+
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(domain.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// RequireAdmin пропускает только администраторов; ставится после AuthMiddleware
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := domain.PrincipalFromContext(r.Context()); p == nil || !p.Admin {
+			writeError(w, r, domain.ErrForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testTask/internal/domain"
+)
+
+// Коды ошибок API. Клиенты ориентируются на code, а не на текст detail,
+// поэтому значения кодов менять нельзя
+const (
+	CodeInvalidBody          = "invalid_body"
+	CodeValidationFailed     = "validation_failed"
+	CodeNotFound             = "not_found"
+	CodeSubscriptionNotFound = "subscription_not_found"
+	CodeServiceNotFound      = "service_not_found"
+	CodePriceChangeNotFound  = "price_change_not_found"
+	CodeAPIKeyNotFound       = "api_key_not_found"
+	CodeExchangeRateNotFound = "exchange_rate_not_found"
+	CodeWebhookNotFound      = "webhook_not_found"
+	CodeDeliveryNotFound     = "webhook_delivery_not_found"
+	CodeConflict             = "conflict"
+	CodeServiceNameTaken     = "service_name_taken"
+	CodeServiceInUse         = "service_in_use"
+	CodeNotDeleted           = "subscription_not_deleted"
+	CodeDeliveryNotDead      = "webhook_delivery_not_dead"
+	CodeInvalidTransition    = "invalid_status_transition"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeRouteNotFound        = "route_not_found"
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodeInternal             = "internal_error"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem тело ответа с ошибкой по RFC 7807
+type Problem struct {
+	Type     string `json:"type" example:"about:blank"`
+	Title    string `json:"title" example:"Bad Request"`
+	Status   int    `json:"status" example:"400"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty" example:"/subscriptions"`
+	// стабильный машиночитаемый код ошибки
+	Code      string `json:"code" example:"validation_failed"`
+	RequestID string `json:"request_id,omitempty"`
+	// ошибки по отдельным полям запроса
+	Errors []ProblemField `json:"errors,omitempty"`
+} // @name Problem
+
+type ProblemField struct {
+	Field   string `json:"field" example:"start_date"`
+	Message string `json:"message" example:"invalid date, expected MM-YYYY"`
+} // @name ProblemField
+
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code, detail string, fields []domain.FieldError) {
+	p := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: RequestIDFromContext(r.Context()),
+	}
+
+	for _, f := range fields {
+		p.Errors = append(p.Errors, ProblemField{Field: f.Field, Message: f.Message})
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		slog.Error("failed to encode problem", "request_id", p.RequestID, "error", err)
+	}
+}
+
+// тело запроса не удалось разобрать как JSON
+func writeInvalidBody(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, http.StatusBadRequest, CodeInvalidBody, "request body is not valid JSON", nil)
+}
+
+func writeInvalidField(w http.ResponseWriter, r *http.Request, field, message string) {
+	writeError(w, r, domain.NewValidationError(field, message))
+}
+
+func writeNotFound(w http.ResponseWriter, r *http.Request, code, detail string) {
+	writeProblem(w, r, http.StatusNotFound, code, detail, nil)
+}
+
+// коды конкретных ошибок; для остальных ошибок категории берется общий код
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{domain.ErrSubscriptionNotFound, CodeSubscriptionNotFound},
+	{domain.ErrServiceNotFound, CodeServiceNotFound},
+	{domain.ErrPriceChangeNotFound, CodePriceChangeNotFound},
+	{domain.ErrExchangeRateNotFound, CodeExchangeRateNotFound},
+	{domain.ErrAPIKeyNotFound, CodeAPIKeyNotFound},
+	{domain.ErrNoExchangeRate, CodeExchangeRateNotFound},
+	{domain.ErrServiceNameTaken, CodeServiceNameTaken},
+	{domain.ErrServiceInUse, CodeServiceInUse},
+	{domain.ErrSubscriptionNotDeleted, CodeNotDeleted},
+	{domain.ErrWebhookNotFound, CodeWebhookNotFound},
+	{domain.ErrWebhookDeliveryNotFound, CodeDeliveryNotFound},
+	{domain.ErrWebhookDeliveryNotDead, CodeDeliveryNotDead},
+	{domain.ErrInvalidStatusTransition, CodeInvalidTransition},
+}
+
+func errorCode(err error, fallback string) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+
+	return fallback
+}
+
+// writeError выбирает статус и код по категории ошибки: ErrUnauthorized - 401,
+// ErrForbidden - 403, ErrValidation - 422,
+// ErrNotFound - 404, ErrConflict - 409. Остальные ошибки пишутся в лог,
+// клиент получает только request_id
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var verr *domain.ValidationError
+
+	switch {
+	case errors.Is(err, domain.ErrUnauthorized):
+		// причину отказа клиенту не сообщаем, только в лог
+		slog.Info("authentication failed", "request_id", RequestIDFromContext(r.Context()), "error", err)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="subscriptions"`)
+		writeProblem(w, r, http.StatusUnauthorized, CodeUnauthorized, domain.ErrUnauthorized.Error(), nil)
+	case errors.Is(err, domain.ErrForbidden):
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error(), nil)
+	case errors.As(err, &verr):
+		writeProblem(w, r, http.StatusUnprocessableEntity, CodeValidationFailed, "request contains invalid fields", verr.Fields)
+	case errors.Is(err, domain.ErrValidation):
+		writeProblem(w, r, http.StatusUnprocessableEntity, errorCode(err, CodeValidationFailed), err.Error(), nil)
+	case errors.Is(err, domain.ErrNotFound):
+		writeNotFound(w, r, errorCode(err, CodeNotFound), err.Error())
+	case errors.Is(err, domain.ErrConflict):
+		writeProblem(w, r, http.StatusConflict, errorCode(err, CodeConflict), err.Error(), nil)
+	default:
+		slog.Error("request failed",
+			"request_id", RequestIDFromContext(r.Context()),
+			"method", r.Method,
+			"path", r.URL.Path,
+			"error", err,
+		)
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "internal server error", nil)
+	}
+}
+
+// NotFoundHandler ответ на запрос к несуществующему маршруту
+func NotFoundHandler(w http.ResponseWriter, r *http.Request) {
+	writeNotFound(w, r, CodeRouteNotFound, "route not found")
+}
+
+// MethodNotAllowedHandler ответ на запрос с неподдерживаемым методом
+func MethodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "method not allowed", nil)
+}
@@ -0,0 +1,241 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testTask/internal/domain"
+	"testTask/internal/dto"
+	"testTask/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ServiceHandler struct {
+	service *service.CatalogService
+}
+
+func NewServiceHandler(svc *service.CatalogService) *ServiceHandler {
+	return &ServiceHandler{service: svc}
+}
+
+func (h *ServiceHandler) RegisterRoutes(r chi.Router) {
+	r.With(RequireAdmin).Post("/services", h.Create)
+	r.Get("/services", h.List)
+	r.Get("/services/{id}", h.Get)
+	r.With(RequireAdmin).Patch("/services/{id}", h.Update)
+	r.With(RequireAdmin).Delete("/services/{id}", h.Delete)
+}
+
+// Create godoc
+// @Summary Добавление сервиса в каталог
+// @Description Название и синонимы должны быть уникальны без учета регистра
+// @Description Каталог общий для всех арендаторов: доступно только администратору без привязки к арендатору
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param service body dto.CreateServiceRequest true "Тело запроса"
+// @Success 201 {object} domain.Service
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /services [post]
+func (h *ServiceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateServiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidBody(w, r)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	currency := domain.DefaultCurrency
+	if req.Currency != "" {
+		currency = req.Currency
+	}
+
+	svc := &domain.Service{
+		Name:         req.Name,
+		Category:     req.Category,
+		DefaultPrice: req.DefaultPrice,
+		Currency:     currency,
+		Website:      req.Website,
+		Aliases:      req.Aliases,
+	}
+
+	if err := svc.Validate(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := h.service.Create(r.Context(), svc); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, svc, http.StatusCreated)
+}
+
+// List godoc
+// @Summary Каталог сервисов
+// @Tags services
+// @Produce json
+// @Param category query string false "Категория"
+// @Success 200 {object} []domain.Service
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /services [get]
+func (h *ServiceHandler) List(w http.ResponseWriter, r *http.Request) {
+	var category *string
+	if c := r.URL.Query().Get("category"); c != "" {
+		category = &c
+	}
+
+	services, err := h.service.List(r.Context(), category)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if services == nil {
+		services = []domain.Service{}
+	}
+
+	writeJSON(w, services, http.StatusOK)
+}
+
+// Get godoc
+// @Summary Сервис из каталога
+// @Tags services
+// @Produce json
+// @Param id path string true "UUID сервиса"
+// @Success 200 {object} domain.Service
+// @Failure 401 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /services/{id} [get]
+func (h *ServiceHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeInvalidField(w, r, "id", "invalid UUID")
+		return
+	}
+
+	svc, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, svc, http.StatusOK)
+}
+
+// Update godoc
+// @Summary Изменение сервиса в каталоге
+// @Description Переименование сервиса сразу отражается во всех его подписках
+// @Description Доступно только администратору без привязки к арендатору
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param id path string true "UUID сервиса"
+// @Param service body dto.UpdateServiceRequest true "Тело запроса"
+// @Success 200 {object} domain.Service
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /services/{id} [patch]
+func (h *ServiceHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeInvalidField(w, r, "id", "invalid UUID")
+		return
+	}
+
+	var req dto.UpdateServiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidBody(w, r)
+		return
+	}
+
+	svc, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if req.Name != nil {
+		svc.Name = *req.Name
+	}
+
+	if req.Category != nil {
+		svc.Category = *req.Category
+	}
+
+	if req.DefaultPrice != nil {
+		svc.DefaultPrice = req.DefaultPrice
+	}
+
+	if req.Currency != nil {
+		svc.Currency = *req.Currency
+	}
+
+	if req.Website != nil {
+		svc.Website = *req.Website
+	}
+
+	if req.Aliases != nil {
+		svc.Aliases = *req.Aliases
+	}
+
+	if err := svc.Validate(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := h.service.Update(r.Context(), svc); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, svc, http.StatusOK)
+}
+
+// Delete godoc
+// @Summary Удаление сервиса из каталога
+// @Description Сервис, на который есть подписки, удалить нельзя
+// @Description Доступно только администратору без привязки к арендатору
+// @Tags services
+// @Param id path string true "UUID сервиса"
+// @Success 204 {string} string "No Content"
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /services/{id} [delete]
+func (h *ServiceHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeInvalidField(w, r, "id", "invalid UUID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,8 +1,14 @@
 package http
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 	"testTask/internal/domain"
 	"testTask/internal/dto"
 	"testTask/internal/service"
@@ -14,6 +20,8 @@ import (
 
 const DateFormatFromRequest = "01-2006"
 
+const invalidMonthMessage = "invalid date, expected MM-YYYY"
+
 // парсит дату в формате "MM-YYYY"
 func parseMonthYear(dateStr string) (time.Time, error) {
 	return time.Parse(DateFormatFromRequest, dateStr)
@@ -36,12 +44,225 @@ func parseUUID(idStr string) (uuid.UUID, error) {
 	return uuid.Parse(idStr)
 }
 
+// собирает фильтр подсчета стоимости из query-параметров запроса
+func parseTotalFilter(r *http.Request) (domain.TotalFilter, error) {
+	q := r.URL.Query()
+	verr := &domain.ValidationError{}
+
+	filter := domain.TotalFilter{
+		Currency: q.Get("currency"),
+	}
+	if filter.Currency == "" {
+		filter.Currency = domain.DefaultCurrency
+	}
+
+	// пустые from и to отклоняет filter.Validate
+	if v := q.Get("from"); v != "" {
+		from, err := parseMonthYear(v)
+		if err != nil {
+			verr.Add("from", invalidMonthMessage)
+		}
+		filter.From = from
+	}
+
+	if v := q.Get("to"); v != "" {
+		to, err := parseMonthYear(v)
+		if err != nil {
+			verr.Add("to", invalidMonthMessage)
+		}
+		filter.To = to
+	}
+
+	if v := q.Get("user_id"); v != "" {
+		uid, err := parseUUID(v)
+		if err != nil {
+			verr.Add("user_id", "invalid UUID")
+		}
+		filter.UserID = &uid
+	}
+
+	if v := q.Get("service_name"); v != "" {
+		filter.ServiceName = &v
+	}
+
+	// group_by можно передать списком через запятую или несколькими параметрами
+	for _, v := range q["group_by"] {
+		for _, g := range strings.Split(v, ",") {
+			if g = strings.TrimSpace(g); g != "" {
+				filter.GroupBy = append(filter.GroupBy, domain.TotalGroupBy(g))
+			}
+		}
+	}
+
+	if err := verr.Err(); err != nil {
+		return domain.TotalFilter{}, err
+	}
+
+	if err := filter.Validate(); err != nil {
+		return domain.TotalFilter{}, err
+	}
+
+	return filter, nil
+}
+
+// собирает фильтр списка подписок из query-параметров запроса
+func parseListFilter(r *http.Request) (domain.ListFilter, error) {
+	q := r.URL.Query()
+	verr := &domain.ValidationError{}
+
+	filter := domain.ListFilter{
+		Sort:  domain.SortByCreatedAt,
+		Limit: domain.DefaultListLimit,
+	}
+
+	if v := q.Get("user_id"); v != "" {
+		uid, err := parseUUID(v)
+		if err != nil {
+			verr.Add("user_id", "invalid UUID")
+		}
+		filter.UserID = &uid
+	}
+
+	if v := q.Get("service_name"); v != "" {
+		filter.ServiceName = &v
+	}
+
+	filter.Search = strings.TrimSpace(q.Get("q"))
+
+	months := []struct {
+		name string
+		dest **time.Time
+	}{
+		{"active_on", &filter.ActiveOn},
+		{"start_from", &filter.StartFrom},
+		{"start_to", &filter.StartTo},
+		{"end_from", &filter.EndFrom},
+		{"end_to", &filter.EndTo},
+	}
+	for _, m := range months {
+		if v := q.Get(m.name); v != "" {
+			t, err := parseMonthYear(v)
+			if err != nil {
+				verr.Add(m.name, invalidMonthMessage)
+				continue
+			}
+			*m.dest = &t
+		}
+	}
+
+	prices := []struct {
+		name string
+		dest **int
+	}{
+		{"price_min", &filter.PriceMin},
+		{"price_max", &filter.PriceMax},
+	}
+	for _, p := range prices {
+		if v := q.Get(p.name); v != "" {
+			price, err := strconv.Atoi(v)
+			if err != nil {
+				verr.Add(p.name, "must be an integer")
+				continue
+			}
+			*p.dest = &price
+		}
+	}
+
+	if v := q.Get("open_ended"); v != "" {
+		openEnded, err := strconv.ParseBool(v)
+		if err != nil {
+			verr.Add("open_ended", "must be true or false")
+		}
+		filter.OpenEnded = &openEnded
+	}
+
+	if v := q.Get("include_deleted"); v != "" {
+		includeDeleted, err := strconv.ParseBool(v)
+		if err != nil {
+			verr.Add("include_deleted", "must be true or false")
+		}
+		filter.IncludeDeleted = includeDeleted
+	}
+
+	if v := q.Get("sort"); v != "" {
+		filter.Sort = domain.ListSort(v)
+	}
+
+	switch q.Get("order") {
+	case "", "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		verr.Add("order", "order must be asc or desc")
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			verr.Add("limit", "must be an integer")
+		}
+		filter.Limit = limit
+	}
+
+	if v := q.Get("cursor"); v != "" {
+		cursor, err := decodeCursor(v)
+		if err != nil {
+			verr.Add("cursor", "invalid cursor")
+		}
+		filter.Cursor = cursor
+	}
+
+	if err := verr.Err(); err != nil {
+		return domain.ListFilter{}, err
+	}
+
+	if err := filter.Validate(); err != nil {
+		return domain.ListFilter{}, err
+	}
+
+	return filter, nil
+}
+
+// курсор передается клиенту непрозрачной строкой: base64 от JSON
+type cursorToken struct {
+	Sort  domain.ListSort `json:"s"`
+	Desc  bool            `json:"d,omitempty"`
+	Value string          `json:"v"`
+	ID    uuid.UUID       `json:"id"`
+}
+
+func encodeCursor(c *domain.ListCursor) string {
+	data, _ := json.Marshal(cursorToken{Sort: c.Sort, Desc: c.Desc, Value: c.Value, ID: c.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*domain.ListCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var t cursorToken
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+
+	cursor := &domain.ListCursor{Sort: t.Sort, Desc: t.Desc, Value: t.Value, ID: t.ID}
+	// значение из подделанного курсора не должно дойти до запроса к базе
+	if !cursor.ValidValue() {
+		return nil, errors.New("cursor value does not match sort")
+	}
+
+	return cursor, nil
+}
+
 // безопасная запись JSON с обработкой ошибки
 func writeJSON(w http.ResponseWriter, data any, status int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
+	// заголовок уже отправлен, поэтому ошибку можно только залогировать
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		slog.Error("failed to encode response", "error", err)
 	}
 }
 
@@ -55,143 +276,225 @@ func NewHandler(svc *service.SubscriptionService) *SubscriptionHandler {
 
 func (h *SubscriptionHandler) RegisterRoutes(r chi.Router) {
 	r.Post("/subscriptions", h.Create)
+	r.Post("/subscriptions/import", h.Import)
 	r.Get("/subscriptions/{id}", h.Get)
 	r.Patch("/subscriptions/{id}", h.Update)
 	r.Delete("/subscriptions/{id}", h.Delete)
+	r.Post("/subscriptions/{id}/restore", h.Restore)
+	r.Post("/subscriptions/{id}/pause", h.Pause)
+	r.Post("/subscriptions/{id}/resume", h.Resume)
+	r.Post("/subscriptions/{id}/cancel", h.Cancel)
+	r.With(RequireAdmin).Post("/admin/subscriptions/purge", h.Purge)
 	r.Get("/subscriptions/list", h.List)
 	r.Get("/subscriptions/total", h.Total)
+	r.Get("/subscriptions/total/breakdown", h.TotalBreakdown)
+	r.Post("/subscriptions/{id}/prices", h.SchedulePriceChange)
+	r.Get("/subscriptions/{id}/prices", h.ListPriceChanges)
+	r.Delete("/subscriptions/{id}/prices/{effective_from}", h.CancelPriceChange)
 }
 
 // Create godoc
 // @Summary Запись новой подписки
-// @Description Создание записи о новой подписке пользователя
+// @Description Создание записи о новой подписке пользователя. Сервис задается service_id
+// @Description или названием/синонимом из каталога /services.
+// @Description Для пользователя user_id подставляется автоматически, указать чужой нельзя
 // @Tags subscriptions
 // @Accept json
 // @Produce json
 // @Param subscription body dto.CreateSubscriptionRequest true "Тело запроса"
 // @Success 201 {object} domain.Subscription
-// @Failure 400 {string} string
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
 // @Router /subscriptions [post]
 func (h *SubscriptionHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateSubscriptionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request", http.StatusBadRequest)
+		writeInvalidBody(w, r)
 		return
 	}
 
-	if err := req.Validate(); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	sub, err := subscriptionFromRequest(&req)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	start, err := time.Parse(DateFormatFromRequest, req.StartDate)
-	if err != nil {
-		http.Error(w, "invalid start_date format", http.StatusBadRequest)
+	if err := h.service.Create(r.Context(), sub); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	var end *time.Time
-	if req.EndDate != nil {
-		t, err := time.Parse(DateFormatFromRequest, *req.EndDate)
-		if err != nil {
-			http.Error(w, "invalid end_date format", http.StatusBadRequest)
-			return
-		}
-		end = &t
+	writeJSON(w, sub, http.StatusCreated)
+}
+
+// subscriptionFromRequest проверяет запрос на создание подписки и разбирает его поля
+func subscriptionFromRequest(req *dto.CreateSubscriptionRequest) (*domain.Subscription, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
 	}
 
-	userUuid, err := uuid.Parse(req.UserID)
+	verr := &domain.ValidationError{}
+
+	start, err := parseMonthYear(req.StartDate)
 	if err != nil {
-		http.Error(w, "invalid user_id", http.StatusBadRequest)
-		return
+		verr.Add("start_date", invalidMonthMessage)
 	}
 
-	sub := &domain.Subscription{
-		ServiceName: req.ServiceName,
-		Price:       req.Price,
-		UserID:      userUuid,
-		StartDate:   start,
-		EndDate:     end,
+	end, err := parseMonthYearPtr(req.EndDate)
+	if err != nil {
+		verr.Add("end_date", invalidMonthMessage)
 	}
 
-	if err := h.service.Create(r.Context(), sub); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	trialUntil, err := parseMonthYearPtr(req.TrialUntil)
+	if err != nil {
+		verr.Add("trial_until", invalidMonthMessage)
 	}
 
-	writeJSON(w, sub, http.StatusCreated)
+	var userUuid uuid.UUID
+	if req.UserID != "" {
+		if userUuid, err = parseUUID(req.UserID); err != nil {
+			verr.Add("user_id", "invalid UUID")
+		}
+	}
+
+	var serviceID uuid.UUID
+	if req.ServiceID != "" {
+		if serviceID, err = parseUUID(req.ServiceID); err != nil {
+			verr.Add("service_id", "invalid UUID")
+		}
+	}
+
+	if err := verr.Err(); err != nil {
+		return nil, err
+	}
+
+	billingPeriod := domain.BillingPeriodMonth
+	if req.BillingPeriod != "" {
+		billingPeriod = domain.BillingPeriod(req.BillingPeriod)
+	}
+
+	return &domain.Subscription{
+		ServiceID:     serviceID,
+		ServiceName:   req.ServiceName,
+		Price:         req.Price,
+		Currency:      req.Currency,
+		BillingPeriod: billingPeriod,
+		UserID:        userUuid,
+		StartDate:     start,
+		EndDate:       end,
+		TrialUntil:    trialUntil,
+	}, nil
 }
 
 // Total godoc
 // @Summary Подсчет суммарной стоимости всех подписок
-// @Description Подсчет суммарной стоимости всех подписок с фильтрами по user и service
+// @Description Подсчет суммарной стоимости всех подписок с фильтрами по user и service.
+// @Description Цена подписки учитывается за каждое списание (по её billing_period), попадающее в период.
+// @Description Списания в пробный период, во время паузы и после отмены не учитываются.
 // @Tags subscriptions
 // @Produce json
 // @Param user_id query string false "UUID пользователя"
 // @Param service_name query string false "Наименование сервиса"
 // @Param from query string true "Начало периода. Формат MM-YYYY"
-// @Param to query string true "Окончание периода. Формат MM-YYYY"
-// @Success 200 {object} map[string]int
-// @Failure 400 {string} string
-// @Failure 500 {string} string
+// @Param to query string true "Окончание периода. Формат MM-YYYY, период не длиннее 120 месяцев"
+// @Param group_by query string false "Группировка: service_name, user_id или оба через запятую. Если задана, возвращается список групп"
+// @Param currency query string false "Валюта отчета ISO 4217, по умолчанию RUB"
+// @Success 200 {object} dto.TotalResponse "при заданном group_by - []dto.GroupedTotalResponse"
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
 // @Router /subscriptions/total [get]
 func (h *SubscriptionHandler) Total(w http.ResponseWriter, r *http.Request) {
-	userIDStr := r.URL.Query().Get("user_id")
-	serviceNameStr := r.URL.Query().Get("service_name")
-	fromStr := r.URL.Query().Get("from")
-	toStr := r.URL.Query().Get("to")
+	filter, err := parseTotalFilter(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
 
-	if fromStr == "" || toStr == "" {
-		http.Error(w, "dates 'from' and 'to' are required", http.StatusBadRequest)
+	if len(filter.GroupBy) > 0 {
+		h.groupedTotal(w, r, &filter)
 		return
 	}
 
-	from, err := parseMonthYear(fromStr)
+	total, err := h.service.CalculateTotal(r.Context(), &filter)
 	if err != nil {
-		http.Error(w, "invalid date 'from'", http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
-	to, err := parseMonthYear(toStr)
+	writeJSON(w, dto.TotalResponse{Total: total, Currency: filter.Currency}, http.StatusOK)
+}
+
+func (h *SubscriptionHandler) groupedTotal(w http.ResponseWriter, r *http.Request, filter *domain.TotalFilter) {
+	groups, err := h.service.CalculateGroupedTotal(r.Context(), filter)
 	if err != nil {
-		http.Error(w, "invalid date 'to'", http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
-	var userID *uuid.UUID
-	if userIDStr != "" {
-		uid, err := uuid.Parse(userIDStr)
-		if err != nil {
-			http.Error(w, "invalid user_id", http.StatusBadRequest)
-			return
+	resp := make([]dto.GroupedTotalResponse, 0, len(groups))
+	for _, g := range groups {
+		key := make(map[string]string, len(filter.GroupBy))
+		if g.ServiceName != nil {
+			key[string(domain.GroupByServiceName)] = *g.ServiceName
+		}
+		if g.UserID != nil {
+			key[string(domain.GroupByUserID)] = g.UserID.String()
 		}
-		userID = &uid
-	}
 
-	var serviceName *string
-	if serviceNameStr != "" {
-		serviceName = &serviceNameStr
+		resp = append(resp, dto.GroupedTotalResponse{Key: key, Total: g.Total, Currency: g.Currency})
 	}
 
-	filter := domain.TotalFilter{
-		UserID:      userID,
-		ServiceName: serviceName,
-		From:        from,
-		To:          to,
-	}
+	writeJSON(w, resp, http.StatusOK)
+}
 
-	if err = filter.Validate(); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+// TotalBreakdown godoc
+// @Summary Помесячная разбивка стоимости подписок
+// @Description Сумма списаний и количество активных подписок за каждый календарный месяц периода.
+// @Description Фильтры те же, что и у /subscriptions/total
+// @Tags subscriptions
+// @Produce json
+// @Param user_id query string false "UUID пользователя"
+// @Param service_name query string false "Наименование сервиса"
+// @Param from query string true "Начало периода. Формат MM-YYYY"
+// @Param to query string true "Окончание периода. Формат MM-YYYY, период не длиннее 120 месяцев"
+// @Param currency query string false "Валюта отчета ISO 4217, по умолчанию RUB"
+// @Success 200 {object} []dto.MonthlyTotalResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /subscriptions/total/breakdown [get]
+func (h *SubscriptionHandler) TotalBreakdown(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTotalFilter(r)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	total, err := h.service.CalculateTotal(r.Context(), &filter)
+	breakdown, err := h.service.CalculateMonthlyBreakdown(r.Context(), &filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	writeJSON(w, map[string]int{"total": total}, http.StatusOK)
+	resp := make([]dto.MonthlyTotalResponse, 0, len(breakdown))
+	for _, mt := range breakdown {
+		resp = append(resp, dto.MonthlyTotalResponse{
+			Month:         mt.Month.Format(DateFormatFromRequest),
+			Amount:        mt.Amount,
+			Currency:      mt.Currency,
+			Subscriptions: mt.Subscriptions,
+		})
+	}
+
+	writeJSON(w, resp, http.StatusOK)
 }
 
 // Get godoc
@@ -201,23 +504,23 @@ func (h *SubscriptionHandler) Total(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path string true "UUID подписки"
 // @Success 200 {object} domain.Subscription
-// @Failure 400 {string} string
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
 // @Router /subscriptions/{id} [get]
 func (h *SubscriptionHandler) Get(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := parseUUID(idStr)
 	if err != nil {
-		http.Error(w, "invalid UUID", http.StatusBadRequest)
+		writeInvalidField(w, r, "id", "invalid UUID")
 		return
 	}
 
 	sub, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	if sub == nil {
-		http.Error(w, "subscription not found", http.StatusNotFound)
+		writeError(w, r, err)
 		return
 	}
 
@@ -226,43 +529,46 @@ func (h *SubscriptionHandler) Get(w http.ResponseWriter, r *http.Request) {
 
 // Update godoc
 // @Summary Изменение записи подписки
-// @Description Внесение изменений в подписку
+// @Description Внесение изменений в подписку. Новая price уже начавшейся подписки действует с текущего месяца
+// @Description и добавляется в историю цен, price в подписке остается начальной ценой; прошлые месяцы не пересчитываются.
+// @Description Для изменения цены с другого месяца используйте /subscriptions/{id}/prices. Currency уже начавшейся подписки не меняется
 // @Tags subscriptions
 // @Accept json
 // @Produce json
 // @Param id path string true "UUID подписки"
 // @Param subscription body dto.UpdateSubscriptionRequest true "Тело запроса"
 // @Success 200 {object} domain.Subscription
-// @Failure 400 {string} string
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
 // @Router /subscriptions/{id} [patch]
 func (h *SubscriptionHandler) Update(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := parseUUID(idStr)
 	if err != nil {
-		http.Error(w, "invalid UUID", http.StatusBadRequest)
+		writeInvalidField(w, r, "id", "invalid UUID")
 		return
 	}
 
 	var req dto.UpdateSubscriptionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		writeInvalidBody(w, r)
 		return
 	}
 
 	sub, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	if sub == nil {
-		http.Error(w, "not found", http.StatusNotFound)
+		writeError(w, r, err)
 		return
 	}
 
 	if req.StartDate != nil {
 		start, err := parseMonthYear(*req.StartDate)
 		if err != nil {
-			http.Error(w, "invalid start_date format", http.StatusBadRequest)
+			writeInvalidField(w, r, "start_date", invalidMonthMessage)
 			return
 		}
 		sub.StartDate = start
@@ -271,14 +577,33 @@ func (h *SubscriptionHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.EndDate != nil {
 		end, err := parseMonthYearPtr(req.EndDate)
 		if err != nil {
-			http.Error(w, "invalid end_date format", http.StatusBadRequest)
+			writeInvalidField(w, r, "end_date", invalidMonthMessage)
 			return
 		}
 
 		sub.EndDate = end
 	}
 
-	if req.ServiceName != nil {
+	if req.TrialUntil != nil {
+		trialUntil, err := parseMonthYearPtr(req.TrialUntil)
+		if err != nil {
+			writeInvalidField(w, r, "trial_until", invalidMonthMessage)
+			return
+		}
+
+		sub.TrialUntil = trialUntil
+	}
+
+	// сервис заново ищется в каталоге по новому ID или названию
+	if req.ServiceID != nil {
+		serviceID, err := uuid.Parse(*req.ServiceID)
+		if err != nil {
+			writeInvalidField(w, r, "service_id", "invalid UUID")
+			return
+		}
+		sub.ServiceID = serviceID
+	} else if req.ServiceName != nil {
+		sub.ServiceID = uuid.Nil
 		sub.ServiceName = *req.ServiceName
 	}
 
@@ -286,8 +611,16 @@ func (h *SubscriptionHandler) Update(w http.ResponseWriter, r *http.Request) {
 		sub.Price = *req.Price
 	}
 
+	if req.Currency != nil {
+		sub.Currency = *req.Currency
+	}
+
+	if req.BillingPeriod != nil {
+		sub.BillingPeriod = domain.BillingPeriod(*req.BillingPeriod)
+	}
+
 	if err := h.service.Update(r.Context(), sub); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -296,64 +629,334 @@ func (h *SubscriptionHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 // Delete godoc
 // @Summary Удаление записи о подписке
-// @Description Удалить запись о подписке по её ID
+// @Description Пометить подписку удаленной. Она пропадает из списков и подсчетов, но хранится
+// @Description в течение срока SOFT_DELETE_RETENTION и может быть восстановлена через /subscriptions/{id}/restore
 // @Tags subscriptions
 // @Param id path string true "UUID подписки"
 // @Success 204 {string} string "No Content"
-// @Failure 400 {string} string
-// @Failure 500 {string} string
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
 // @Router /subscriptions/{id} [delete]
 func (h *SubscriptionHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := parseUUID(idStr)
 	if err != nil {
-		http.Error(w, "invalid UUID", http.StatusBadRequest)
+		writeInvalidField(w, r, "id", "invalid UUID")
 		return
 	}
 
 	if err := h.service.Delete(r.Context(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Restore godoc
+// @Summary Восстановление удаленной подписки
+// @Description Вернуть подписку, удаленную не раньше срока хранения SOFT_DELETE_RETENTION
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "UUID подписки"
+// @Success 200 {object} domain.Subscription
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /subscriptions/{id}/restore [post]
+func (h *SubscriptionHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeInvalidField(w, r, "id", "invalid UUID")
+		return
+	}
+
+	sub, err := h.service.Restore(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, sub, http.StatusOK)
+}
+
+// Pause godoc
+// @Summary Приостановка подписки
+// @Description Приостановить активную подписку с текущего дня: списания до возобновления не выставляются
+// @Description и не учитываются в /subscriptions/total
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "UUID подписки"
+// @Success 200 {object} domain.Subscription
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /subscriptions/{id}/pause [post]
+func (h *SubscriptionHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.Pause)
+}
+
+// Resume godoc
+// @Summary Возобновление подписки
+// @Description Возобновить приостановленную подписку с текущего дня
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "UUID подписки"
+// @Success 200 {object} domain.Subscription
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /subscriptions/{id}/resume [post]
+func (h *SubscriptionHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.Resume)
+}
+
+// Cancel godoc
+// @Summary Отмена подписки
+// @Description Отменить подписку в конце оплаченного периода: ближайшее и следующие списания
+// @Description не выставляются (CancelAt), end_date сокращается до месяца последнего оплаченного дня
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "UUID подписки"
+// @Success 200 {object} domain.Subscription
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /subscriptions/{id}/cancel [post]
+func (h *SubscriptionHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.Cancel)
+}
+
+func (h *SubscriptionHandler) transition(
+	w http.ResponseWriter,
+	r *http.Request,
+	apply func(ctx context.Context, id uuid.UUID) (*domain.Subscription, error),
+) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeInvalidField(w, r, "id", "invalid UUID")
+		return
+	}
+
+	sub, err := apply(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, sub, http.StatusOK)
+}
+
+// Purge godoc
+// @Summary Очистка удаленных подписок
+// @Description Окончательно удалить подписки арендатора, удаленные раньше срока хранения SOFT_DELETE_RETENTION,
+// @Description вместе с их историей цен. Удаление каждой подписки записывается в журнал аудита с action purge
+// @Tags admin
+// @Produce json
+// @Success 200 {object} dto.PurgeResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /admin/subscriptions/purge [post]
+func (h *SubscriptionHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	purged, err := h.service.Purge(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, dto.PurgeResponse{Purged: purged}, http.StatusOK)
+}
+
 // List godoc
 // @Summary Список подписок
-// @Description Получить список подписок (можно фильтровать по userId, serviceName)
+// @Description Получить страницу списка подписок с фильтрами.
+// @Description Для следующей страницы передайте next_cursor из ответа в параметр cursor
+// @Description с той же сортировкой; на последней странице next_cursor = null
 // @Tags subscriptions
 // @Produce json
 // @Param user_id query string false "UUID пользователя"
 // @Param service_name query string false "Наименование сервиса в подписке"
-// @Success 200 {object} []domain.Subscription
-// @Failure 400 {string} string
-// @Failure 500 {string} string
+// @Param q query string false "Поиск по подстроке в названии сервиса без учета регистра"
+// @Param active_on query string false "Подписка активна в этом месяце. Формат MM-YYYY"
+// @Param start_from query string false "Дата начала не раньше. Формат MM-YYYY"
+// @Param start_to query string false "Дата начала не позже. Формат MM-YYYY"
+// @Param end_from query string false "Дата окончания не раньше. Формат MM-YYYY"
+// @Param end_to query string false "Дата окончания не позже. Формат MM-YYYY"
+// @Param price_min query int false "Цена не меньше, в минорных единицах"
+// @Param price_max query int false "Цена не больше, в минорных единицах"
+// @Param open_ended query bool false "true - только бессрочные подписки, false - только с датой окончания"
+// @Param include_deleted query bool false "Включить удаленные подписки (только для администратора)"
+// @Param sort query string false "Поле сортировки: start_date, price, service_name, created_at (по умолчанию)"
+// @Param order query string false "Направление сортировки: asc (по умолчанию) или desc"
+// @Param limit query int false "Размер страницы, по умолчанию 50, максимум 500"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Success 200 {object} dto.SubscriptionListResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
 // @Router /subscriptions/list [get]
 func (h *SubscriptionHandler) List(w http.ResponseWriter, r *http.Request) {
-	userUuid := r.URL.Query().Get("user_id")
-	serviceName := r.URL.Query().Get("service_name")
+	filter, err := parseListFilter(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
 
-	var userID *uuid.UUID
-	if userUuid != "" {
-		uid, err := parseUUID(userUuid)
-		if err != nil {
-			http.Error(w, "invalid user_id", http.StatusBadRequest)
-			return
-		}
-		userID = &uid
+	page, err := h.service.List(r.Context(), &filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := dto.SubscriptionListResponse{Items: page.Items}
+	if resp.Items == nil {
+		resp.Items = []domain.Subscription{}
+	}
+	if page.NextCursor != nil {
+		cursor := encodeCursor(page.NextCursor)
+		resp.NextCursor = &cursor
+	}
+
+	writeJSON(w, resp, http.StatusOK)
+}
+
+// SchedulePriceChange godoc
+// @Summary Запланировать изменение цены
+// @Description Новая цена действует для списаний начиная с месяца effective_from,
+// @Description суммы за предыдущие месяцы не пересчитываются.
+// @Description Повторный запрос с тем же месяцем заменяет ранее запланированную цену
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "UUID подписки"
+// @Param price body dto.SchedulePriceChangeRequest true "Тело запроса"
+// @Success 201 {object} domain.PriceChange
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /subscriptions/{id}/prices [post]
+func (h *SubscriptionHandler) SchedulePriceChange(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeInvalidField(w, r, "id", "invalid UUID")
+		return
+	}
+
+	var req dto.SchedulePriceChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidBody(w, r)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	effectiveFrom, err := parseMonthYear(req.EffectiveFrom)
+	if err != nil {
+		writeInvalidField(w, r, "effective_from", invalidMonthMessage)
+		return
+	}
+
+	change := &domain.PriceChange{
+		Price:         req.Price,
+		EffectiveFrom: effectiveFrom,
+	}
+
+	if err := h.service.SchedulePriceChange(r.Context(), id, change); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, change, http.StatusCreated)
+}
+
+// ListPriceChanges godoc
+// @Summary История цен подписки
+// @Description Все изменения цены подписки в порядке вступления в силу.
+// @Description До первого изменения действует цена из самой подписки
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "UUID подписки"
+// @Success 200 {object} []domain.PriceChange
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /subscriptions/{id}/prices [get]
+func (h *SubscriptionHandler) ListPriceChanges(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeInvalidField(w, r, "id", "invalid UUID")
+		return
 	}
 
-	var serviceNamePtr *string
-	if serviceName != "" {
-		serviceNamePtr = &serviceName
+	changes, err := h.service.ListPriceChanges(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
 	}
 
-	list, err := h.service.List(r.Context(), userID, serviceNamePtr)
+	if changes == nil {
+		changes = []domain.PriceChange{}
+	}
+
+	writeJSON(w, changes, http.StatusOK)
+}
+
+// CancelPriceChange godoc
+// @Summary Отмена изменения цены
+// @Description Удалить изменение цены, действующее с указанного месяца
+// @Tags subscriptions
+// @Param id path string true "UUID подписки"
+// @Param effective_from path string true "Месяц вступления цены в силу. Формат MM-YYYY"
+// @Success 204 {string} string "No Content"
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /subscriptions/{id}/prices/{effective_from} [delete]
+func (h *SubscriptionHandler) CancelPriceChange(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
 	if err != nil {
-		http.Error(w, "Get subscriptions list error: "+err.Error(), http.StatusInternalServerError)
+		writeInvalidField(w, r, "id", "invalid UUID")
 		return
 	}
 
-	writeJSON(w, list, http.StatusOK)
+	effectiveFrom, err := parseMonthYear(chi.URLParam(r, "effective_from"))
+	if err != nil {
+		writeInvalidField(w, r, "effective_from", invalidMonthMessage)
+		return
+	}
+
+	if err := h.service.CancelPriceChange(r.Context(), id, effectiveFrom); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
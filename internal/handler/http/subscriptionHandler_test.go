@@ -0,0 +1,66 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testTask/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	sub := &domain.Subscription{
+		ID:          uuid.New(),
+		ServiceName: "Netflix",
+		Price:       39900,
+		StartDate:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		CreatedAt:   time.Date(2025, time.January, 2, 3, 4, 5, 6, time.UTC),
+	}
+
+	// курсоры, выданные сервером, декодируются обратно без изменений
+	for _, sort := range []domain.ListSort{
+		domain.SortByStartDate, domain.SortByPrice, domain.SortByServiceName, domain.SortByCreatedAt,
+	} {
+		filter := domain.ListFilter{Sort: sort, Desc: true}
+		want := filter.CursorAfter(sub)
+
+		got, err := decodeCursor(encodeCursor(&want))
+		if err != nil {
+			t.Errorf("%s: decode issued cursor: %v", sort, err)
+			continue
+		}
+		if *got != want {
+			t.Errorf("%s: decoded %+v, want %+v", sort, *got, want)
+		}
+	}
+
+	token := func(sort domain.ListSort, value string) string {
+		data, _ := json.Marshal(cursorToken{Sort: sort, Value: value, ID: sub.ID})
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	tampered := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"not json", base64.RawURLEncoding.EncodeToString([]byte("cursor"))},
+		{"unknown sort", token("owner", "x")},
+		{"price not a number", token(domain.SortByPrice, "1; DROP TABLE subscriptions")},
+		{"price out of range", token(domain.SortByPrice, "99999999999999999999")},
+		{"start date format", token(domain.SortByStartDate, "01-2025")},
+		{"start date year zero", token(domain.SortByStartDate, "0000-01-01")},
+		{"created at without time", token(domain.SortByCreatedAt, "2025-01-02")},
+		{"service name with nul", token(domain.SortByServiceName, "Net\x00flix")},
+	}
+
+	for _, tt := range tampered {
+		t.Run(tt.name, func(t *testing.T) {
+			if cursor, err := decodeCursor(tt.cursor); err == nil {
+				t.Errorf("decoded tampered cursor %+v", cursor)
+			}
+		})
+	}
+}
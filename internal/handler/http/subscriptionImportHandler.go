@@ -0,0 +1,255 @@
+package http
+
+import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"testTask/internal/domain"
+	"testTask/internal/dto"
+)
+
+// maxImportBodySize предельный размер CSV-файла импорта
+const maxImportBodySize = 10 << 20
+
+// колонки CSV-файла импорта; остальные колонки не принимаются
+var (
+	importRequiredColumns = []string{"service_name", "price", "start_date"}
+	importOptionalColumns = []string{"user_id", "end_date"}
+)
+
+// Import godoc
+// @Summary Импорт подписок из CSV
+// @Description Массовое создание подписок из CSV-файла. Первая строка - заголовок с колонками
+// @Description service_name, price, start_date и необязательными user_id, end_date; даты в формате MM-YYYY.
+// @Description Каждая строка проверяется по тем же правилам, что и в POST /subscriptions.
+// @Description Строки с ошибками попадают в отчет и пропускаются, остальные сохраняются в одной транзакции.
+// @Description При dry_run=true файл только проверяется. Номера строк в отчете - строки файла, заголовок - строка 1
+// @Tags subscriptions
+// @Accept text/csv
+// @Produce json
+// @Param dry_run query bool false "Только проверить файл, ничего не сохраняя"
+// @Param file body string true "CSV-файл"
+// @Success 200 {object} dto.ImportResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 413 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /subscriptions/import [post]
+func (h *SubscriptionHandler) Import(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			writeInvalidField(w, r, "dry_run", "must be true or false")
+			return
+		}
+	}
+
+	rows, rowErrors, total, err := parseImportCSV(http.MaxBytesReader(w, r.Body, maxImportBodySize))
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeProblem(w, r, http.StatusRequestEntityTooLarge, CodeInvalidBody,
+			fmt.Sprintf("file is larger than %d bytes", maxBytesErr.Limit), nil)
+		return
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	result, err := h.service.Import(r.Context(), rows, dryRun)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := dto.ImportResponse{
+		DryRun:   dryRun,
+		Rows:     total,
+		Valid:    result.Valid,
+		Imported: result.Imported,
+		Errors:   make([]dto.ImportRowError, 0, len(rowErrors)+len(result.Errors)),
+	}
+
+	allErrors := append(rowErrors, result.Errors...)
+	slices.SortFunc(allErrors, func(a, b domain.ImportRowError) int { return cmp.Compare(a.Row, b.Row) })
+
+	for _, e := range allErrors {
+		rowErr := dto.ImportRowError{Row: e.Row, Errors: make([]dto.ImportFieldError, 0, len(e.Fields))}
+		for _, f := range e.Fields {
+			rowErr.Errors = append(rowErr.Errors, dto.ImportFieldError{Field: f.Field, Message: f.Message})
+		}
+		resp.Errors = append(resp.Errors, rowErr)
+	}
+
+	writeJSON(w, resp, http.StatusOK)
+}
+
+// parseImportCSV разбирает CSV-файл импорта. Строки, которые не проходят проверку
+// запроса, возвращаются в rowErrors, остальные - в rows; total - число строк с данными
+func parseImportCSV(body io.Reader) (rows []domain.ImportRow, rowErrors []domain.ImportRowError, total int, err error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, 0, domain.NewValidationError("file", "file is empty")
+	}
+	if err != nil {
+		return nil, nil, 0, csvError(err)
+	}
+
+	columns, err := importColumns(header)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, nil, 0, csvError(err)
+		}
+
+		total++
+		if total > domain.MaxImportRows {
+			return nil, nil, 0, domain.NewValidationError("file", fmt.Sprintf("at most %d rows can be imported at once", domain.MaxImportRows))
+		}
+
+		// строка с другим числом колонок - ошибка строки, а не всего файла
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rowErrors = append(rowErrors, domain.ImportRowError{
+				Row:    parseErr.StartLine,
+				Fields: []domain.FieldError{{Field: "file", Message: fmt.Sprintf("expected %d columns", len(header))}},
+			})
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		sub, err := importSubscription(record, columns)
+		var verr *domain.ValidationError
+		if errors.As(err, &verr) {
+			rowErrors = append(rowErrors, domain.ImportRowError{Row: line, Fields: verr.Fields})
+			continue
+		}
+		if err != nil {
+			return nil, nil, 0, err
+		}
+
+		rows = append(rows, domain.ImportRow{Row: line, Subscription: *sub})
+	}
+
+	if total == 0 {
+		return nil, nil, 0, domain.NewValidationError("file", "file has no rows")
+	}
+
+	return rows, rowErrors, total, nil
+}
+
+// importColumns индексы колонок по заголовку файла
+func importColumns(header []string) (map[string]int, error) {
+	verr := &domain.ValidationError{}
+	columns := make(map[string]int, len(header))
+
+	for i, name := range header {
+		if i == 0 {
+			// Excel сохраняет CSV в UTF-8 с BOM
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		switch {
+		case !slices.Contains(importRequiredColumns, name) && !slices.Contains(importOptionalColumns, name):
+			verr.Add("file", fmt.Sprintf("unknown column %q", name))
+		case columns[name] != 0:
+			verr.Add("file", fmt.Sprintf("duplicate column %q", name))
+		default:
+			columns[name] = i + 1
+		}
+	}
+
+	for _, name := range importRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			verr.Add("file", fmt.Sprintf("missing column %q", name))
+		}
+	}
+
+	if err := verr.Err(); err != nil {
+		return nil, err
+	}
+
+	return columns, nil
+}
+
+// importSubscription проверяет строку файла как запрос POST /subscriptions
+func importSubscription(record []string, columns map[string]int) (*domain.Subscription, error) {
+	value := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i-1])
+		}
+		return ""
+	}
+
+	verr := &domain.ValidationError{}
+
+	req := dto.CreateSubscriptionRequest{
+		ServiceName: value("service_name"),
+		UserID:      value("user_id"),
+		StartDate:   value("start_date"),
+	}
+
+	if v := value("price"); v != "" {
+		price, err := strconv.Atoi(v)
+		if err != nil {
+			verr.Add("price", "price must be an integer")
+		}
+		req.Price = price
+	}
+
+	if v := value("end_date"); v != "" {
+		req.EndDate = &v
+	}
+
+	sub, err := subscriptionFromRequest(&req)
+	var reqErr *domain.ValidationError
+	if errors.As(err, &reqErr) {
+		for _, f := range reqErr.Fields {
+			// service_id в файле нет, сервис задается только названием
+			if f.Field == "service_id" {
+				f.Field = "service_name"
+			}
+			verr.Add(f.Field, f.Message)
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := verr.Err(); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// csvError ошибка разбора CSV относится ко всему файлу
+func csvError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return domain.NewValidationError("file", fmt.Sprintf("line %d: %v", parseErr.StartLine, parseErr.Err))
+	}
+
+	return err
+}
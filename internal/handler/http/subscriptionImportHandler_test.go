@@ -0,0 +1,352 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testTask/internal/domain"
+	"testTask/internal/dto"
+	"testTask/internal/repository/memory"
+	"testTask/internal/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const testUserID = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+
+// fieldErrors ошибки по полям из ValidationError в виде "field: message"
+func fieldErrors(t *testing.T, err error) []string {
+	t.Helper()
+
+	var verr *domain.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("error %v is not a validation error", err)
+	}
+
+	var result []string
+	for _, f := range verr.Fields {
+		result = append(result, f.Field+": "+f.Message)
+	}
+
+	return result
+}
+
+func TestImportColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  []string
+		want    map[string]int
+		wantErr []string
+	}{
+		{
+			name:   "required columns",
+			header: []string{"service_name", "price", "start_date"},
+			want:   map[string]int{"service_name": 1, "price": 2, "start_date": 3},
+		},
+		{
+			name:   "any order with optional columns",
+			header: []string{"start_date", "user_id", "price", "end_date", "service_name"},
+			want:   map[string]int{"start_date": 1, "user_id": 2, "price": 3, "end_date": 4, "service_name": 5},
+		},
+		{
+			name:   "BOM, case and spaces",
+			header: []string{"\ufeffService_Name", " PRICE ", "start_date"},
+			want:   map[string]int{"service_name": 1, "price": 2, "start_date": 3},
+		},
+		{
+			name:    "unknown column",
+			header:  []string{"service_name", "price", "start_date", "currency"},
+			wantErr: []string{`file: unknown column "currency"`},
+		},
+		{
+			name:    "duplicate column",
+			header:  []string{"service_name", "price", "start_date", "price"},
+			wantErr: []string{`file: duplicate column "price"`},
+		},
+		{
+			name:    "missing columns",
+			header:  []string{"service_name", "user_id"},
+			wantErr: []string{`file: missing column "price"`, `file: missing column "start_date"`},
+		},
+		{
+			// BOM снимается только с первой колонки
+			name:    "BOM in the middle",
+			header:  []string{"service_name", "\ufeffprice", "start_date"},
+			wantErr: []string{`file: unknown column "\ufeffprice"`, `file: missing column "price"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := importColumns(tt.header)
+			if tt.wantErr != nil {
+				if errs := fieldErrors(t, err); !reflect.DeepEqual(errs, tt.wantErr) {
+					t.Errorf("errors %q, want %q", errs, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("importColumns: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("columns %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportSubscription(t *testing.T) {
+	columns := map[string]int{"service_name": 1, "price": 2, "start_date": 3, "user_id": 4, "end_date": 5}
+
+	tests := []struct {
+		name    string
+		record  []string
+		wantErr []string
+	}{
+		{
+			name:   "valid row",
+			record: []string{"Netflix", "39900", "07-2025", testUserID, "12-2025"},
+		},
+		{
+			name:   "empty price and end date",
+			record: []string{"Netflix", "", "07-2025", testUserID, ""},
+		},
+		{
+			name:    "price is not a number",
+			record:  []string{"Netflix", "399.00", "07-2025", testUserID, ""},
+			wantErr: []string{"price: price must be an integer"},
+		},
+		{
+			// в файле нет service_id: ошибка относится к service_name
+			name:    "missing service name",
+			record:  []string{"", "100", "07-2025", testUserID, ""},
+			wantErr: []string{"service_name: service_id or service_name is required"},
+		},
+		{
+			name:   "several errors",
+			record: []string{"Netflix", "abc", "2025-07", "not-a-uuid", "13-2025"},
+			wantErr: []string{
+				"price: price must be an integer",
+				"start_date: " + invalidMonthMessage,
+				"end_date: " + invalidMonthMessage,
+				"user_id: invalid UUID",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := importSubscription(tt.record, columns)
+			if tt.wantErr != nil {
+				if errs := fieldErrors(t, err); !reflect.DeepEqual(errs, tt.wantErr) {
+					t.Errorf("errors %q, want %q", errs, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("importSubscription: %v", err)
+			}
+			if sub.ServiceName != "Netflix" || sub.UserID.String() != testUserID {
+				t.Errorf("subscription %+v, want Netflix of user %s", sub, testUserID)
+			}
+		})
+	}
+}
+
+func TestParseImportCSV(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		wantRows  []int
+		wantErrs  map[int][]string
+		wantTotal int
+		wantErr   []string
+	}{
+		{
+			name:      "rows are numbered by file line",
+			file:      "service_name,price,start_date\nNetflix,100,01-2025\nSpotify,200,02-2025\n",
+			wantRows:  []int{2, 3},
+			wantTotal: 2,
+		},
+		{
+			name:      "BOM before header",
+			file:      "\ufeffservice_name,price,start_date\nNetflix,100,01-2025\n",
+			wantRows:  []int{2},
+			wantTotal: 1,
+		},
+		{
+			// поле в кавычках с переводом строки занимает две строки файла
+			name:      "multiline field",
+			file:      "service_name,price,start_date\n\"Yandex\nPlus\",100,01-2025\nNetflix,100,01-2025\n",
+			wantRows:  []int{2, 4},
+			wantTotal: 2,
+		},
+		{
+			name:      "wrong field count is a row error",
+			file:      "service_name,price,start_date\nNetflix,100\nSpotify,200,02-2025,extra\nKinopoisk,300,03-2025\n",
+			wantRows:  []int{4},
+			wantErrs:  map[int][]string{2: {"file: expected 3 columns"}, 3: {"file: expected 3 columns"}},
+			wantTotal: 3,
+		},
+		{
+			name:      "invalid row",
+			file:      "service_name,price,start_date\nNetflix,abc,01-2025\nSpotify,200,02-2025\n",
+			wantRows:  []int{3},
+			wantErrs:  map[int][]string{2: {"price: price must be an integer"}},
+			wantTotal: 2,
+		},
+		{
+			name:    "empty file",
+			file:    "",
+			wantErr: []string{"file: file is empty"},
+		},
+		{
+			name:    "header only",
+			file:    "service_name,price,start_date\n",
+			wantErr: []string{"file: file has no rows"},
+		},
+		{
+			name:    "unknown column",
+			file:    "service_name,price,start_date,currency\nNetflix,100,01-2025,RUB\n",
+			wantErr: []string{`file: unknown column "currency"`},
+		},
+		{
+			name:    "broken quotes",
+			file:    "service_name,price,start_date\n\"Netflix,100,01-2025\n",
+			wantErr: []string{`file: line 2: extraneous or missing " in quoted-field`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, rowErrors, total, err := parseImportCSV(strings.NewReader(tt.file))
+			if tt.wantErr != nil {
+				if errs := fieldErrors(t, err); !reflect.DeepEqual(errs, tt.wantErr) {
+					t.Errorf("errors %q, want %q", errs, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parseImportCSV: %v", err)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("total %d, want %d", total, tt.wantTotal)
+			}
+
+			var gotRows []int
+			for _, row := range rows {
+				gotRows = append(gotRows, row.Row)
+			}
+			if !reflect.DeepEqual(gotRows, tt.wantRows) {
+				t.Errorf("rows %v, want %v", gotRows, tt.wantRows)
+			}
+
+			var gotErrs map[int][]string
+			for _, e := range rowErrors {
+				if gotErrs == nil {
+					gotErrs = make(map[int][]string)
+				}
+				for _, f := range e.Fields {
+					gotErrs[e.Row] = append(gotErrs[e.Row], f.Field+": "+f.Message)
+				}
+			}
+			if !reflect.DeepEqual(gotErrs, tt.wantErrs) {
+				t.Errorf("row errors %v, want %v", gotErrs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestParseImportCSVMaxRows(t *testing.T) {
+	file := func(n int) string {
+		var b strings.Builder
+		b.WriteString("service_name,price,start_date\n")
+		for range n {
+			b.WriteString("Netflix,100,01-2025\n")
+		}
+		return b.String()
+	}
+
+	_, _, total, err := parseImportCSV(strings.NewReader(file(domain.MaxImportRows)))
+	if err != nil || total != domain.MaxImportRows {
+		t.Fatalf("parse %d rows: total %d, error %v", domain.MaxImportRows, total, err)
+	}
+
+	_, _, _, err = parseImportCSV(strings.NewReader(file(domain.MaxImportRows + 1)))
+	want := []string{fmt.Sprintf("file: at most %d rows can be imported at once", domain.MaxImportRows)}
+	if errs := fieldErrors(t, err); !reflect.DeepEqual(errs, want) {
+		t.Errorf("errors %q, want %q", errs, want)
+	}
+}
+
+func TestImportMergesParserAndServiceErrors(t *testing.T) {
+	store := memory.NewStore()
+	services := memory.NewServiceRepository(store)
+	svc := service.NewSubscriptionService(
+		memory.NewSubscriptionRepository(store),
+		services,
+		memory.NewExchangeRateRepository(store),
+		memory.NewPriceHistoryRepository(store),
+		memory.NewAuditRepository(store),
+		memory.NewOutboxRepository(store),
+		memory.NewWebhookDeliveryRepository(store),
+		memory.NewTransactor(store),
+		30*24*time.Hour,
+	)
+
+	ctx := domain.WithPrincipal(t.Context(), domain.SystemPrincipal(domain.DefaultTenant))
+	if err := services.Create(ctx, &domain.Service{ID: uuid.New(), Name: "Netflix", Currency: domain.DefaultCurrency}); err != nil {
+		t.Fatalf("create service: %v", err)
+	}
+
+	// строки 3 и 5 отклоняются при разборе файла, строка 2 - сервисом (нет такого
+	// сервиса); в отчете ошибки идут в порядке строк
+	file := "service_name,price,start_date,user_id\n" +
+		"Unknown,100,01-2025," + testUserID + "\n" +
+		"Netflix,abc,01-2025," + testUserID + "\n" +
+		"Netflix,100,01-2025," + testUserID + "\n" +
+		"Netflix,100\n"
+
+	req := httptest.NewRequest(http.MethodPost, "/subscriptions/import?dry_run=true", strings.NewReader(file))
+	req = req.WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	NewHandler(svc).Import(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body)
+	}
+
+	var resp dto.ImportResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if !resp.DryRun || resp.Rows != 4 || resp.Valid != 1 || resp.Imported != 0 {
+		t.Errorf("report %+v, want dry run of 4 rows with 1 valid", resp)
+	}
+
+	var got []string
+	for _, e := range resp.Errors {
+		for _, f := range e.Errors {
+			got = append(got, fmt.Sprintf("%d %s: %s", e.Row, f.Field, f.Message))
+		}
+	}
+	want := []string{
+		"2 service_name: " + domain.ErrServiceNotFound.Error(),
+		"3 price: price must be an integer",
+		"5 file: expected 4 columns",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("errors %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,321 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testTask/internal/domain"
+	"testTask/internal/dto"
+	"testTask/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type WebhookHandler struct {
+	service *service.WebhookService
+}
+
+func NewWebhookHandler(svc *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: svc}
+}
+
+func (h *WebhookHandler) RegisterRoutes(r chi.Router) {
+	r.Post("/webhooks", h.Create)
+	r.Get("/webhooks", h.List)
+	r.Get("/webhooks/{id}", h.Get)
+	r.Patch("/webhooks/{id}", h.Update)
+	r.Delete("/webhooks/{id}", h.Delete)
+	r.Get("/webhooks/{id}/deliveries", h.Deliveries)
+	r.Post("/webhooks/{id}/deliveries/{delivery_id}/retry", h.Redeliver)
+}
+
+func eventTypes(types []string) []domain.EventType {
+	result := make([]domain.EventType, 0, len(types))
+	for _, t := range types {
+		result = append(result, domain.EventType(t))
+	}
+
+	return result
+}
+
+// Create godoc
+// @Summary Регистрация вебхука
+// @Description На url отправляются POST-запросы с событиями выбранных типов. Тело подписывается
+// @Description HMAC-SHA256 секретом вебхука в заголовке X-Signature-256: sha256=<hex>.
+// @Description Вебхук пользователя получает события только о его подписках, вебхук администратора - обо всех.
+// @Description Секрет возвращается только в этом ответе
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body dto.CreateWebhookRequest true "Тело запроса"
+// @Success 201 {object} dto.CreateWebhookResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /webhooks [post]
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidBody(w, r)
+		return
+	}
+
+	webhook := &domain.Webhook{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: eventTypes(req.EventTypes),
+		Active:     req.Active == nil || *req.Active,
+	}
+
+	secret, err := h.service.Create(r.Context(), webhook)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, dto.CreateWebhookResponse{WebhookResponse: dto.NewWebhookResponse(webhook), Secret: secret}, http.StatusCreated)
+}
+
+// List godoc
+// @Summary Список вебхуков
+// @Description Вебхуки пользователя; администратору - все вебхуки арендатора
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} []dto.WebhookResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /webhooks [get]
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.service.List(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := make([]dto.WebhookResponse, 0, len(webhooks))
+	for i := range webhooks {
+		resp = append(resp, dto.NewWebhookResponse(&webhooks[i]))
+	}
+
+	writeJSON(w, resp, http.StatusOK)
+}
+
+// Get godoc
+// @Summary Информация о вебхуке
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "UUID вебхука"
+// @Success 200 {object} dto.WebhookResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeInvalidField(w, r, "id", "invalid UUID")
+		return
+	}
+
+	webhook, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, dto.NewWebhookResponse(webhook), http.StatusOK)
+}
+
+// Update godoc
+// @Summary Изменение вебхука
+// @Description Не переданные поля не меняются; event_types заменяются целиком
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "UUID вебхука"
+// @Param webhook body dto.UpdateWebhookRequest true "Тело запроса"
+// @Success 200 {object} dto.WebhookResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /webhooks/{id} [patch]
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeInvalidField(w, r, "id", "invalid UUID")
+		return
+	}
+
+	var req dto.UpdateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidBody(w, r)
+		return
+	}
+
+	webhook, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if req.URL != nil {
+		webhook.URL = *req.URL
+	}
+
+	if req.Secret != nil {
+		webhook.Secret = *req.Secret
+	}
+
+	if req.EventTypes != nil {
+		webhook.EventTypes = eventTypes(*req.EventTypes)
+	}
+
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := h.service.Update(r.Context(), webhook); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, dto.NewWebhookResponse(webhook), http.StatusOK)
+}
+
+// Delete godoc
+// @Summary Удаление вебхука
+// @Description Удаляет вебхук вместе с журналом доставок
+// @Tags webhooks
+// @Param id path string true "UUID вебхука"
+// @Success 204 {string} string "No Content"
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeInvalidField(w, r, "id", "invalid UUID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries godoc
+// @Summary Журнал доставок вебхука
+// @Description Доставки событий от новых к старым: состояние, число попыток, код ответа и ошибка последней попытки
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "UUID вебхука"
+// @Param status query string false "Состояние: pending, delivered или dead"
+// @Param limit query int false "Размер страницы, по умолчанию 50, максимум 500"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Success 200 {object} dto.WebhookDeliveryPageResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeInvalidField(w, r, "id", "invalid UUID")
+		return
+	}
+
+	q := r.URL.Query()
+	verr := &domain.ValidationError{}
+
+	filter := domain.DeliveryFilter{
+		Status: domain.DeliveryStatus(q.Get("status")),
+		Limit:  domain.DefaultDeliveryLimit,
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			verr.Add("limit", "must be an integer")
+		}
+		filter.Limit = limit
+	}
+
+	if v := q.Get("cursor"); v != "" {
+		before, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			verr.Add("cursor", "invalid cursor")
+		}
+		filter.BeforeID = &before
+	}
+
+	if err := verr.Err(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := filter.Validate(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	page, err := h.service.Deliveries(r.Context(), id, &filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, dto.NewWebhookDeliveryPageResponse(page), http.StatusOK)
+}
+
+// Redeliver godoc
+// @Summary Повтор доставки
+// @Description Возвращает в очередь доставку в состоянии dead, исчерпавшую попытки
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "UUID вебхука"
+// @Param delivery_id path int true "ID доставки"
+// @Success 200 {object} dto.WebhookDeliveryResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Security ApiKeyAuth || BearerAuth
+// @Router /webhooks/{id}/deliveries/{delivery_id}/retry [post]
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeInvalidField(w, r, "id", "invalid UUID")
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(chi.URLParam(r, "delivery_id"), 10, 64)
+	if err != nil {
+		writeInvalidField(w, r, "delivery_id", "must be an integer")
+		return
+	}
+
+	delivery, err := h.service.Redeliver(r.Context(), id, deliveryID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, dto.NewWebhookDeliveryResponse(delivery), http.StatusOK)
+}
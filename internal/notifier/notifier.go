@@ -0,0 +1,44 @@
+package notifier
+
+import (
+	"context"
+	"log/slog"
+	"testTask/internal/domain"
+)
+
+// Notifier доставляет пользователю напоминание о предстоящем списании.
+// Ошибка означает, что напоминание не доставлено и его нужно отправить повторно
+type Notifier interface {
+	Notify(ctx context.Context, r *domain.Reminder) error
+}
+
+// LogNotifier пишет напоминания в лог; для локального запуска
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(_ context.Context, r *domain.Reminder) error {
+	slog.Info("renewal reminder",
+		"tenant_id", r.TenantID,
+		"subscription_id", r.SubscriptionID,
+		"user_id", r.UserID,
+		"service_name", r.ServiceName,
+		"charge_date", r.ChargeDate.Format("2006-01-02"),
+		"amount", r.Money().String(),
+	)
+
+	return nil
+}
+
+// NopNotifier не отправляет напоминаний; остается только событие subscription.renewal_upcoming
+type NopNotifier struct{}
+
+func NewNopNotifier() *NopNotifier {
+	return &NopNotifier{}
+}
+
+func (n *NopNotifier) Notify(_ context.Context, _ *domain.Reminder) error {
+	return nil
+}
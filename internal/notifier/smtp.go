@@ -0,0 +1,74 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"testTask/internal/domain"
+	"time"
+)
+
+// UserIDPlaceholder заменяется в адресе получателя на user_id владельца подписки
+const UserIDPlaceholder = "{user_id}"
+
+// SMTPNotifier отправляет напоминание письмом. Адресов пользователей сервис не хранит,
+// поэтому получатель задается шаблоном, например "{user_id}@users.example.com"
+// для почтового шлюза, или одним адресом для всех напоминаний
+type SMTPNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   string
+}
+
+// NewSMTPNotifier addr - host:port сервера; без username письма отправляются без авторизации
+func NewSMTPNotifier(addr, username, password, from, to string) (*SMTPNotifier, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smtp address: %w", err)
+	}
+
+	n := &SMTPNotifier{addr: addr, from: from, to: to}
+	if username != "" {
+		n.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return n, nil
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, r *domain.Reminder) error {
+	to := strings.ReplaceAll(n.to, UserIDPlaceholder, r.UserID.String())
+
+	subject := fmt.Sprintf("Скоро списание по подписке %s", r.ServiceName)
+	body := fmt.Sprintf("%s по подписке %s будет списано %s.\r\n",
+		r.ChargeDate.Format("02.01.2006"), r.ServiceName, r.Money())
+
+	msg := strings.Join([]string{
+		"From: " + n.from,
+		"To: " + to,
+		"Subject: " + mime.BEncoding.Encode("UTF-8", subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Transfer-Encoding: 8bit",
+		"",
+		body,
+	}, "\r\n")
+
+	// net/smtp не принимает контекст: письмо отправляется в отдельной горутине,
+	// а ожидание прерывается по отмене ctx
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(n.addr, n.auth, n.from, []string{to}, []byte(msg))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,42 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testTask/internal/domain"
+	"testTask/internal/publisher"
+	"time"
+)
+
+// WebhookNotifier отправляет напоминание POST-запросом с JSON телом на адрес
+// сервиса уведомлений. Доставленным считается ответ 2xx
+type WebhookNotifier struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhookNotifier если secret не пуст, тело подписывается в заголовке X-Signature-256
+func NewWebhookNotifier(url string, secret []byte, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{url: url, secret: secret, client: &http.Client{Timeout: timeout}}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, r *domain.Reminder) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	status, err := publisher.PostJSON(ctx, n.client, n.url, body, n.secret, nil)
+	if err != nil {
+		return err
+	}
+
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("notification webhook responded with status %d", status)
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package publisher
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"testTask/internal/domain"
+	"time"
+)
+
+// NATSPublisher публикует события в NATS (или совместимый сервер) в subject
+// "<prefix>.<тип события>". Реализует минимальную часть текстового протокола NATS:
+// после каждой публикации ждет PONG, чтобы убедиться, что сервер принял сообщение
+type NATSPublisher struct {
+	addr    string
+	user    *url.Userinfo
+	prefix  string
+	timeout time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewNATSPublisher rawURL в формате nats://[user:password@]host:port
+func NewNATSPublisher(rawURL, prefix string, timeout time.Duration) (*NATSPublisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS url: %w", err)
+	}
+	if u.Scheme != "nats" || u.Host == "" {
+		return nil, errors.New("invalid NATS url: expected nats://host:port")
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+
+	return &NATSPublisher{addr: addr, user: u.User, prefix: prefix, timeout: timeout}, nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, e *domain.Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.publish(ctx, p.prefix+"."+string(e.Type), payload); err != nil {
+		// после ошибки состояние соединения неизвестно, следующая публикация переподключится
+		p.close()
+		return fmt.Errorf("nats publish: %w", err)
+	}
+
+	return nil
+}
+
+// Close закрывает соединение с сервером
+func (p *NATSPublisher) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.close()
+}
+
+func (p *NATSPublisher) publish(ctx context.Context, subject string, payload []byte) error {
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := p.setDeadline(ctx); err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(payload), payload)
+	if _, err := p.conn.Write([]byte(msg)); err != nil {
+		return err
+	}
+
+	return p.waitPong()
+}
+
+func (p *NATSPublisher) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: p.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return err
+	}
+
+	p.conn = conn
+	p.reader = bufio.NewReader(conn)
+
+	if err := p.setDeadline(ctx); err != nil {
+		return err
+	}
+
+	// сервер начинает сессию строкой INFO
+	line, err := p.readLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("unexpected greeting %q", line)
+	}
+
+	options := map[string]any{"verbose": false, "pedantic": false, "name": "subscriptions-outbox"}
+	if p.user != nil {
+		options["user"] = p.user.Username()
+		if password, ok := p.user.Password(); ok {
+			options["pass"] = password
+		}
+	}
+
+	connect, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.conn.Write([]byte("CONNECT " + string(connect) + "\r\nPING\r\n")); err != nil {
+		return err
+	}
+
+	return p.waitPong()
+}
+
+// ждет PONG на отправленный PING, отвечая на PING сервера
+func (p *NATSPublisher) waitPong() error {
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (p *NATSPublisher) readLine() (string, error) {
+	line, err := p.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (p *NATSPublisher) setDeadline(ctx context.Context) error {
+	deadline := time.Now().Add(p.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	return p.conn.SetDeadline(deadline)
+}
+
+func (p *NATSPublisher) close() {
+	if p.conn != nil {
+		_ = p.conn.Close()
+		p.conn = nil
+		p.reader = nil
+	}
+}
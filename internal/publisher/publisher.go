@@ -0,0 +1,83 @@
+package publisher
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"sync"
+	"testTask/internal/domain"
+)
+
+// Publisher доставляет доменные события потребителям. Ошибка означает, что
+// событие не доставлено и его нужно опубликовать повторно
+type Publisher interface {
+	Publish(ctx context.Context, event *domain.Event) error
+}
+
+// LogPublisher пишет события в лог; для локального запуска без брокера
+type LogPublisher struct{}
+
+func NewLogPublisher() *LogPublisher {
+	return &LogPublisher{}
+}
+
+func (p *LogPublisher) Publish(_ context.Context, e *domain.Event) error {
+	slog.Info("event published",
+		"event_id", e.ID,
+		"type", e.Type,
+		"tenant_id", e.TenantID,
+		"subscription_id", e.SubscriptionID,
+	)
+
+	return nil
+}
+
+// MemoryPublisher запоминает опубликованные события; подменяет брокер в тестах.
+// Пока задана Err, публикация завершается этой ошибкой
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []domain.Event
+	err    error
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(_ context.Context, e *domain.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+
+	p.events = append(p.events, *e)
+	return nil
+}
+
+// SetErr заставляет следующие публикации завершаться ошибкой err; nil - снова успешно
+func (p *MemoryPublisher) SetErr(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.err = err
+}
+
+// Events копия опубликованных событий в порядке публикации
+func (p *MemoryPublisher) Events() []domain.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]domain.Event(nil), p.events...)
+}
+
+// Sign подпись тела запроса HMAC-SHA256 в формате "sha256=<hex>"
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,121 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"testTask/internal/domain"
+	"time"
+)
+
+const (
+	SignatureHeader  = "X-Signature-256"
+	EventIDHeader    = "X-Event-ID"
+	EventTypeHeader  = "X-Event-Type"
+	DeliveryIDHeader = "X-Delivery-ID"
+)
+
+// WebhookPublisher отправляет событие POST-запросом с JSON телом.
+// Доставленным считается событие, на которое получен ответ 2xx
+type WebhookPublisher struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhookPublisher если secret не пуст, тело подписывается в заголовке X-Signature-256
+func NewWebhookPublisher(url string, secret []byte, timeout time.Duration) *WebhookPublisher {
+	return &WebhookPublisher{url: url, secret: secret, client: &http.Client{Timeout: timeout}}
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, e *domain.Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	header.Set(EventIDHeader, e.ID.String())
+	header.Set(EventTypeHeader, string(e.Type))
+
+	status, err := PostJSON(ctx, p.client, p.url, body, p.secret, header)
+	if err != nil {
+		return err
+	}
+
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("webhook responded with status %d", status)
+	}
+
+	return nil
+}
+
+// ErrInternalAddress адрес назначения вебхука не публичный
+var ErrInternalAddress = errors.New("webhook address is not public")
+
+// NewWebhookClient HTTP-клиент для вебхуков, адреса которых задают клиенты API.
+// Адрес проверяется при соединении, уже после разрешения имени, поэтому запрос не
+// уйдет во внутреннюю сеть и через DNS-имя. Редиректы не выполняются: ответ 3xx
+// считается неудачной доставкой
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !domain.PublicAddr(addr.Addr()) {
+				return fmt.Errorf("%w: %s", ErrInternalAddress, addr.Addr())
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// через прокси соединение устанавливалось бы с ним, минуя проверку адреса
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// PostJSON отправляет body POST-запросом и возвращает код ответа. Если secret
+// не пуст, тело подписывается в заголовке X-Signature-256
+func PostJSON(ctx context.Context, client *http.Client, url string, body, secret []byte, header http.Header) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(secret, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// тело дочитывается, чтобы соединение вернулось в пул
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
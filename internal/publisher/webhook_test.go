@@ -0,0 +1,52 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookClientRefusesInternalAddress(t *testing.T) {
+	var called bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	client := NewWebhookClient(time.Second)
+
+	_, err := PostJSON(context.Background(), client, srv.URL, []byte(`{}`), nil, http.Header{})
+	if !errors.Is(err, ErrInternalAddress) {
+		t.Fatalf("PostJSON to %s = %v, want ErrInternalAddress", srv.URL, err)
+	}
+	if called {
+		t.Error("request reached a loopback server")
+	}
+}
+
+func TestWebhookClientDoesNotFollowRedirects(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("redirect was followed")
+	}))
+	defer target.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer srv.Close()
+
+	// проверка адреса отключена, чтобы дойти до тестового сервера на loopback
+	client := NewWebhookClient(time.Second)
+	client.Transport = http.DefaultTransport
+
+	status, err := PostJSON(context.Background(), client, srv.URL, []byte(`{}`), nil, http.Header{})
+	if err != nil {
+		t.Fatalf("PostJSON: %v", err)
+	}
+	if status != http.StatusFound {
+		t.Errorf("status = %d, want %d", status, http.StatusFound)
+	}
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"testTask/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Если ключа нет, GetByPrefix и Revoke возвращают domain.ErrAPIKeyNotFound
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error)
+	// tenantID == nil - ключи всех арендаторов
+	List(ctx context.Context, tenantID *string) ([]domain.APIKey, error)
+	// помечает ключ отозванным; запись остается для аудита
+	Revoke(ctx context.Context, id uuid.UUID, tenantID *string) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID) error
+}
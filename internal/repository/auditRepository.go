@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"testTask/internal/domain"
+)
+
+// Журнал аудита только дополняется: записи не изменяются и не удаляются
+type AuditRepository interface {
+	Create(ctx context.Context, entry *domain.AuditEntry) error
+	// сохраняет записи пакетом, например при импорте; ID и время создания не заполняются
+	CreateBatch(ctx context.Context, entries []domain.AuditEntry) error
+	// возвращает не больше filter.Limit записей от новых к старым
+	List(ctx context.Context, filter *domain.AuditFilter) ([]domain.AuditEntry, error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"testTask/internal/domain"
+)
+
+// Если курса нет, Get и Delete возвращают domain.ErrExchangeRateNotFound
+type ExchangeRateRepository interface {
+	Upsert(ctx context.Context, rate *domain.ExchangeRate) error
+	Get(ctx context.Context, base, quote string) (*domain.ExchangeRate, error)
+	List(ctx context.Context) ([]domain.ExchangeRate, error)
+	Delete(ctx context.Context, base, quote string) error
+}
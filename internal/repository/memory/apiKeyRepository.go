@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"testTask/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyRepository struct {
+	store *Store
+}
+
+func NewAPIKeyRepository(store *Store) *APIKeyRepository {
+	return &APIKeyRepository{store: store}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	defer r.store.lock(ctx)()
+
+	for _, k := range r.store.data.apiKeys {
+		if k.ID == key.ID || k.Prefix == key.Prefix {
+			return fmt.Errorf("api key %s already exists", key.Prefix)
+		}
+	}
+
+	key.CreatedAt = now()
+
+	stored := *key
+	stored.Hash = bytes.Clone(key.Hash)
+	stored.RevokedAt = nil
+	stored.LastUsedAt = nil
+	r.store.data.apiKeys[key.ID] = stored
+
+	return nil
+}
+
+func (r *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	defer r.store.lock(ctx)()
+
+	for _, k := range r.store.data.apiKeys {
+		if k.Prefix == prefix {
+			k.Hash = bytes.Clone(k.Hash)
+			return &k, nil
+		}
+	}
+
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (r *APIKeyRepository) List(ctx context.Context, tenantID *string) ([]domain.APIKey, error) {
+	defer r.store.lock(ctx)()
+
+	var keys []domain.APIKey
+	for _, k := range r.store.data.apiKeys {
+		if tenantID != nil && (k.TenantID == nil || *k.TenantID != *tenantID) {
+			continue
+		}
+		k.Hash = bytes.Clone(k.Hash)
+		keys = append(keys, k)
+	}
+
+	slices.SortFunc(keys, func(a, b domain.APIKey) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), bytes.Compare(a.ID[:], b.ID[:]))
+	})
+
+	return keys, nil
+}
+
+// повторный отзыв не меняет время первого
+func (r *APIKeyRepository) Revoke(ctx context.Context, id uuid.UUID, tenantID *string) error {
+	defer r.store.lock(ctx)()
+
+	k, ok := r.store.data.apiKeys[id]
+	if !ok || (tenantID != nil && (k.TenantID == nil || *k.TenantID != *tenantID)) {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	if k.RevokedAt == nil {
+		revokedAt := now()
+		k.RevokedAt = &revokedAt
+		r.store.data.apiKeys[id] = k
+	}
+
+	return nil
+}
+
+// время последнего использования обновляется не чаще раза в минуту
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	defer r.store.lock(ctx)()
+
+	k, ok := r.store.data.apiKeys[id]
+	if !ok {
+		return nil
+	}
+
+	usedAt := now()
+	if k.LastUsedAt == nil || k.LastUsedAt.Before(usedAt.Add(-time.Minute)) {
+		k.LastUsedAt = &usedAt
+		r.store.data.apiKeys[id] = k
+	}
+
+	return nil
+}
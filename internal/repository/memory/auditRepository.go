@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"testTask/internal/domain"
+)
+
+type AuditRepository struct {
+	store *Store
+}
+
+func NewAuditRepository(store *Store) *AuditRepository {
+	return &AuditRepository{store: store}
+}
+
+// Записи создаются в транзакции изменения подписки из контекста (Transactor.WithinTx)
+func (r *AuditRepository) Create(ctx context.Context, e *domain.AuditEntry) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.store.lock(ctx)()
+
+	r.store.data.auditSeq++
+	e.ID = r.store.data.auditSeq
+	e.CreatedAt = now()
+
+	r.store.data.audit = append(r.store.data.audit, auditRow{tenantID: tenantID, entry: *e})
+
+	return nil
+}
+
+func (r *AuditRepository) CreateBatch(ctx context.Context, entries []domain.AuditEntry) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.store.lock(ctx)()
+
+	createdAt := now()
+	for i := range entries {
+		r.store.data.auditSeq++
+		entries[i].ID = r.store.data.auditSeq
+		entries[i].CreatedAt = createdAt
+
+		r.store.data.audit = append(r.store.data.audit, auditRow{tenantID: tenantID, entry: entries[i]})
+	}
+
+	return nil
+}
+
+// записи хранятся в порядке ID, поэтому читаются с конца
+func (r *AuditRepository) List(ctx context.Context, filter *domain.AuditFilter) ([]domain.AuditEntry, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.store.lock(ctx)()
+
+	var entries []domain.AuditEntry
+
+	for i := len(r.store.data.audit) - 1; i >= 0 && len(entries) < filter.Limit; i-- {
+		row := r.store.data.audit[i]
+		e := row.entry
+
+		switch {
+		case row.tenantID != tenantID,
+			filter.SubscriptionID != nil && e.SubscriptionID != *filter.SubscriptionID,
+			filter.OwnerID != nil && e.OwnerID != *filter.OwnerID,
+			filter.Actor != "" && e.Actor != filter.Actor,
+			filter.Action != "" && e.Action != filter.Action,
+			filter.From != nil && e.CreatedAt.Before(*filter.From),
+			filter.To != nil && e.CreatedAt.After(*filter.To),
+			filter.BeforeID != nil && e.ID >= *filter.BeforeID:
+			continue
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"testTask/internal/domain"
+)
+
+type ExchangeRateRepository struct {
+	store *Store
+}
+
+func NewExchangeRateRepository(store *Store) *ExchangeRateRepository {
+	return &ExchangeRateRepository{store: store}
+}
+
+func (r *ExchangeRateRepository) Upsert(ctx context.Context, rate *domain.ExchangeRate) error {
+	defer r.store.lock(ctx)()
+
+	rate.UpdatedAt = now()
+	r.store.data.rates[rateKey{rate.BaseCurrency, rate.QuoteCurrency}] = *rate
+
+	return nil
+}
+
+func (r *ExchangeRateRepository) Get(ctx context.Context, base, quote string) (*domain.ExchangeRate, error) {
+	defer r.store.lock(ctx)()
+
+	rate, ok := r.store.data.rates[rateKey{base, quote}]
+	if !ok {
+		return nil, domain.ErrExchangeRateNotFound
+	}
+
+	return &rate, nil
+}
+
+func (r *ExchangeRateRepository) List(ctx context.Context) ([]domain.ExchangeRate, error) {
+	defer r.store.lock(ctx)()
+
+	var rates []domain.ExchangeRate
+	for _, rate := range r.store.data.rates {
+		rates = append(rates, rate)
+	}
+
+	slices.SortFunc(rates, func(a, b domain.ExchangeRate) int {
+		return cmp.Or(cmp.Compare(a.BaseCurrency, b.BaseCurrency), cmp.Compare(a.QuoteCurrency, b.QuoteCurrency))
+	})
+
+	return rates, nil
+}
+
+func (r *ExchangeRateRepository) Delete(ctx context.Context, base, quote string) error {
+	defer r.store.lock(ctx)()
+
+	key := rateKey{base, quote}
+	if _, ok := r.store.data.rates[key]; !ok {
+		return domain.ErrExchangeRateNotFound
+	}
+
+	delete(r.store.data.rates, key)
+
+	return nil
+}
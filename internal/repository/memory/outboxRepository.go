@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"context"
+	"testTask/internal/domain"
+	"time"
+)
+
+type OutboxRepository struct {
+	store *Store
+}
+
+func NewOutboxRepository(store *Store) *OutboxRepository {
+	return &OutboxRepository{store: store}
+}
+
+func (r *OutboxRepository) Add(ctx context.Context, e *domain.Event) error {
+	defer r.store.lock(ctx)()
+
+	r.add(e)
+
+	return nil
+}
+
+func (r *OutboxRepository) AddBatch(ctx context.Context, events []domain.Event) error {
+	defer r.store.lock(ctx)()
+
+	for i := range events {
+		r.add(&events[i])
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) add(e *domain.Event) {
+	e.OccurredAt = now()
+
+	r.store.data.outboxSeq++
+	r.store.data.outbox = append(r.store.data.outbox, outboxRow{
+		message:       domain.OutboxMessage{ID: r.store.data.outboxSeq, Event: *e},
+		nextAttemptAt: e.OccurredAt,
+	})
+}
+
+func (r *OutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error) {
+	defer r.store.lock(ctx)()
+
+	current := now()
+
+	var messages []domain.OutboxMessage
+	for i := range r.store.data.outbox {
+		if len(messages) == limit {
+			break
+		}
+
+		row := &r.store.data.outbox[i]
+		if row.publishedAt == nil && !row.nextAttemptAt.After(current) {
+			row.nextAttemptAt = current.Add(lease)
+			messages = append(messages, row.message)
+		}
+	}
+
+	return messages, nil
+}
+
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id int64) error {
+	defer r.store.lock(ctx)()
+
+	if row := r.store.data.outboxRow(id); row != nil {
+		publishedAt := now()
+		row.publishedAt = &publishedAt
+		row.message.Attempts++
+		row.lastError = nil
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, reason string, retryAfter time.Duration) error {
+	defer r.store.lock(ctx)()
+
+	if row := r.store.data.outboxRow(id); row != nil {
+		row.message.Attempts++
+		row.lastError = &reason
+		row.nextAttemptAt = now().Add(retryAfter)
+	}
+
+	return nil
+}
+
+// outboxRow строка outbox для изменения на месте; nil - события нет
+func (s *state) outboxRow(id int64) *outboxRow {
+	for i := range s.outbox {
+		if s.outbox[i].message.ID == id {
+			return &s.outbox[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"testTask/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PriceHistoryRepository struct {
+	store *Store
+}
+
+func NewPriceHistoryRepository(store *Store) *PriceHistoryRepository {
+	return &PriceHistoryRepository{store: store}
+}
+
+// priceChanges история цен подписки в порядке EffectiveFrom
+func (s *state) priceChanges(subscriptionID uuid.UUID) []domain.PriceChange {
+	var changes []domain.PriceChange
+	for key, c := range s.prices {
+		if key.subscriptionID == subscriptionID {
+			changes = append(changes, c)
+		}
+	}
+
+	slices.SortFunc(changes, func(a, b domain.PriceChange) int { return a.EffectiveFrom.Compare(b.EffectiveFrom) })
+
+	return changes
+}
+
+// Как и в postgres.PriceHistoryRepository, подписка должна принадлежать
+// арендатору клиента; удаленная подписка тоже подходит
+func (r *PriceHistoryRepository) Upsert(ctx context.Context, change *domain.PriceChange) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.store.lock(ctx)()
+
+	if row, ok := r.store.data.subscriptions[change.SubscriptionID]; !ok || row.tenantID != tenantID {
+		return domain.ErrSubscriptionNotFound
+	}
+
+	change.CreatedAt = now()
+	r.store.data.prices[priceKey{change.SubscriptionID, timeKey(change.EffectiveFrom)}] = *change
+
+	return nil
+}
+
+func (r *PriceHistoryRepository) List(ctx context.Context, subscriptionID uuid.UUID) ([]domain.PriceChange, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.store.lock(ctx)()
+
+	if row, ok := r.store.data.subscriptions[subscriptionID]; !ok || row.tenantID != tenantID {
+		return nil, nil
+	}
+
+	return r.store.data.priceChanges(subscriptionID), nil
+}
+
+func (r *PriceHistoryRepository) Delete(ctx context.Context, subscriptionID uuid.UUID, effectiveFrom time.Time) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.store.lock(ctx)()
+
+	key := priceKey{subscriptionID, timeKey(effectiveFrom)}
+
+	row, ok := r.store.data.subscriptions[subscriptionID]
+	if _, exists := r.store.data.prices[key]; !exists || !ok || row.tenantID != tenantID {
+		return domain.ErrPriceChangeNotFound
+	}
+
+	delete(r.store.data.prices, key)
+
+	return nil
+}
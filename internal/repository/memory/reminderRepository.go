@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testTask/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReminderRepository struct {
+	store *Store
+}
+
+func NewReminderRepository(store *Store) *ReminderRepository {
+	return &ReminderRepository{store: store}
+}
+
+// Tenants арендаторы неудаленных подписок
+func (r *ReminderRepository) Tenants(ctx context.Context) ([]string, error) {
+	defer r.store.lock(ctx)()
+
+	var tenants []string
+	for _, row := range r.store.data.subscriptions {
+		if row.sub.DeletedAt == nil && !slices.Contains(tenants, row.tenantID) {
+			tenants = append(tenants, row.tenantID)
+		}
+	}
+
+	slices.Sort(tenants)
+
+	return tenants, nil
+}
+
+// месяц end_date оплачивается, поэтому подписка, закончившаяся в месяце from,
+// еще может иметь списание в периоде
+func (r *ReminderRepository) ListCandidates(
+	ctx context.Context,
+	from, to time.Time,
+	afterID uuid.UUID,
+	limit int,
+) ([]domain.Subscription, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.store.lock(ctx)()
+
+	var subs []domain.Subscription
+	for _, row := range r.store.data.subscriptions {
+		s := &row.sub
+		if row.tenantID != tenantID || s.DeletedAt != nil || s.StartDate.After(to) ||
+			(s.EndDate != nil && monthStart(*s.EndDate).Before(monthStart(from))) ||
+			bytes.Compare(s.ID[:], afterID[:]) <= 0 {
+			continue
+		}
+		subs = append(subs, r.store.data.read(row))
+	}
+
+	slices.SortFunc(subs, func(a, b domain.Subscription) int { return bytes.Compare(a.ID[:], b.ID[:]) })
+
+	if len(subs) > limit {
+		subs = subs[:limit]
+	}
+
+	return subs, nil
+}
+
+func (r *ReminderRepository) Claim(ctx context.Context, reminder *domain.Reminder, lease time.Duration) (bool, bool, error) {
+	defer r.store.lock(ctx)()
+
+	current := now()
+	key := reminderKey{reminder.SubscriptionID, timeKey(reminder.ChargeDate)}
+
+	row, exists := r.store.data.reminders[key]
+	if exists && (row.notifiedAt != nil || row.lockedUntil.After(current)) {
+		return false, false, nil
+	}
+
+	r.store.data.reminders[key] = reminderRow{tenantID: reminder.TenantID, lockedUntil: current.Add(lease)}
+
+	return true, !exists, nil
+}
+
+func (r *ReminderRepository) MarkNotified(ctx context.Context, reminder *domain.Reminder) error {
+	defer r.store.lock(ctx)()
+
+	key := reminderKey{reminder.SubscriptionID, timeKey(reminder.ChargeDate)}
+	if row, ok := r.store.data.reminders[key]; ok {
+		notifiedAt := now()
+		row.notifiedAt = &notifiedAt
+		row.lockedUntil = time.Time{}
+		r.store.data.reminders[key] = row
+	}
+
+	return nil
+}
+
+func (r *ReminderRepository) Release(ctx context.Context, reminder *domain.Reminder) error {
+	defer r.store.lock(ctx)()
+
+	key := reminderKey{reminder.SubscriptionID, timeKey(reminder.ChargeDate)}
+	if row, ok := r.store.data.reminders[key]; ok && row.notifiedAt == nil {
+		row.lockedUntil = time.Time{}
+		r.store.data.reminders[key] = row
+	}
+
+	return nil
+}
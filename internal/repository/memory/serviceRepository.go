@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testTask/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// ServiceRepository каталог общий для всех арендаторов, как и в Postgres
+type ServiceRepository struct {
+	store *Store
+}
+
+func NewServiceRepository(store *Store) *ServiceRepository {
+	return &ServiceRepository{store: store}
+}
+
+// копия сервиса с синонимами в алфавитном порядке
+func cloneService(s *domain.Service) domain.Service {
+	c := *s
+	c.Aliases = slices.Clone(s.Aliases)
+	slices.Sort(c.Aliases)
+	if c.Aliases == nil {
+		c.Aliases = []string{}
+	}
+
+	return c
+}
+
+// checkUnique названия и синонимы уникальны без учета регистра, как
+// уникальные индексы idx_services_name и idx_service_aliases_alias
+func (s *state) checkUnique(service *domain.Service) error {
+	for id, other := range s.services {
+		if id == service.ID {
+			continue
+		}
+
+		if strings.ToLower(other.Name) == strings.ToLower(service.Name) {
+			return domain.ErrServiceNameTaken
+		}
+
+		for _, alias := range service.Aliases {
+			if slices.ContainsFunc(other.Aliases, func(a string) bool { return strings.ToLower(a) == strings.ToLower(alias) }) {
+				return domain.ErrServiceNameTaken
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *ServiceRepository) Create(ctx context.Context, s *domain.Service) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.data.services[s.ID]; ok {
+		return fmt.Errorf("service %s already exists", s.ID)
+	}
+
+	if err := r.store.data.checkUnique(s); err != nil {
+		return err
+	}
+
+	r.store.data.services[s.ID] = cloneService(s)
+
+	return nil
+}
+
+func (r *ServiceRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Service, error) {
+	defer r.store.lock(ctx)()
+
+	s, ok := r.store.data.services[id]
+	if !ok {
+		return nil, domain.ErrServiceNotFound
+	}
+
+	result := cloneService(&s)
+	return &result, nil
+}
+
+func (r *ServiceRepository) FindByName(ctx context.Context, name string) (*domain.Service, error) {
+	defer r.store.lock(ctx)()
+
+	for _, s := range r.store.data.services {
+		if matchServiceName(&s, name) {
+			result := cloneService(&s)
+			return &result, nil
+		}
+	}
+
+	return nil, domain.ErrServiceNotFound
+}
+
+// синонимы заменяются целиком
+func (r *ServiceRepository) Update(ctx context.Context, s *domain.Service) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.data.services[s.ID]; !ok {
+		return domain.ErrServiceNotFound
+	}
+
+	if err := r.store.data.checkUnique(s); err != nil {
+		return err
+	}
+
+	r.store.data.services[s.ID] = cloneService(s)
+
+	return nil
+}
+
+// сервис, на который ссылаются подписки, в том числе удаленные, не удаляется
+func (r *ServiceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.data.services[id]; !ok {
+		return domain.ErrServiceNotFound
+	}
+
+	for _, row := range r.store.data.subscriptions {
+		if row.sub.ServiceID == id {
+			return domain.ErrServiceInUse
+		}
+	}
+
+	delete(r.store.data.services, id)
+
+	return nil
+}
+
+func (r *ServiceRepository) List(ctx context.Context, category *string) ([]domain.Service, error) {
+	defer r.store.lock(ctx)()
+
+	var services []domain.Service
+	for _, s := range r.store.data.services {
+		if category != nil && *category != "" && strings.ToLower(s.Category) != strings.ToLower(*category) {
+			continue
+		}
+		services = append(services, cloneService(&s))
+	}
+
+	slices.SortFunc(services, func(a, b domain.Service) int { return strings.Compare(a.Name, b.Name) })
+
+	return services, nil
+}
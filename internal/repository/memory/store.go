@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"testTask/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store данные всех репозиториев в памяти процесса. Репозитории одного Store
+// видят изменения друг друга, как таблицы одной БД; данные теряются при остановке
+type Store struct {
+	mu   sync.Mutex
+	data state
+}
+
+func NewStore() *Store {
+	return &Store{data: newState()}
+}
+
+type subscriptionRow struct {
+	tenantID string
+	sub      domain.Subscription
+}
+
+type priceKey struct {
+	subscriptionID uuid.UUID
+	effectiveFrom  time.Time
+}
+
+type auditRow struct {
+	tenantID string
+	entry    domain.AuditEntry
+}
+
+type outboxRow struct {
+	message       domain.OutboxMessage
+	publishedAt   *time.Time
+	nextAttemptAt time.Time
+	lastError     *string
+}
+
+type webhookRow struct {
+	tenantID string
+	webhook  domain.Webhook
+}
+
+type deliveryRow struct {
+	tenantID string
+	delivery domain.WebhookDelivery
+}
+
+type rateKey struct {
+	base  string
+	quote string
+}
+
+type reminderRow struct {
+	tenantID    string
+	notifiedAt  *time.Time
+	lockedUntil time.Time
+}
+
+type reminderKey struct {
+	subscriptionID uuid.UUID
+	chargeDate     time.Time
+}
+
+// timeKey время для ключа map: одинаковые моменты дают равные ключи
+// независимо от часового пояса и показаний монотонных часов
+func timeKey(t time.Time) time.Time {
+	return t.UTC().Round(0)
+}
+
+// state строки хранятся значениями и заменяются целиком, а вложенные срезы
+// не изменяются на месте, поэтому для снимка достаточно поверхностной копии
+type state struct {
+	subscriptions map[uuid.UUID]subscriptionRow
+	services      map[uuid.UUID]domain.Service
+	prices        map[priceKey]domain.PriceChange
+	rates         map[rateKey]domain.ExchangeRate
+	apiKeys       map[uuid.UUID]domain.APIKey
+	webhooks      map[uuid.UUID]webhookRow
+	reminders     map[reminderKey]reminderRow
+
+	audit      []auditRow
+	outbox     []outboxRow
+	deliveries []deliveryRow
+
+	// последние выданные ID, как у последовательностей BIGSERIAL
+	auditSeq    int64
+	outboxSeq   int64
+	deliverySeq int64
+}
+
+func newState() state {
+	return state{
+		subscriptions: make(map[uuid.UUID]subscriptionRow),
+		services:      make(map[uuid.UUID]domain.Service),
+		prices:        make(map[priceKey]domain.PriceChange),
+		rates:         make(map[rateKey]domain.ExchangeRate),
+		apiKeys:       make(map[uuid.UUID]domain.APIKey),
+		webhooks:      make(map[uuid.UUID]webhookRow),
+		reminders:     make(map[reminderKey]reminderRow),
+	}
+}
+
+func (s state) snapshot() state {
+	s.subscriptions = maps.Clone(s.subscriptions)
+	s.services = maps.Clone(s.services)
+	s.prices = maps.Clone(s.prices)
+	s.rates = maps.Clone(s.rates)
+	s.apiKeys = maps.Clone(s.apiKeys)
+	s.webhooks = maps.Clone(s.webhooks)
+	s.reminders = maps.Clone(s.reminders)
+	s.audit = slices.Clone(s.audit)
+	s.outbox = slices.Clone(s.outbox)
+	s.deliveries = slices.Clone(s.deliveries)
+
+	return s
+}
+
+type txKey struct{}
+
+// lock захватывает Store на время вызова репозитория. Внутри WithinTx Store
+// уже захвачен транзакцией, и вызов выполняется без повторной блокировки
+func (s *Store) lock(ctx context.Context) func() {
+	if tx, _ := ctx.Value(txKey{}).(*Store); tx == s {
+		return func() {}
+	}
+
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// now время записи с точностью до микросекунд, как у TIMESTAMP в Postgres
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// Transactor транзакции над Store. Транзакция держит Store до завершения,
+// поэтому транзакции и запросы вне их выполняются строго по очереди
+type Transactor struct {
+	store *Store
+}
+
+func NewTransactor(store *Store) *Transactor {
+	return &Transactor{store: store}
+}
+
+// WithinTx при ошибке fn возвращает данные к снимку, сделанному до нее.
+// Вложенный вызов выполняется в уже открытой транзакции
+func (t *Transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if tx, _ := ctx.Value(txKey{}).(*Store); tx == t.store {
+		return fn(ctx)
+	}
+
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	before := t.store.data.snapshot()
+
+	if err := fn(context.WithValue(ctx, txKey{}, t.store)); err != nil {
+		t.store.data = before
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,696 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"testTask/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SubscriptionRepository struct {
+	store *Store
+}
+
+func NewSubscriptionRepository(store *Store) *SubscriptionRepository {
+	return &SubscriptionRepository{store: store}
+}
+
+// Все методы ограничены арендатором клиента из контекста (domain.TenantFromContext),
+// как и в postgres.SubscriptionRepository
+func (r *SubscriptionRepository) Create(ctx context.Context, sub *domain.Subscription) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.data.subscriptions[sub.ID]; ok {
+		return fmt.Errorf("subscription %s already exists", sub.ID)
+	}
+
+	if _, ok := r.store.data.services[sub.ServiceID]; !ok {
+		return domain.NewValidationError("service_id", domain.ErrServiceNotFound.Error())
+	}
+
+	sub.CreatedAt = now()
+
+	stored := *sub
+	stored.Status = storedStatus(sub.Status)
+	stored.CancelAt = nil
+	stored.Pauses = nil
+	stored.DeletedAt = nil
+	r.store.data.subscriptions[sub.ID] = subscriptionRow{tenantID: tenantID, sub: stored}
+
+	return nil
+}
+
+// CreateBatch проверяет все подписки до записи, поэтому сохраняются все или ни одной
+func (r *SubscriptionRepository) CreateBatch(ctx context.Context, subs []domain.Subscription) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.store.lock(ctx)()
+
+	seen := make(map[uuid.UUID]bool, len(subs))
+	for i := range subs {
+		id := subs[i].ID
+		if _, ok := r.store.data.subscriptions[id]; ok || seen[id] {
+			return fmt.Errorf("subscription %s already exists", id)
+		}
+		seen[id] = true
+
+		if _, ok := r.store.data.services[subs[i].ServiceID]; !ok {
+			return domain.NewValidationError("service_id", domain.ErrServiceNotFound.Error())
+		}
+	}
+
+	createdAt := now()
+	for i := range subs {
+		subs[i].CreatedAt = createdAt
+
+		stored := subs[i]
+		stored.Status = storedStatus(stored.Status)
+		stored.CancelAt = nil
+		stored.Pauses = nil
+		stored.DeletedAt = nil
+		r.store.data.subscriptions[stored.ID] = subscriptionRow{tenantID: tenantID, sub: stored}
+	}
+
+	return nil
+}
+
+// пробный период - не хранимое состояние (см. domain.Subscription.StatusAt)
+func storedStatus(status domain.SubscriptionStatus) domain.SubscriptionStatus {
+	if status == domain.StatusTrial || status == "" {
+		return domain.StatusActive
+	}
+
+	return status
+}
+
+// read копия подписки для вызывающего: название сервиса из каталога
+// и состояние на текущий момент
+func (s *state) read(row subscriptionRow) domain.Subscription {
+	sub := row.sub
+	sub.ServiceName = s.services[sub.ServiceID].Name
+	sub.Status = sub.StatusAt(time.Now())
+	sub.Pauses = slices.Clone(sub.Pauses)
+	if sub.Pauses == nil {
+		sub.Pauses = []domain.Pause{}
+	}
+
+	return sub
+}
+
+// find подписка арендатора; deleted - искать среди удаленных
+func (s *state) find(tenantID string, id uuid.UUID, deleted bool) (subscriptionRow, bool) {
+	row, ok := s.subscriptions[id]
+	if !ok || row.tenantID != tenantID || (row.sub.DeletedAt != nil) != deleted {
+		return subscriptionRow{}, false
+	}
+
+	return row, true
+}
+
+func (r *SubscriptionRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	return r.get(ctx, id, false)
+}
+
+func (r *SubscriptionRepository) GetDeleted(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	return r.get(ctx, id, true)
+}
+
+func (r *SubscriptionRepository) get(ctx context.Context, id uuid.UUID, deleted bool) (*domain.Subscription, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.store.lock(ctx)()
+
+	row, ok := r.store.data.find(tenantID, id, deleted)
+	if !ok {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+
+	sub := r.store.data.read(row)
+	return &sub, nil
+}
+
+func (r *SubscriptionRepository) Update(ctx context.Context, sub *domain.Subscription) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.store.lock(ctx)()
+
+	row, ok := r.store.data.find(tenantID, sub.ID, false)
+	if !ok {
+		return domain.ErrSubscriptionNotFound
+	}
+
+	if _, ok := r.store.data.services[sub.ServiceID]; !ok {
+		return domain.NewValidationError("service_id", domain.ErrServiceNotFound.Error())
+	}
+
+	row.sub.ServiceID = sub.ServiceID
+	row.sub.Price = sub.Price
+	row.sub.Currency = sub.Currency
+	row.sub.BillingPeriod = sub.BillingPeriod
+	row.sub.StartDate = sub.StartDate
+	row.sub.EndDate = sub.EndDate
+	row.sub.TrialUntil = sub.TrialUntil
+	r.store.data.subscriptions[sub.ID] = row
+
+	return nil
+}
+
+// UpdateStatus как и postgres.SubscriptionRepository, сохраняет из пауз только последнюю
+func (r *SubscriptionRepository) UpdateStatus(ctx context.Context, sub *domain.Subscription) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.store.lock(ctx)()
+
+	row, ok := r.store.data.find(tenantID, sub.ID, false)
+	if !ok {
+		return domain.ErrSubscriptionNotFound
+	}
+
+	row.sub.Status = storedStatus(sub.Status)
+	row.sub.CancelAt = sub.CancelAt
+	row.sub.EndDate = sub.EndDate
+
+	if n := len(sub.Pauses); n > 0 {
+		pause := sub.Pauses[n-1]
+		pauses := slices.Clone(row.sub.Pauses)
+
+		i := slices.IndexFunc(pauses, func(p domain.Pause) bool { return p.From.Equal(pause.From) })
+		if i >= 0 {
+			pauses[i].Until = pause.Until
+		} else {
+			pauses = append(pauses, pause)
+			slices.SortFunc(pauses, func(a, b domain.Pause) int { return a.From.Compare(b.From) })
+		}
+		row.sub.Pauses = pauses
+	}
+
+	r.store.data.subscriptions[sub.ID] = row
+
+	return nil
+}
+
+func (r *SubscriptionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.store.lock(ctx)()
+
+	row, ok := r.store.data.find(tenantID, id, false)
+	if !ok {
+		return domain.ErrSubscriptionNotFound
+	}
+
+	deletedAt := now()
+	row.sub.DeletedAt = &deletedAt
+	r.store.data.subscriptions[id] = row
+
+	return nil
+}
+
+func (r *SubscriptionRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.store.lock(ctx)()
+
+	row, ok := r.store.data.find(tenantID, id, true)
+	if !ok {
+		return domain.ErrSubscriptionNotFound
+	}
+
+	row.sub.DeletedAt = nil
+	r.store.data.subscriptions[id] = row
+
+	return nil
+}
+
+// вместе с подписками удаляются их история цен и отметки напоминаний
+func (r *SubscriptionRepository) Purge(ctx context.Context, before time.Time) ([]domain.Subscription, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.store.lock(ctx)()
+
+	data := &r.store.data
+
+	var purged []domain.Subscription
+	for id, row := range data.subscriptions {
+		if row.tenantID != tenantID || row.sub.DeletedAt == nil || !row.sub.DeletedAt.Before(before) {
+			continue
+		}
+
+		purged = append(purged, data.read(row))
+		delete(data.subscriptions, id)
+
+		for key := range data.prices {
+			if key.subscriptionID == id {
+				delete(data.prices, key)
+			}
+		}
+		for key := range data.reminders {
+			if key.subscriptionID == id {
+				delete(data.reminders, key)
+			}
+		}
+	}
+
+	slices.SortFunc(purged, func(a, b domain.Subscription) int {
+		return cmp.Or(a.DeletedAt.Compare(*b.DeletedAt), bytes.Compare(a.ID[:], b.ID[:]))
+	})
+
+	return purged, nil
+}
+
+// listKey значение поля сортировки; заполнено только поле выбранной сортировки
+type listKey struct {
+	date  time.Time
+	price int
+	name  string
+}
+
+func compareListKeys(a, b listKey) int {
+	return cmp.Or(a.date.Compare(b.date), cmp.Compare(a.price, b.price), strings.Compare(a.name, b.name))
+}
+
+func sortKey(sort domain.ListSort, sub *domain.Subscription) listKey {
+	switch sort {
+	case domain.SortByStartDate:
+		return listKey{date: sub.StartDate}
+	case domain.SortByPrice:
+		return listKey{price: sub.Price}
+	case domain.SortByServiceName:
+		return listKey{name: sub.ServiceName}
+	default:
+		return listKey{date: sub.CreatedAt}
+	}
+}
+
+// разбирает значение курсора в формате domain.ListFilter.CursorAfter
+func cursorKey(sort domain.ListSort, value string) (listKey, error) {
+	var (
+		key listKey
+		err error
+	)
+
+	switch sort {
+	case domain.SortByStartDate:
+		key.date, err = time.Parse(time.DateOnly, value)
+	case domain.SortByPrice:
+		key.price, err = strconv.Atoi(value)
+	case domain.SortByServiceName:
+		key.name = value
+	case domain.SortByCreatedAt:
+		key.date, err = time.Parse(time.RFC3339Nano, value)
+	}
+
+	return key, err
+}
+
+func (r *SubscriptionRepository) List(
+	ctx context.Context,
+	filter *domain.ListFilter,
+) ([]domain.Subscription, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch filter.Sort {
+	case domain.SortByStartDate, domain.SortByPrice, domain.SortByServiceName, domain.SortByCreatedAt:
+	default:
+		return nil, fmt.Errorf("unsupported sort value %q", filter.Sort)
+	}
+
+	var after *listKey
+	if filter.Cursor != nil {
+		key, err := cursorKey(filter.Sort, filter.Cursor.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor value: %w", err)
+		}
+		after = &key
+	}
+
+	defer r.store.lock(ctx)()
+
+	data := &r.store.data
+
+	// keyset-пагинация: id - второй ключ сортировки, чтобы порядок был однозначным
+	compare := func(a, b *domain.Subscription) int {
+		return cmp.Or(compareListKeys(sortKey(filter.Sort, a), sortKey(filter.Sort, b)), bytes.Compare(a.ID[:], b.ID[:]))
+	}
+
+	var subs []domain.Subscription
+
+	for _, row := range data.subscriptions {
+		if row.tenantID != tenantID || !data.matchList(&row.sub, filter) {
+			continue
+		}
+
+		sub := data.read(row)
+
+		if after != nil {
+			c := cmp.Or(compareListKeys(sortKey(filter.Sort, &sub), *after), bytes.Compare(sub.ID[:], filter.Cursor.ID[:]))
+			if (filter.Desc && c >= 0) || (!filter.Desc && c <= 0) {
+				continue
+			}
+		}
+
+		subs = append(subs, sub)
+	}
+
+	slices.SortFunc(subs, func(a, b domain.Subscription) int {
+		if filter.Desc {
+			return compare(&b, &a)
+		}
+		return compare(&a, &b)
+	})
+
+	if len(subs) > filter.Limit {
+		subs = subs[:filter.Limit]
+	}
+
+	return subs, nil
+}
+
+// matchList условия фильтра списка, как в postgres.listFilterConditions
+func (s *state) matchList(sub *domain.Subscription, filter *domain.ListFilter) bool {
+	if sub.DeletedAt != nil && !filter.IncludeDeleted {
+		return false
+	}
+
+	if !matchOwner(sub, filter.OwnerID, filter.UserID) {
+		return false
+	}
+
+	service := s.services[sub.ServiceID]
+
+	if filter.ServiceName != nil && *filter.ServiceName != "" && !matchServiceName(&service, *filter.ServiceName) {
+		return false
+	}
+
+	if filter.Search != "" && !matchServiceSearch(&service, filter.Search) {
+		return false
+	}
+
+	if filter.ActiveOn != nil && !activeInMonth(sub, *filter.ActiveOn) {
+		return false
+	}
+
+	switch {
+	case filter.StartFrom != nil && sub.StartDate.Before(*filter.StartFrom),
+		filter.StartTo != nil && sub.StartDate.After(*filter.StartTo),
+		filter.EndFrom != nil && (sub.EndDate == nil || sub.EndDate.Before(*filter.EndFrom)),
+		filter.EndTo != nil && (sub.EndDate == nil || sub.EndDate.After(*filter.EndTo)),
+		filter.PriceMin != nil && sub.Price < *filter.PriceMin,
+		filter.PriceMax != nil && sub.Price > *filter.PriceMax,
+		filter.OpenEnded != nil && *filter.OpenEnded != (sub.EndDate == nil):
+		return false
+	}
+
+	return true
+}
+
+func matchOwner(sub *domain.Subscription, ownerID, userID *uuid.UUID) bool {
+	return (ownerID == nil || sub.UserID == *ownerID) && (userID == nil || sub.UserID == *userID)
+}
+
+// название совпадает с названием в каталоге или с одним из синонимов без учета регистра
+func matchServiceName(service *domain.Service, name string) bool {
+	name = strings.ToLower(name)
+
+	if strings.ToLower(service.Name) == name {
+		return true
+	}
+
+	return slices.ContainsFunc(service.Aliases, func(alias string) bool { return strings.ToLower(alias) == name })
+}
+
+// подстрока названия или синонима без учета регистра
+func matchServiceSearch(service *domain.Service, search string) bool {
+	search = strings.ToLower(search)
+
+	if strings.Contains(strings.ToLower(service.Name), search) {
+		return true
+	}
+
+	return slices.ContainsFunc(service.Aliases, func(alias string) bool {
+		return strings.Contains(strings.ToLower(alias), search)
+	})
+}
+
+// подписка действует в месяце month: месяцы начала и окончания включительно
+func activeInMonth(sub *domain.Subscription, month time.Time) bool {
+	month = monthStart(month)
+
+	if monthStart(sub.StartDate).After(month) {
+		return false
+	}
+
+	return sub.EndDate == nil || !monthStart(*sub.EndDate).Before(month)
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// charged подписка с историей цен, участвующая в подсчете сумм
+type charged struct {
+	sub     domain.Subscription
+	changes []domain.PriceChange
+}
+
+// chargedSubscriptions неудаленные подписки арендатора, подходящие под фильтр сумм
+// (см. postgres.totalFilterConditions)
+func (s *state) chargedSubscriptions(tenantID string, filter *domain.TotalFilter) []charged {
+	var result []charged
+
+	for _, row := range s.subscriptions {
+		if row.tenantID != tenantID || row.sub.DeletedAt != nil || !matchOwner(&row.sub, filter.OwnerID, filter.UserID) {
+			continue
+		}
+
+		service := s.services[row.sub.ServiceID]
+		if filter.ServiceName != nil && *filter.ServiceName != "" && !matchServiceName(&service, *filter.ServiceName) {
+			continue
+		}
+
+		result = append(result, charged{sub: s.read(row), changes: s.priceChanges(row.sub.ID)})
+	}
+
+	return result
+}
+
+// cost сумма выставляемых списаний в периоде [from, to] по месяцам включительно.
+// Подписка участвует, если начинается не позже to и заканчивается не раньше from
+func (c *charged) cost(from, to time.Time) (int, bool) {
+	if c.sub.StartDate.After(to) || (c.sub.EndDate != nil && c.sub.EndDate.Before(from)) {
+		return 0, false
+	}
+
+	charges := c.sub.ChargeDates(from, to)
+	if len(charges) == 0 {
+		return 0, false
+	}
+
+	var total int
+	for _, charge := range charges {
+		total += c.sub.PriceAt(c.changes, charge)
+	}
+
+	return total, true
+}
+
+func (r *SubscriptionRepository) CalculateTotal(
+	ctx context.Context,
+	filter *domain.TotalFilter,
+) ([]domain.Money, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.store.lock(ctx)()
+
+	sums := make(map[string]int)
+	for _, c := range r.store.data.chargedSubscriptions(tenantID, filter) {
+		if amount, ok := c.cost(filter.From, filter.To); ok {
+			sums[c.sub.Currency] += amount
+		}
+	}
+
+	var totals []domain.Money
+	for _, currency := range slices.Sorted(maps.Keys(sums)) {
+		totals = append(totals, domain.Money{Amount: sums[currency], Currency: currency})
+	}
+
+	return totals, nil
+}
+
+func (r *SubscriptionRepository) CalculateGroupedTotal(
+	ctx context.Context,
+	filter *domain.TotalFilter,
+) ([]domain.GroupedTotal, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(filter.GroupBy) == 0 {
+		return nil, errors.New("group_by is required")
+	}
+
+	var byService, byUser bool
+	for _, g := range filter.GroupBy {
+		switch g {
+		case domain.GroupByServiceName:
+			byService = true
+		case domain.GroupByUserID:
+			byUser = true
+		default:
+			return nil, fmt.Errorf("unsupported group_by value %q", g)
+		}
+	}
+
+	defer r.store.lock(ctx)()
+
+	type groupKey struct {
+		serviceName string
+		userID      uuid.UUID
+		currency    string
+	}
+
+	sums := make(map[groupKey]int)
+	for _, c := range r.store.data.chargedSubscriptions(tenantID, filter) {
+		amount, ok := c.cost(filter.From, filter.To)
+		if !ok {
+			continue
+		}
+
+		key := groupKey{currency: c.sub.Currency}
+		if byService {
+			key.serviceName = c.sub.ServiceName
+		}
+		if byUser {
+			key.userID = c.sub.UserID
+		}
+		sums[key] += amount
+	}
+
+	result := make([]domain.GroupedTotal, 0, len(sums))
+	for key, total := range sums {
+		gt := domain.GroupedTotal{Total: total, Currency: key.currency}
+		if byService {
+			gt.ServiceName = &key.serviceName
+		}
+		if byUser {
+			gt.UserID = &key.userID
+		}
+		result = append(result, gt)
+	}
+
+	// порядок колонок группировки, как в GROUP BY postgres.SubscriptionRepository
+	slices.SortFunc(result, func(a, b domain.GroupedTotal) int {
+		for _, g := range filter.GroupBy {
+			var c int
+			switch g {
+			case domain.GroupByServiceName:
+				c = strings.Compare(*a.ServiceName, *b.ServiceName)
+			case domain.GroupByUserID:
+				c = bytes.Compare(a.UserID[:], b.UserID[:])
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(a.Currency, b.Currency)
+	})
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+
+	return result, nil
+}
+
+// CalculateMonthlyBreakdown месяцы без активных подписок попадают в результат
+// с нулевой суммой и пустой валютой; подписка считается активной в месяце,
+// даже если списания в нём нет. Для каждой валюты в месяце - отдельная строка
+func (r *SubscriptionRepository) CalculateMonthlyBreakdown(
+	ctx context.Context,
+	filter *domain.TotalFilter,
+) ([]domain.MonthlyTotal, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.store.lock(ctx)()
+
+	subs := r.store.data.chargedSubscriptions(tenantID, filter)
+
+	var result []domain.MonthlyTotal
+
+	for month := monthStart(filter.From); !month.After(monthStart(filter.To)); month = month.AddDate(0, 1, 0) {
+		byCurrency := make(map[string]*domain.MonthlyTotal)
+
+		for _, c := range subs {
+			if !activeInMonth(&c.sub, month) {
+				continue
+			}
+
+			mt, ok := byCurrency[c.sub.Currency]
+			if !ok {
+				mt = &domain.MonthlyTotal{Month: month, Currency: c.sub.Currency}
+				byCurrency[c.sub.Currency] = mt
+			}
+
+			mt.Subscriptions++
+			for _, charge := range c.sub.ChargeDates(month, month) {
+				mt.Amount += c.sub.PriceAt(c.changes, charge)
+			}
+		}
+
+		if len(byCurrency) == 0 {
+			result = append(result, domain.MonthlyTotal{Month: month})
+			continue
+		}
+
+		for _, currency := range slices.Sorted(maps.Keys(byCurrency)) {
+			result = append(result, *byCurrency[currency])
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,18 @@
+package memory
+
+import (
+	"testTask/internal/repository/repositorytest"
+	"testing"
+)
+
+func TestSubscriptionRepository(t *testing.T) {
+	repositorytest.RunSubscriptionRepository(t, func(t *testing.T) repositorytest.Backend {
+		store := NewStore()
+
+		return repositorytest.Backend{
+			Subscriptions: NewSubscriptionRepository(store),
+			Services:      NewServiceRepository(store),
+			Prices:        NewPriceHistoryRepository(store),
+		}
+	})
+}
@@ -0,0 +1,370 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testTask/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookRepository struct {
+	store *Store
+}
+
+func NewWebhookRepository(store *Store) *WebhookRepository {
+	return &WebhookRepository{store: store}
+}
+
+func cloneWebhook(w *domain.Webhook) domain.Webhook {
+	c := *w
+	c.EventTypes = slices.Clone(w.EventTypes)
+
+	return c
+}
+
+// Все методы ограничены арендатором клиента из контекста
+func (r *WebhookRepository) Create(ctx context.Context, w *domain.Webhook) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.data.webhooks[w.ID]; ok {
+		return fmt.Errorf("webhook %s already exists", w.ID)
+	}
+
+	w.CreatedAt = now()
+	w.UpdatedAt = w.CreatedAt
+	r.store.data.webhooks[w.ID] = webhookRow{tenantID: tenantID, webhook: cloneWebhook(w)}
+
+	return nil
+}
+
+func (r *WebhookRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.store.lock(ctx)()
+
+	row, ok := r.store.data.webhooks[id]
+	if !ok || row.tenantID != tenantID {
+		return nil, domain.ErrWebhookNotFound
+	}
+
+	w := cloneWebhook(&row.webhook)
+	return &w, nil
+}
+
+func (r *WebhookRepository) List(ctx context.Context, ownerID *uuid.UUID) ([]domain.Webhook, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.store.lock(ctx)()
+
+	var webhooks []domain.Webhook
+	for _, row := range r.store.data.webhooks {
+		if row.tenantID != tenantID {
+			continue
+		}
+		// вебхуки администратора (без владельца) в список пользователя не попадают
+		if ownerID != nil && (row.webhook.OwnerID == nil || *row.webhook.OwnerID != *ownerID) {
+			continue
+		}
+		webhooks = append(webhooks, cloneWebhook(&row.webhook))
+	}
+
+	slices.SortFunc(webhooks, func(a, b domain.Webhook) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), bytes.Compare(a.ID[:], b.ID[:]))
+	})
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, w *domain.Webhook) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.store.lock(ctx)()
+
+	row, ok := r.store.data.webhooks[w.ID]
+	if !ok || row.tenantID != tenantID {
+		return domain.ErrWebhookNotFound
+	}
+
+	w.UpdatedAt = now()
+
+	row.webhook.URL = w.URL
+	row.webhook.Secret = w.Secret
+	row.webhook.EventTypes = slices.Clone(w.EventTypes)
+	row.webhook.Active = w.Active
+	row.webhook.UpdatedAt = w.UpdatedAt
+	r.store.data.webhooks[w.ID] = row
+
+	return nil
+}
+
+// журнал доставок удаляется вместе с вебхуком
+func (r *WebhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.store.lock(ctx)()
+
+	row, ok := r.store.data.webhooks[id]
+	if !ok || row.tenantID != tenantID {
+		return domain.ErrWebhookNotFound
+	}
+
+	delete(r.store.data.webhooks, id)
+	r.store.data.deliveries = slices.DeleteFunc(r.store.data.deliveries, func(d deliveryRow) bool {
+		return d.delivery.WebhookID == id
+	})
+
+	return nil
+}
+
+type WebhookDeliveryRepository struct {
+	store *Store
+}
+
+func NewWebhookDeliveryRepository(store *Store) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{store: store}
+}
+
+func (r *WebhookDeliveryRepository) Enqueue(ctx context.Context, event *domain.Event, ownerID uuid.UUID) error {
+	return r.EnqueueBatch(ctx, []domain.WebhookEvent{{Event: event, OwnerID: ownerID}})
+}
+
+// событие доставляется на вебхук один раз, повторная постановка в очередь игнорируется
+func (r *WebhookDeliveryRepository) EnqueueBatch(ctx context.Context, events []domain.WebhookEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	payloads := make([]json.RawMessage, len(events))
+	for i, e := range events {
+		payload, err := json.Marshal(e.Event)
+		if err != nil {
+			return err
+		}
+		payloads[i] = payload
+	}
+
+	defer r.store.lock(ctx)()
+
+	data := &r.store.data
+	tenantID := events[0].Event.TenantID
+
+	var webhooks []domain.Webhook
+	for _, row := range data.webhooks {
+		if row.tenantID == tenantID && row.webhook.Active {
+			webhooks = append(webhooks, row.webhook)
+		}
+	}
+
+	// порядок постановки в очередь не зависит от порядка обхода map
+	slices.SortFunc(webhooks, func(a, b domain.Webhook) int { return bytes.Compare(a.ID[:], b.ID[:]) })
+
+	for i, e := range events {
+		for _, w := range webhooks {
+			if !slices.Contains(w.EventTypes, e.Event.Type) || (w.OwnerID != nil && *w.OwnerID != e.OwnerID) {
+				continue
+			}
+
+			queued := slices.ContainsFunc(data.deliveries, func(d deliveryRow) bool {
+				return d.delivery.WebhookID == w.ID && d.delivery.EventID == e.Event.ID
+			})
+			if queued {
+				continue
+			}
+
+			createdAt := now()
+			data.deliverySeq++
+			data.deliveries = append(data.deliveries, deliveryRow{
+				tenantID: tenantID,
+				delivery: domain.WebhookDelivery{
+					ID:            data.deliverySeq,
+					WebhookID:     w.ID,
+					EventID:       e.Event.ID,
+					EventType:     e.Event.Type,
+					Payload:       payloads[i],
+					Status:        domain.DeliveryPending,
+					NextAttemptAt: &createdAt,
+					CreatedAt:     createdAt,
+				},
+			})
+		}
+	}
+
+	return nil
+}
+
+func (r *WebhookDeliveryRepository) List(
+	ctx context.Context,
+	webhookID uuid.UUID,
+	filter *domain.DeliveryFilter,
+) ([]domain.WebhookDelivery, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.store.lock(ctx)()
+
+	var deliveries []domain.WebhookDelivery
+
+	for i := len(r.store.data.deliveries) - 1; i >= 0 && len(deliveries) < filter.Limit; i-- {
+		row := r.store.data.deliveries[i]
+		d := row.delivery
+
+		switch {
+		case row.tenantID != tenantID,
+			d.WebhookID != webhookID,
+			filter.Status != "" && d.Status != filter.Status,
+			filter.BeforeID != nil && d.ID >= *filter.BeforeID:
+			continue
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookDeliveryRepository) GetByID(ctx context.Context, webhookID uuid.UUID, id int64) (*domain.WebhookDelivery, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.store.lock(ctx)()
+
+	row := r.store.data.deliveryRow(id)
+	if row == nil || row.tenantID != tenantID || row.delivery.WebhookID != webhookID {
+		return nil, domain.ErrWebhookDeliveryNotFound
+	}
+
+	d := row.delivery
+	return &d, nil
+}
+
+func (r *WebhookDeliveryRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookJob, error) {
+	defer r.store.lock(ctx)()
+
+	current := now()
+
+	var due []*deliveryRow
+	for i := range r.store.data.deliveries {
+		d := &r.store.data.deliveries[i].delivery
+		if d.Status == domain.DeliveryPending && d.NextAttemptAt != nil && !d.NextAttemptAt.After(current) {
+			due = append(due, &r.store.data.deliveries[i])
+		}
+	}
+
+	slices.SortFunc(due, func(a, b *deliveryRow) int {
+		return cmp.Or(a.delivery.NextAttemptAt.Compare(*b.delivery.NextAttemptAt), cmp.Compare(a.delivery.ID, b.delivery.ID))
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	next := current.Add(lease)
+	jobs := make([]domain.WebhookJob, 0, len(due))
+	for _, row := range due {
+		row.delivery.NextAttemptAt = &next
+		w := r.store.data.webhooks[row.delivery.WebhookID].webhook
+		jobs = append(jobs, domain.WebhookJob{Delivery: row.delivery, URL: w.URL, Secret: w.Secret})
+	}
+
+	slices.SortFunc(jobs, func(a, b domain.WebhookJob) int {
+		return cmp.Compare(a.Delivery.ID, b.Delivery.ID)
+	})
+
+	return jobs, nil
+}
+
+func (r *WebhookDeliveryRepository) RecordAttempt(ctx context.Context, id int64, a *domain.DeliveryAttempt) error {
+	defer r.store.lock(ctx)()
+
+	row := r.store.data.deliveryRow(id)
+	if row == nil {
+		return nil
+	}
+
+	d := &row.delivery
+	current := now()
+
+	d.Status = a.Status
+	d.Attempts++
+	d.LastStatusCode = nil
+	if a.StatusCode != 0 {
+		d.LastStatusCode = &a.StatusCode
+	}
+	d.LastError = nil
+	if a.Error != "" {
+		d.LastError = &a.Error
+	}
+
+	d.NextAttemptAt = nil
+	if a.Status == domain.DeliveryPending {
+		next := current.Add(a.RetryAfter)
+		d.NextAttemptAt = &next
+	}
+
+	d.DeliveredAt = nil
+	if a.Status == domain.DeliveryDelivered {
+		d.DeliveredAt = &current
+	}
+
+	return nil
+}
+
+func (r *WebhookDeliveryRepository) Requeue(ctx context.Context, id int64) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.store.lock(ctx)()
+
+	row := r.store.data.deliveryRow(id)
+	if row == nil || row.tenantID != tenantID {
+		return domain.ErrWebhookDeliveryNotFound
+	}
+
+	next := now()
+	row.delivery.Status = domain.DeliveryPending
+	row.delivery.Attempts = 0
+	row.delivery.NextAttemptAt = &next
+
+	return nil
+}
+
+// deliveryRow строка доставки для изменения на месте; nil - доставки нет
+func (s *state) deliveryRow(id int64) *deliveryRow {
+	i, ok := slices.BinarySearchFunc(s.deliveries, id, func(d deliveryRow, id int64) int {
+		return cmp.Compare(d.delivery.ID, id)
+	})
+	if !ok {
+		return nil
+	}
+
+	return &s.deliveries[i]
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"testTask/internal/domain"
+	"time"
+)
+
+type OutboxRepository interface {
+	// сохраняет событие; вызывается в транзакции изменения, которое оно описывает
+	Add(ctx context.Context, event *domain.Event) error
+	// сохраняет события пакетом в порядке events
+	AddBatch(ctx context.Context, events []domain.Event) error
+	// возвращает до limit событий, готовых к публикации, в порядке сохранения,
+	// и откладывает их следующую попытку на lease: пока событие публикуется,
+	// параллельные процессы его не берут. Вызывается вне транзакции, чтобы
+	// публикация не держала блокировки
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error)
+	MarkPublished(ctx context.Context, id int64) error
+	// откладывает следующую попытку публикации на retryAfter
+	MarkFailed(ctx context.Context, id int64, reason string, retryAfter time.Duration) error
+}
@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testTask/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type APIKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+const apiKeyColumns = `id, name, prefix, key_hash, admin, gateway, tenant_id, created_at, expires_at, revoked_at, last_used_at`
+
+func scanAPIKey(row pgx.Row) (*domain.APIKey, error) {
+	var k domain.APIKey
+
+	err := row.Scan(
+		&k.ID,
+		&k.Name,
+		&k.Prefix,
+		&k.Hash,
+		&k.Admin,
+		&k.Gateway,
+		&k.TenantID,
+		&k.CreatedAt,
+		&k.ExpiresAt,
+		&k.RevokedAt,
+		&k.LastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &k, nil
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	query := `
+		INSERT INTO api_keys (id, name, prefix, key_hash, admin, gateway, tenant_id, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`
+
+	return r.db.QueryRow(ctx, query,
+		key.ID,
+		key.Name,
+		key.Prefix,
+		key.Hash,
+		key.Admin,
+		key.Gateway,
+		key.TenantID,
+		key.ExpiresAt,
+	).Scan(&key.CreatedAt)
+}
+
+func (r *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE prefix = $1`
+
+	k, err := scanAPIKey(r.db.QueryRow(ctx, query, prefix))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+
+		return nil, err
+	}
+
+	return k, nil
+}
+
+func (r *APIKeyRepository) List(ctx context.Context, tenantID *string) ([]domain.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + ` FROM api_keys
+		WHERE $1::text IS NULL OR tenant_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []domain.APIKey
+
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *k)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, id uuid.UUID, tenantID *string) error {
+	// повторный отзыв не меняет время первого
+	query := `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1 AND ($2::text IS NULL OR tenant_id = $2)
+	`
+
+	cmd, err := r.db.Exec(ctx, query, id, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// время последнего использования обновляется не чаще раза в минуту,
+// чтобы не писать в БД на каждый запрос
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE api_keys SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`
+
+	_, err := r.db.Exec(ctx, query, id)
+	return err
+}
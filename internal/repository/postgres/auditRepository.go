@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testTask/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AuditRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditRepository(db *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Записи создаются в транзакции изменения подписки из контекста (Transactor.WithinTx)
+func (r *AuditRepository) Create(ctx context.Context, e *domain.AuditEntry) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO subscription_audit
+		(tenant_id, subscription_id, owner_id, action, actor, actor_type, actor_user_id, state_before, state_after, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at
+	`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		tenantID,
+		e.SubscriptionID,
+		e.OwnerID,
+		e.Action,
+		e.Actor,
+		e.ActorType,
+		e.ActorUserID,
+		e.Before,
+		e.After,
+		e.RequestID,
+	).Scan(&e.ID, &e.CreatedAt)
+}
+
+var auditColumns = []string{
+	"tenant_id", "subscription_id", "owner_id", "action", "actor", "actor_type",
+	"actor_user_id", "state_before", "state_after", "request_id",
+}
+
+func (r *AuditRepository) CreateBatch(ctx context.Context, entries []domain.AuditEntry) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]any, 0, len(entries))
+	for i := range entries {
+		e := &entries[i]
+		rows = append(rows, []any{
+			tenantID,
+			e.SubscriptionID,
+			e.OwnerID,
+			e.Action,
+			e.Actor,
+			e.ActorType,
+			e.ActorUserID,
+			e.Before,
+			e.After,
+			e.RequestID,
+		})
+	}
+
+	return insertValues(ctx, conn(ctx, r.db), "subscription_audit", auditColumns, rows, "")
+}
+
+func (r *AuditRepository) List(ctx context.Context, filter *domain.AuditFilter) ([]domain.AuditEntry, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, subscription_id, owner_id, action, actor, actor_type, actor_user_id,
+		       state_before, state_after, request_id, created_at
+		FROM subscription_audit
+		WHERE tenant_id = $1
+	`
+
+	args := []interface{}{tenantID}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		query += " AND " + strings.ReplaceAll(condition, "$?", fmt.Sprintf("$%d", len(args)))
+	}
+
+	if filter.SubscriptionID != nil {
+		add("subscription_id = $?", *filter.SubscriptionID)
+	}
+
+	if filter.OwnerID != nil {
+		add("owner_id = $?", *filter.OwnerID)
+	}
+
+	if filter.Actor != "" {
+		add("actor = $?", filter.Actor)
+	}
+
+	if filter.Action != "" {
+		add("action = $?", filter.Action)
+	}
+
+	if filter.From != nil {
+		add("created_at >= $?", *filter.From)
+	}
+
+	if filter.To != nil {
+		add("created_at <= $?", *filter.To)
+	}
+
+	if filter.BeforeID != nil {
+		add("id < $?", *filter.BeforeID)
+	}
+
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args))
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []domain.AuditEntry
+
+	for rows.Next() {
+		var e domain.AuditEntry
+		err = rows.Scan(
+			&e.ID,
+			&e.SubscriptionID,
+			&e.OwnerID,
+			&e.Action,
+			&e.Actor,
+			&e.ActorType,
+			&e.ActorUserID,
+			&e.Before,
+			&e.After,
+			&e.RequestID,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// предел числа параметров одного запроса в протоколе Postgres
+const maxQueryParams = 65535
+
+// insertValues вставляет rows многострочными INSERT ... VALUES, по одному запросу
+// на каждые maxQueryParams параметров. В отличие от CopyFrom работает и с таблицами
+// под RLS. suffix дописывается к каждому запросу, например ON CONFLICT
+func insertValues(ctx context.Context, q querier, table string, columns []string, rows [][]any, suffix string) error {
+	for chunk := range slices.Chunk(rows, maxQueryParams/len(columns)) {
+		var query strings.Builder
+		args := make([]any, 0, len(chunk)*len(columns))
+
+		query.WriteString("INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES ")
+		for i, row := range chunk {
+			if i > 0 {
+				query.WriteString(", ")
+			}
+
+			query.WriteByte('(')
+			for j, value := range row {
+				if j > 0 {
+					query.WriteString(", ")
+				}
+				args = append(args, value)
+				query.WriteString("$" + strconv.Itoa(len(args)))
+			}
+			query.WriteByte(')')
+		}
+		query.WriteString(" " + suffix)
+
+		if _, err := q.Exec(ctx, query.String(), args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
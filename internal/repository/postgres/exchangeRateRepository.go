@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testTask/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ExchangeRateRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewExchangeRateRepository(db *pgxpool.Pool) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+func (r *ExchangeRateRepository) Upsert(ctx context.Context, rate *domain.ExchangeRate) error {
+	query := `
+		INSERT INTO currency_rates (base_currency, quote_currency, rate)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (base_currency, quote_currency)
+		DO UPDATE SET rate = EXCLUDED.rate, updated_at = NOW()
+		RETURNING updated_at
+	`
+
+	return r.db.QueryRow(ctx, query,
+		rate.BaseCurrency,
+		rate.QuoteCurrency,
+		rate.Rate,
+	).Scan(&rate.UpdatedAt)
+}
+
+func (r *ExchangeRateRepository) Get(ctx context.Context, base, quote string) (*domain.ExchangeRate, error) {
+	query := `
+		SELECT base_currency, quote_currency, rate, updated_at
+		FROM currency_rates
+		WHERE base_currency = $1 AND quote_currency = $2
+	`
+
+	var rate domain.ExchangeRate
+
+	err := r.db.QueryRow(ctx, query, base, quote).Scan(
+		&rate.BaseCurrency,
+		&rate.QuoteCurrency,
+		&rate.Rate,
+		&rate.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrExchangeRateNotFound
+		}
+
+		return nil, err
+	}
+
+	return &rate, nil
+}
+
+func (r *ExchangeRateRepository) List(ctx context.Context) ([]domain.ExchangeRate, error) {
+	query := `
+		SELECT base_currency, quote_currency, rate, updated_at
+		FROM currency_rates
+		ORDER BY base_currency, quote_currency
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []domain.ExchangeRate
+
+	for rows.Next() {
+		var rate domain.ExchangeRate
+		err = rows.Scan(
+			&rate.BaseCurrency,
+			&rate.QuoteCurrency,
+			&rate.Rate,
+			&rate.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+func (r *ExchangeRateRepository) Delete(ctx context.Context, base, quote string) error {
+	query := `DELETE FROM currency_rates WHERE base_currency = $1 AND quote_currency = $2`
+
+	cmd, err := r.db.Exec(ctx, query, base, quote)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrExchangeRateNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,302 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationLockID ключ advisory lock, под которым выполняются миграции:
+// реплики, запущенные одновременно, применяют их по очереди
+const migrationLockID int64 = 7_365_411_202
+
+// таблица версии схемы совместима с golang-migrate, поэтому БД, размеченные
+// контейнером migrate/migrate, продолжают обновляться с той же версии
+const createSchemaMigrations = `
+CREATE TABLE IF NOT EXISTS schema_migrations
+(
+    version BIGINT  NOT NULL PRIMARY KEY,
+    dirty   BOOLEAN NOT NULL
+)`
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus миграция и признак того, что она применена
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// SchemaVersion версия схемы из schema_migrations: 0 - миграции не применялись.
+// Dirty - миграция на этой версии прервалась, схему нужно исправить вручную
+// и выполнить Force
+type SchemaVersion struct {
+	Version int64
+	Dirty   bool
+}
+
+type Migrator struct {
+	db         *pgxpool.Pool
+	migrations []Migration
+}
+
+// NewMigrator читает миграции из fsys; для каждой версии нужен файл .up.sql
+func NewMigrator(db *pgxpool.Pool, fsys fs.FS) (*Migrator, error) {
+	files, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, file := range files {
+		match := migrationFileName.FindStringSubmatch(file.Name())
+		if file.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", file.Name())
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s", version, m.Name, match[2])
+		}
+
+		sql, err := fs.ReadFile(fsys, file.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", file.Name(), err)
+		}
+
+		if match[3] == "up" {
+			m.Up = string(sql)
+		} else {
+			m.Down = string(sql)
+		}
+	}
+
+	migrator := &Migrator{db: db}
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrator.migrations = append(migrator.migrations, *m)
+	}
+
+	slices.SortFunc(migrator.migrations, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+
+	return migrator, nil
+}
+
+// Up применяет все непримененные миграции и возвращает их число
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := m.cleanVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations[m.position(current):] {
+			if err := apply(ctx, conn, migration.Up, migration.Version); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+			}
+
+			slog.Info("migration applied", "version", migration.Version, "name", migration.Name)
+			applied++
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down откатывает steps последних примененных миграций и возвращает их число
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := m.cleanVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := m.position(current) - 1; i >= 0 && reverted < steps; i-- {
+			migration := m.migrations[i]
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+			}
+
+			var previous int64
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+
+			if err := apply(ctx, conn, migration.Down, previous); err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+			}
+
+			slog.Info("migration reverted", "version", migration.Version, "name", migration.Name)
+			reverted++
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status текущая версия схемы и список известных приложению миграций
+func (m *Migrator) Status(ctx context.Context) (SchemaVersion, []MigrationStatus, error) {
+	var current SchemaVersion
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		var err error
+		current, err = schemaVersion(ctx, conn)
+		return err
+	})
+	if err != nil {
+		return SchemaVersion{}, nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, MigrationStatus{
+			Migration: migration,
+			Applied:   migration.Version <= current.Version,
+		})
+	}
+
+	return current, statuses, nil
+}
+
+// Force записывает версию схемы без выполнения миграций и снимает признак
+// dirty; 0 - ни одна миграция не применена
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	if version != 0 && !m.known(version) {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			return setSchemaVersion(ctx, tx, version)
+		})
+	})
+}
+
+func (m *Migrator) known(version int64) bool {
+	return slices.ContainsFunc(m.migrations, func(mg Migration) bool { return mg.Version == version })
+}
+
+// position индекс первой миграции после версии version
+func (m *Migrator) position(version int64) int {
+	i, _ := slices.BinarySearchFunc(m.migrations, version+1, func(mg Migration, v int64) int {
+		return cmp.Compare(mg.Version, v)
+	})
+	return i
+}
+
+// cleanVersion изменения начинаются только с чистой версии, известной приложению
+func (m *Migrator) cleanVersion(ctx context.Context, conn *pgxpool.Conn) (int64, error) {
+	current, err := schemaVersion(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	if current.Dirty {
+		return 0, fmt.Errorf("database is dirty at version %d: fix the schema and run migrate force", current.Version)
+	}
+	if current.Version != 0 && !m.known(current.Version) {
+		return 0, fmt.Errorf("database version %d is unknown to this build", current.Version)
+	}
+
+	return current.Version, nil
+}
+
+// withLock выполняет fn на одном соединении под advisory lock
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		// контекст мог истечь, а блокировку нужно снять до возврата соединения в пул
+		if _, err := conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockID); err != nil {
+			slog.Error("failed to release migration lock", "error", err)
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, createSchemaMigrations); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// apply выполняет миграцию и записывает новую версию в одной транзакции:
+// при ошибке схема и версия остаются прежними
+func apply(ctx context.Context, conn *pgxpool.Conn, sql string, version int64) error {
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return err
+		}
+
+		return setSchemaVersion(ctx, tx, version)
+	})
+}
+
+func schemaVersion(ctx context.Context, conn *pgxpool.Conn) (SchemaVersion, error) {
+	var v SchemaVersion
+
+	err := conn.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&v.Version, &v.Dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return SchemaVersion{}, nil
+	}
+	if err != nil {
+		return SchemaVersion{}, fmt.Errorf("read schema version: %w", err)
+	}
+
+	return v, nil
+}
+
+// setSchemaVersion в таблице одна строка, как у golang-migrate; версия 0 - пустая таблица
+func setSchemaVersion(ctx context.Context, tx pgx.Tx, version int64) error {
+	if _, err := tx.Exec(ctx, `TRUNCATE schema_migrations`); err != nil {
+		return err
+	}
+
+	if version == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, version)
+	return err
+}
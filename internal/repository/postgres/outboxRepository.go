@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"testTask/internal/domain"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OutboxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOutboxRepository(db *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+func (r *OutboxRepository) Add(ctx context.Context, e *domain.Event) error {
+	query := `
+		INSERT INTO outbox (event_id, tenant_id, event_type, subscription_id, payload)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		e.ID,
+		e.TenantID,
+		e.Type,
+		e.SubscriptionID,
+		e.Data,
+	).Scan(&e.OccurredAt)
+}
+
+var outboxColumns = []string{"event_id", "tenant_id", "event_type", "subscription_id", "payload"}
+
+// порядок публикации задает id, который выдается в порядке строк VALUES
+func (r *OutboxRepository) AddBatch(ctx context.Context, events []domain.Event) error {
+	rows := make([][]any, 0, len(events))
+	for i := range events {
+		e := &events[i]
+		rows = append(rows, []any{e.ID, e.TenantID, e.Type, e.SubscriptionID, e.Data})
+	}
+
+	return insertValues(ctx, conn(ctx, r.db), "outbox", outboxColumns, rows, "")
+}
+
+// строки блокируются только на время UPDATE: после него их защищает next_attempt_at
+func (r *OutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error) {
+	query := `
+		UPDATE outbox o
+		SET next_attempt_at = NOW() + $2::double precision * interval '1 millisecond'
+		FROM (
+			SELECT id FROM outbox
+			WHERE published_at IS NULL AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		) due
+		WHERE o.id = due.id
+		RETURNING o.id, o.event_id, o.tenant_id, o.event_type, o.subscription_id, o.payload, o.created_at, o.attempts
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []domain.OutboxMessage
+
+	for rows.Next() {
+		var m domain.OutboxMessage
+		err = rows.Scan(
+			&m.ID,
+			&m.Event.ID,
+			&m.Event.TenantID,
+			&m.Event.Type,
+			&m.Event.SubscriptionID,
+			&m.Event.Data,
+			&m.Event.OccurredAt,
+			&m.Attempts,
+		)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING не сохраняет порядок подзапроса
+	slices.SortFunc(messages, func(a, b domain.OutboxMessage) int { return cmp.Compare(a.ID, b.ID) })
+
+	return messages, nil
+}
+
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id int64) error {
+	query := `UPDATE outbox SET published_at = NOW(), attempts = attempts + 1, last_error = NULL WHERE id = $1`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, id)
+	return err
+}
+
+// время следующей попытки считается по часам БД, как и выборка в Claim
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, reason string, retryAfter time.Duration) error {
+	query := `
+		UPDATE outbox
+		SET attempts = attempts + 1,
+		    last_error = $2,
+		    next_attempt_at = NOW() + $3::double precision * interval '1 millisecond'
+		WHERE id = $1
+	`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, id, reason, retryAfter.Milliseconds())
+	return err
+}
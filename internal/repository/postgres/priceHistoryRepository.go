@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testTask/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PriceHistoryRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPriceHistoryRepository(db *pgxpool.Pool) *PriceHistoryRepository {
+	return &PriceHistoryRepository{db: db}
+}
+
+// История цен своего tenant_id не хранит: все запросы проверяют,
+// что подписка принадлежит арендатору клиента
+func (r *PriceHistoryRepository) Upsert(ctx context.Context, change *domain.PriceChange) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO subscription_prices (subscription_id, effective_from, price)
+		SELECT s.id, $2, $3
+		FROM subscriptions s
+		WHERE s.id = $1 AND s.tenant_id = $4
+		ON CONFLICT (subscription_id, effective_from)
+		DO UPDATE SET price = EXCLUDED.price, created_at = NOW()
+		RETURNING created_at
+	`
+
+	err = conn(ctx, r.db).QueryRow(ctx, query,
+		change.SubscriptionID,
+		change.EffectiveFrom,
+		change.Price,
+		tenantID,
+	).Scan(&change.CreatedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) || isPgError(err, foreignKeyViolation) {
+		return domain.ErrSubscriptionNotFound
+	}
+
+	return err
+}
+
+func (r *PriceHistoryRepository) List(ctx context.Context, subscriptionID uuid.UUID) ([]domain.PriceChange, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT p.subscription_id, p.effective_from, p.price, p.created_at
+		FROM subscription_prices p
+		JOIN subscriptions s ON s.id = p.subscription_id
+		WHERE p.subscription_id = $1 AND s.tenant_id = $2
+		ORDER BY p.effective_from
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, subscriptionID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []domain.PriceChange
+
+	for rows.Next() {
+		var c domain.PriceChange
+		err = rows.Scan(
+			&c.SubscriptionID,
+			&c.EffectiveFrom,
+			&c.Price,
+			&c.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func (r *PriceHistoryRepository) Delete(ctx context.Context, subscriptionID uuid.UUID, effectiveFrom time.Time) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		DELETE FROM subscription_prices p
+		USING subscriptions s
+		WHERE s.id = p.subscription_id
+		  AND p.subscription_id = $1 AND p.effective_from = $2 AND s.tenant_id = $3
+	`
+
+	cmd, err := conn(ctx, r.db).Exec(ctx, query, subscriptionID, effectiveFrom, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrPriceChangeNotFound
+	}
+
+	return nil
+}
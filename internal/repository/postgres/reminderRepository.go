@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testTask/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReminderRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewReminderRepository(db *pgxpool.Pool) *ReminderRepository {
+	return &ReminderRepository{db: db}
+}
+
+// Tenants читает арендаторов функцией из миграции, которая не ограничена RLS
+func (r *ReminderRepository) Tenants(ctx context.Context) ([]string, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT subscription_tenants()`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenants []string
+
+	for rows.Next() {
+		var tenantID string
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, tenantID)
+	}
+
+	return tenants, rows.Err()
+}
+
+func (r *ReminderRepository) ListCandidates(
+	ctx context.Context,
+	from, to time.Time,
+	afterID uuid.UUID,
+	limit int,
+) ([]domain.Subscription, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// месяц end_date оплачивается, поэтому подписка, закончившаяся в месяце from,
+	// еще может иметь списание в периоде
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions s
+		JOIN services sv ON sv.id = s.service_id
+		WHERE s.tenant_id = $1
+		  AND s.deleted_at IS NULL
+		  AND s.start_date <= $3
+		  AND (s.end_date IS NULL OR date_trunc('month', s.end_date::timestamp) >= date_trunc('month', $2::timestamp))
+		  AND s.id > $4
+		ORDER BY s.id
+		LIMIT $5
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, tenantID, from, to, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []domain.Subscription
+
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, *sub)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subs, nil
+}
+
+// напоминание без notified_at с истекшей арендой закрепляется повторно;
+// xmax = 0 у вставленной, а не обновленной строки
+func (r *ReminderRepository) Claim(ctx context.Context, reminder *domain.Reminder, lease time.Duration) (bool, bool, error) {
+	query := `
+		INSERT INTO renewal_reminders (subscription_id, charge_date, tenant_id, locked_until)
+		VALUES ($1, $2, $3, NOW() + $4::double precision * interval '1 millisecond')
+		ON CONFLICT (subscription_id, charge_date) DO UPDATE
+		SET locked_until = EXCLUDED.locked_until
+		WHERE renewal_reminders.notified_at IS NULL AND renewal_reminders.locked_until <= NOW()
+		RETURNING xmax = 0
+	`
+
+	var created bool
+
+	err := conn(ctx, r.db).QueryRow(ctx, query,
+		reminder.SubscriptionID, reminder.ChargeDate, reminder.TenantID, lease.Milliseconds(),
+	).Scan(&created)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+
+	return true, created, nil
+}
+
+func (r *ReminderRepository) MarkNotified(ctx context.Context, reminder *domain.Reminder) error {
+	query := `
+		UPDATE renewal_reminders
+		SET notified_at = NOW(), locked_until = NULL
+		WHERE subscription_id = $1 AND charge_date = $2
+	`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, reminder.SubscriptionID, reminder.ChargeDate)
+	return err
+}
+
+func (r *ReminderRepository) Release(ctx context.Context, reminder *domain.Reminder) error {
+	query := `
+		UPDATE renewal_reminders
+		SET locked_until = NOW()
+		WHERE subscription_id = $1 AND charge_date = $2 AND notified_at IS NULL
+	`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, reminder.SubscriptionID, reminder.ChargeDate)
+	return err
+}
@@ -0,0 +1,246 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testTask/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// коды ошибок PostgreSQL
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+func isPgError(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
+
+type ServiceRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewServiceRepository(db *pgxpool.Pool) *ServiceRepository {
+	return &ServiceRepository{db: db}
+}
+
+const serviceColumns = `
+		sv.id, sv.name, sv.category, sv.default_price, sv.currency, sv.website,
+		ARRAY(SELECT a.alias FROM service_aliases a WHERE a.service_id = sv.id ORDER BY a.alias)
+`
+
+func scanService(row pgx.Row) (*domain.Service, error) {
+	var s domain.Service
+
+	err := row.Scan(
+		&s.ID,
+		&s.Name,
+		&s.Category,
+		&s.DefaultPrice,
+		&s.Currency,
+		&s.Website,
+		&s.Aliases,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (r *ServiceRepository) Create(ctx context.Context, s *domain.Service) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO services (id, name, category, default_price, currency, website)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err = tx.Exec(ctx, query,
+		s.ID,
+		s.Name,
+		s.Category,
+		s.DefaultPrice,
+		s.Currency,
+		s.Website,
+	)
+	if err != nil {
+		return mapServiceError(err)
+	}
+
+	if err = insertAliases(ctx, tx, s); err != nil {
+		return mapServiceError(err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *ServiceRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Service, error) {
+	query := `SELECT ` + serviceColumns + ` FROM services sv WHERE sv.id = $1`
+
+	s, err := scanService(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrServiceNotFound
+		}
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (r *ServiceRepository) FindByName(ctx context.Context, name string) (*domain.Service, error) {
+	query := `
+		SELECT ` + serviceColumns + `
+		FROM services sv
+		WHERE lower(sv.name) = lower($1)
+		   OR EXISTS (
+		       SELECT 1 FROM service_aliases a
+		       WHERE a.service_id = sv.id AND lower(a.alias) = lower($1)
+		   )
+		LIMIT 1
+	`
+
+	s, err := scanService(r.db.QueryRow(ctx, query, name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrServiceNotFound
+		}
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (r *ServiceRepository) Update(ctx context.Context, s *domain.Service) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE services
+		SET name = $1,
+		    category = $2,
+		    default_price = $3,
+		    currency = $4,
+		    website = $5,
+		    updated_at = NOW()
+		WHERE id = $6
+	`
+
+	cmd, err := tx.Exec(ctx, query,
+		s.Name,
+		s.Category,
+		s.DefaultPrice,
+		s.Currency,
+		s.Website,
+		s.ID,
+	)
+	if err != nil {
+		return mapServiceError(err)
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrServiceNotFound
+	}
+
+	// синонимы заменяются целиком
+	if _, err = tx.Exec(ctx, `DELETE FROM service_aliases WHERE service_id = $1`, s.ID); err != nil {
+		return err
+	}
+
+	if err = insertAliases(ctx, tx, s); err != nil {
+		return mapServiceError(err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *ServiceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM services WHERE id = $1`
+
+	cmd, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return mapServiceError(err)
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrServiceNotFound
+	}
+
+	return nil
+}
+
+func (r *ServiceRepository) List(ctx context.Context, category *string) ([]domain.Service, error) {
+	query := `SELECT ` + serviceColumns + ` FROM services sv WHERE 1=1`
+
+	var args []interface{}
+
+	if category != nil && *category != "" {
+		args = append(args, *category)
+		query += fmt.Sprintf(" AND lower(sv.category) = lower($%d)", len(args))
+	}
+
+	query += " ORDER BY sv.name"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var services []domain.Service
+
+	for rows.Next() {
+		s, err := scanService(rows)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, *s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+func insertAliases(ctx context.Context, tx pgx.Tx, s *domain.Service) error {
+	for _, alias := range s.Aliases {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO service_aliases (service_id, alias) VALUES ($1, $2)`,
+			s.ID, alias,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mapServiceError(err error) error {
+	switch {
+	case isPgError(err, uniqueViolation):
+		return domain.ErrServiceNameTaken
+	case isPgError(err, foreignKeyViolation):
+		return domain.ErrServiceInUse
+	default:
+		return err
+	}
+}
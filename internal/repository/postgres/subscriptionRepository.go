@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"testTask/internal/domain"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -19,126 +21,435 @@ func NewSubscriptionRepository(db *pgxpool.Pool) *SubscriptionRepository {
 	return &SubscriptionRepository{db: db}
 }
 
+// Все запросы ограничены арендатором клиента из контекста (domain.TenantFromContext)
+// и выполняются в его транзакции, если она открыта (Transactor.WithinTx)
 func (r *SubscriptionRepository) Create(ctx context.Context, sub *domain.Subscription) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO subscriptions 
-		(id, service_name, price, user_id, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		(id, service_id, price, currency, billing_period, user_id, start_date, end_date, tenant_id, status, trial_until)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING created_at
 	`
-	_, err := r.db.Exec(ctx, query,
+	err = conn(ctx, r.db).QueryRow(ctx, query,
 		sub.ID,
-		sub.ServiceName,
+		sub.ServiceID,
 		sub.Price,
+		sub.Currency,
+		sub.BillingPeriod,
 		sub.UserID,
 		sub.StartDate,
 		sub.EndDate,
-	)
+		tenantID,
+		storedStatus(sub.Status),
+		sub.TrialUntil,
+	).Scan(&sub.CreatedAt)
+
+	return mapSubscriptionError(err)
+}
+
+// copyColumns колонки, которые заполняет CreateBatch
+var copyColumns = []string{
+	"id", "service_id", "price", "currency", "billing_period", "user_id",
+	"start_date", "end_date", "tenant_id", "status", "trial_until", "created_at",
+}
+
+// CreateBatch загружает подписки через COPY. Для таблицы с действующими политиками
+// RLS COPY FROM не поддерживается, тогда подписки вставляются пакетом INSERT
+func (r *SubscriptionRepository) CreateBatch(ctx context.Context, subs []domain.Subscription) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(subs) == 0 {
+		return nil
+	}
+
+	q := conn(ctx, r.db)
+
+	var rls bool
+	if err := q.QueryRow(ctx, `SELECT row_security_active('subscriptions')`).Scan(&rls); err != nil {
+		return err
+	}
+
+	// одно время создания на пакет, как у NOW() в транзакции
+	createdAt := time.Now().UTC().Truncate(time.Microsecond)
+
+	rows := make([][]any, 0, len(subs))
+	for i := range subs {
+		sub := &subs[i]
+		rows = append(rows, []any{
+			sub.ID,
+			sub.ServiceID,
+			sub.Price,
+			sub.Currency,
+			sub.BillingPeriod,
+			sub.UserID,
+			sub.StartDate,
+			sub.EndDate,
+			tenantID,
+			storedStatus(sub.Status),
+			sub.TrialUntil,
+			createdAt,
+		})
+	}
+
+	if rls {
+		err = insertRows(ctx, q, rows)
+	} else {
+		_, err = q.CopyFrom(ctx, pgx.Identifier{"subscriptions"}, copyColumns, pgx.CopyFromRows(rows))
+	}
+	if err != nil {
+		return mapSubscriptionError(err)
+	}
+
+	for i := range subs {
+		subs[i].CreatedAt = createdAt
+	}
+
+	return nil
+}
+
+func insertRows(ctx context.Context, q querier, rows [][]any) error {
+	query := `INSERT INTO subscriptions (` + strings.Join(copyColumns, ", ") + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	batch := &pgx.Batch{}
+	for _, row := range rows {
+		batch.Queue(query, row...)
+	}
+
+	return q.SendBatch(ctx, batch).Close()
+}
+
+// сервис мог быть удален из каталога между проверкой в service слое и записью
+func mapSubscriptionError(err error) error {
+	if isPgError(err, foreignKeyViolation) {
+		return domain.NewValidationError("service_id", domain.ErrServiceNotFound.Error())
+	}
 
 	return err
 }
 
-func (r *SubscriptionRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
-	query := `
-		SELECT id, service_name, price, user_id, start_date, end_date
-		FROM subscriptions
-		WHERE id = $1
-	`
+// пробный период - не хранимое состояние, а активная подписка до trial_until
+// (см. domain.Subscription.StatusAt)
+func storedStatus(status domain.SubscriptionStatus) domain.SubscriptionStatus {
+	if status == domain.StatusTrial || status == "" {
+		return domain.StatusActive
+	}
+
+	return status
+}
 
+const subscriptionStatusExpr = `CASE
+            WHEN s.status = 'active' AND s.trial_until > (NOW() AT TIME ZONE 'UTC')::date THEN 'trial'
+            ELSE s.status
+        END`
+
+// паузы подписки в JSON с ключами полей domain.Pause
+const subscriptionPausesExpr = `COALESCE((
+            SELECT json_agg(json_build_object(
+                'From', sp.paused_from::timestamp AT TIME ZONE 'UTC',
+                'Until', sp.resumed_at::timestamp AT TIME ZONE 'UTC'
+            ) ORDER BY sp.paused_from)
+            FROM subscription_pauses sp
+            WHERE sp.subscription_id = s.id
+        ), '[]')`
+
+const subscriptionColumns = `s.id, s.service_id, sv.name, s.price, s.currency, s.billing_period, s.user_id, s.start_date, s.end_date, ` +
+	subscriptionStatusExpr + `, s.trial_until, s.cancel_at, ` + subscriptionPausesExpr + `, s.created_at, s.deleted_at`
+
+func scanSubscription(row pgx.Row) (*domain.Subscription, error) {
 	var sub domain.Subscription
 
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := row.Scan(
 		&sub.ID,
+		&sub.ServiceID,
 		&sub.ServiceName,
 		&sub.Price,
+		&sub.Currency,
+		&sub.BillingPeriod,
 		&sub.UserID,
 		&sub.StartDate,
 		&sub.EndDate,
+		&sub.Status,
+		&sub.TrialUntil,
+		&sub.CancelAt,
+		&sub.Pauses,
+		&sub.CreatedAt,
+		&sub.DeletedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sub, nil
+}
+
+func (r *SubscriptionRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	return r.get(ctx, id, false)
+}
 
+func (r *SubscriptionRepository) GetDeleted(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	return r.get(ctx, id, true)
+}
+
+func (r *SubscriptionRepository) get(ctx context.Context, id uuid.UUID, deleted bool) (*domain.Subscription, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
-		}
+		return nil, err
+	}
 
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions s
+		JOIN services sv ON sv.id = s.service_id
+		WHERE s.id = $1 AND s.tenant_id = $2 AND (s.deleted_at IS NOT NULL) = $3
+	`
+
+	sub, err := scanSubscription(conn(ctx, r.db).QueryRow(ctx, query, id, tenantID, deleted))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+	if err != nil {
 		return nil, err
 	}
 
-	return &sub, nil
+	return sub, nil
 }
 
 func (r *SubscriptionRepository) Update(ctx context.Context, sub *domain.Subscription) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE subscriptions
-		SET service_name = $1,
+		SET service_id = $1,
 		    price = $2,
-		    start_date = $3,
-		    end_date = $4,
+		    currency = $3,
+		    billing_period = $4,
+		    start_date = $5,
+		    end_date = $6,
+		    trial_until = $7,
 		    updated_at = NOW()
-		WHERE id = $5
+		WHERE id = $8 AND tenant_id = $9 AND deleted_at IS NULL
 	`
 
-	cmd, err := r.db.Exec(ctx, query,
-		sub.ServiceName,
+	cmd, err := conn(ctx, r.db).Exec(ctx, query,
+		sub.ServiceID,
 		sub.Price,
+		sub.Currency,
+		sub.BillingPeriod,
 		sub.StartDate,
 		sub.EndDate,
+		sub.TrialUntil,
 		sub.ID,
+		tenantID,
 	)
 
 	if err != nil {
-		return err
+		return mapSubscriptionError(err)
 	}
 
 	if cmd.RowsAffected() == 0 {
-		return fmt.Errorf("subscription not found")
+		return domain.ErrSubscriptionNotFound
 	}
 
 	return nil
 }
 
+// UpdateStatus сохраняет последнюю паузу отдельным запросом, поэтому
+// вызывается в транзакции
+func (r *SubscriptionRepository) UpdateStatus(ctx context.Context, sub *domain.Subscription) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE subscriptions
+		SET status = $1,
+		    cancel_at = $2,
+		    end_date = $3,
+		    updated_at = NOW()
+		WHERE id = $4 AND tenant_id = $5 AND deleted_at IS NULL
+	`
+
+	cmd, err := conn(ctx, r.db).Exec(ctx, query,
+		storedStatus(sub.Status),
+		sub.CancelAt,
+		sub.EndDate,
+		sub.ID,
+		tenantID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrSubscriptionNotFound
+	}
+
+	if len(sub.Pauses) == 0 {
+		return nil
+	}
+
+	// переходы меняют только последнюю паузу: начинают новую или завершают текущую
+	pause := sub.Pauses[len(sub.Pauses)-1]
+	query = `
+		INSERT INTO subscription_pauses (subscription_id, tenant_id, paused_from, resumed_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (subscription_id, paused_from)
+		DO UPDATE SET resumed_at = EXCLUDED.resumed_at
+	`
+
+	_, err = conn(ctx, r.db).Exec(ctx, query, sub.ID, tenantID, pause.From, pause.Until)
+	return err
+}
+
 func (r *SubscriptionRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM subscriptions WHERE id = $1`
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE subscriptions
+		SET deleted_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+	`
 
-	cmd, err := r.db.Exec(ctx, query, id)
+	cmd, err := conn(ctx, r.db).Exec(ctx, query, id, tenantID)
 	if err != nil {
 		return err
 	}
 
 	if cmd.RowsAffected() == 0 {
-		return fmt.Errorf("subscription not found")
+		return domain.ErrSubscriptionNotFound
 	}
 
 	return nil
 }
 
+func (r *SubscriptionRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE subscriptions
+		SET deleted_at = NULL,
+		    updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NOT NULL
+	`
+
+	cmd, err := conn(ctx, r.db).Exec(ctx, query, id, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrSubscriptionNotFound
+	}
+
+	return nil
+}
+
+// история цен удаляется вместе с подписками (ON DELETE CASCADE)
+func (r *SubscriptionRepository) Purge(ctx context.Context, before time.Time) ([]domain.Subscription, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// все части запроса видят один снимок, поэтому паузы удаляемых подписок
+	// еще читаются, хотя удаляются каскадом
+	query := `
+		WITH s AS (
+			DELETE FROM subscriptions WHERE tenant_id = $1 AND deleted_at < $2
+			RETURNING *
+		)
+		SELECT ` + subscriptionColumns + `
+		FROM s
+		JOIN services sv ON sv.id = s.service_id
+		ORDER BY s.deleted_at, s.id
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, tenantID, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var purged []domain.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		purged = append(purged, *sub)
+	}
+
+	return purged, rows.Err()
+}
+
+// колонка сортировки списка и тип, к которому приводится значение курсора
+var listSortColumns = map[domain.ListSort]struct{ column, cast string }{
+	domain.SortByStartDate:   {"s.start_date", "date"},
+	domain.SortByPrice:       {"s.price", "bigint"},
+	domain.SortByServiceName: {"sv.name", "text"},
+	domain.SortByCreatedAt:   {"s.created_at", "timestamp"},
+}
+
 func (r *SubscriptionRepository) List(
 	ctx context.Context,
-	userID *uuid.UUID,
-	serviceName *string,
+	filter *domain.ListFilter,
 ) ([]domain.Subscription, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort, ok := listSortColumns[filter.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort value %q", filter.Sort)
+	}
 
 	query := `
-		SELECT id, service_name, price, user_id, start_date, end_date
-		FROM subscriptions
-		WHERE 1=1
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions s
+		JOIN services sv ON sv.id = s.service_id
+		WHERE s.tenant_id = $1
 	`
 
-	var args []interface{}
-	argID := 1
+	conditions, args := listFilterConditions(filter, []interface{}{tenantID})
+	query += conditions
+	argID := len(args) + 1
 
-	if userID != nil {
-		query += fmt.Sprintf(" AND user_id = $%d", argID)
-		args = append(args, *userID)
-		argID++
+	// keyset-пагинация: id - второй ключ сортировки, чтобы порядок был однозначным
+	direction, compare := "ASC", ">"
+	if filter.Desc {
+		direction, compare = "DESC", "<"
 	}
 
-	if serviceName != nil && *serviceName != "" {
-		query += fmt.Sprintf(" AND service_name = $%d", argID)
-		args = append(args, *serviceName)
-		argID++
+	if filter.Cursor != nil {
+		query += fmt.Sprintf(" AND (%s, s.id) %s ($%d::%s, $%d)", sort.column, compare, argID, sort.cast, argID+1)
+		args = append(args, filter.Cursor.Value, filter.Cursor.ID)
+		argID += 2
 	}
 
-	rows, err := r.db.Query(ctx, query, args...)
+	query += fmt.Sprintf(" ORDER BY %s %s, s.id %s LIMIT $%d", sort.column, direction, direction, argID)
+	args = append(args, filter.Limit)
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -147,19 +458,11 @@ func (r *SubscriptionRepository) List(
 	var subs []domain.Subscription
 
 	for rows.Next() {
-		var sub domain.Subscription
-		err = rows.Scan(
-			&sub.ID,
-			&sub.ServiceName,
-			&sub.Price,
-			&sub.UserID,
-			&sub.StartDate,
-			&sub.EndDate,
-		)
+		sub, err := scanSubscription(rows)
 		if err != nil {
 			return nil, err
 		}
-		subs = append(subs, sub)
+		subs = append(subs, *sub)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -169,37 +472,334 @@ func (r *SubscriptionRepository) List(
 	return subs, nil
 }
 
-func (r *SubscriptionRepository) CalculateTotal(
-	ctx context.Context,
-	filter *domain.TotalFilter,
-) (int, error) {
-	query := `
-        SELECT COALESCE(SUM(price), 0)
-        FROM subscriptions
-        WHERE start_date <= $1
-          AND (end_date IS NULL OR end_date >= $2)
-    `
+// экранирует спецсимволы LIKE, чтобы строка поиска искалась буквально
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
 
-	args := []interface{}{filter.To, filter.From}
-	argPos := 3
+// условия фильтра списка подписок; значения передаются только через плейсхолдеры
+func listFilterConditions(filter *domain.ListFilter, args []interface{}) (string, []interface{}) {
+	var conditions string
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions += " AND " + strings.ReplaceAll(condition, "$?", fmt.Sprintf("$%d", len(args)))
+	}
+
+	if !filter.IncludeDeleted {
+		conditions += " AND s.deleted_at IS NULL"
+	}
+
+	if filter.OwnerID != nil {
+		add("s.user_id = $?", *filter.OwnerID)
+	}
+
+	if filter.UserID != nil {
+		add("s.user_id = $?", *filter.UserID)
+	}
+
+	if filter.ServiceName != nil && *filter.ServiceName != "" {
+		add(fmt.Sprintf(serviceNameMatch, len(args)+1), *filter.ServiceName)
+	}
+
+	if filter.Search != "" {
+		add(`(sv.name ILIKE $? OR EXISTS (
+            SELECT 1 FROM service_aliases a
+            WHERE a.service_id = sv.id AND a.alias ILIKE $?
+        ))`, "%"+likeEscaper.Replace(filter.Search)+"%")
+	}
+
+	if filter.ActiveOn != nil {
+		add("date_trunc('month', s.start_date::timestamp) <= date_trunc('month', $?::timestamp)", *filter.ActiveOn)
+		add("(s.end_date IS NULL OR date_trunc('month', s.end_date::timestamp) >= date_trunc('month', $?::timestamp))", *filter.ActiveOn)
+	}
+
+	if filter.StartFrom != nil {
+		add("s.start_date >= $?", *filter.StartFrom)
+	}
+
+	if filter.StartTo != nil {
+		add("s.start_date <= $?", *filter.StartTo)
+	}
+
+	if filter.EndFrom != nil {
+		add("s.end_date >= $?", *filter.EndFrom)
+	}
+
+	if filter.EndTo != nil {
+		add("s.end_date <= $?", *filter.EndTo)
+	}
+
+	if filter.PriceMin != nil {
+		add("s.price >= $?", *filter.PriceMin)
+	}
+
+	if filter.PriceMax != nil {
+		add("s.price <= $?", *filter.PriceMax)
+	}
+
+	if filter.OpenEnded != nil {
+		if *filter.OpenEnded {
+			conditions += " AND s.end_date IS NULL"
+		} else {
+			conditions += " AND s.end_date IS NOT NULL"
+		}
+	}
+
+	return conditions, args
+}
+
+// название сервиса совпадает с названием в каталоге или с одним из синонимов без учета регистра.
+// Единственный плейсхолдер - искомое название
+const serviceNameMatch = `(lower(sv.name) = lower($%[1]d) OR EXISTS (
+            SELECT 1 FROM service_aliases a
+            WHERE a.service_id = sv.id AND lower(a.alias) = lower($%[1]d)
+        ))`
+
+// добавляет к запросу условия фильтра по пользователю и сервису,
+// нумерация плейсхолдеров продолжается после уже переданных args.
+// Удаленные подписки в подсчетах не участвуют
+func totalFilterConditions(tenantID string, filter *domain.TotalFilter, args []interface{}) (string, []interface{}) {
+	args = append(args, tenantID)
+	conditions := fmt.Sprintf(" AND s.tenant_id = $%d AND s.deleted_at IS NULL", len(args))
+
+	if filter.OwnerID != nil {
+		args = append(args, *filter.OwnerID)
+		conditions += fmt.Sprintf(" AND s.user_id = $%d", len(args))
+	}
 
 	if filter.UserID != nil {
-		query += fmt.Sprintf(" AND user_id = $%d", argPos)
 		args = append(args, *filter.UserID)
-		argPos++
+		conditions += fmt.Sprintf(" AND s.user_id = $%d", len(args))
 	}
 
 	if filter.ServiceName != nil && *filter.ServiceName != "" {
-		query += fmt.Sprintf(" AND service_name = $%d", argPos)
 		args = append(args, *filter.ServiceName)
-		argPos++
+		conditions += fmt.Sprintf(" AND "+serviceNameMatch, len(args))
 	}
 
-	var total int
-	err := r.db.QueryRow(ctx, query, args...).Scan(&total)
+	return conditions, args
+}
+
+// шаг между списаниями в зависимости от периода оплаты (см. domain.BillingPeriod)
+const billingIntervalExpr = `CASE s.billing_period
+            WHEN 'week' THEN interval '1 week'
+            WHEN 'quarter' THEN interval '3 months'
+            WHEN 'year' THEN interval '1 year'
+            ELSE interval '1 month'
+        END`
+
+// первое списание: для недельной оплаты - день начала подписки,
+// для остальных периодов - первое число месяца начала
+const firstChargeExpr = `CASE s.billing_period
+            WHEN 'week' THEN s.start_date::timestamp
+            ELSE date_trunc('month', s.start_date::timestamp)
+        END`
+
+// цена, действующая на дату списания charge.date (см. domain.Subscription.PriceAt)
+const chargePriceExpr = `COALESCE((
+            SELECT p.price
+            FROM subscription_prices p
+            WHERE p.subscription_id = s.id
+              AND p.effective_from <= charge.date
+            ORDER BY p.effective_from DESC
+            LIMIT 1
+        ), s.price)`
+
+// списание не выставляется в пробный период, во время пауз и после отмены
+// (см. domain.Subscription.billable)
+const billableChargeCond = `(s.trial_until IS NULL OR charge.date >= s.trial_until)
+          AND (s.cancel_at IS NULL OR charge.date < s.cancel_at)
+          AND NOT EXISTS (
+              SELECT 1 FROM subscription_pauses sp
+              WHERE sp.subscription_id = s.id
+                AND charge.date >= sp.paused_from
+                AND (sp.resumed_at IS NULL OR charge.date < sp.resumed_at)
+          )`
+
+// все выставляемые списания по подпискам, попадающие одновременно в период их действия
+// и в запрошенный период (см. domain.Subscription.ChargeDates).
+// $1 - конец периода, $2 - начало
+const chargesFrom = `
+        FROM subscriptions s
+        JOIN services sv ON sv.id = s.service_id
+        CROSS JOIN LATERAL generate_series(
+            ` + firstChargeExpr + `,
+            date_trunc('month', LEAST(COALESCE(s.end_date, $1), $1)::timestamp) + interval '1 month' - interval '1 day',
+            ` + billingIntervalExpr + `
+        ) AS charge(date)
+        WHERE s.start_date <= $1
+          AND (s.end_date IS NULL OR s.end_date >= $2)
+          AND charge.date >= date_trunc('month', $2::timestamp)
+          AND ` + billableChargeCond + `
+`
+
+// колонки для группировки; значения group_by никогда не попадают в SQL напрямую
+var groupByColumns = map[domain.TotalGroupBy]string{
+	domain.GroupByServiceName: "sv.name",
+	domain.GroupByUserID:      "s.user_id",
+}
+
+func (r *SubscriptionRepository) CalculateTotal(
+	ctx context.Context,
+	filter *domain.TotalFilter,
+) ([]domain.Money, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+
+	query := `SELECT s.currency, SUM(` + chargePriceExpr + `)::bigint` + chargesFrom
+
+	conditions, args := totalFilterConditions(tenantID, filter, []interface{}{filter.To, filter.From})
+	query += conditions + " GROUP BY s.currency ORDER BY s.currency"
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []domain.Money
+
+	for rows.Next() {
+		var m domain.Money
+		if err = rows.Scan(&m.Currency, &m.Amount); err != nil {
+			return nil, err
+		}
+		totals = append(totals, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+func (r *SubscriptionRepository) CalculateGroupedTotal(
+	ctx context.Context,
+	filter *domain.TotalFilter,
+) ([]domain.GroupedTotal, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(filter.GroupBy) == 0 {
+		return nil, errors.New("group_by is required")
+	}
+
+	columns := make([]string, 0, len(filter.GroupBy))
+	for _, g := range filter.GroupBy {
+		column, ok := groupByColumns[g]
+		if !ok {
+			return nil, fmt.Errorf("unsupported group_by value %q", g)
+		}
+		columns = append(columns, column)
+	}
+	columns = append(columns, "s.currency")
+	groupBy := strings.Join(columns, ", ")
+
+	query := `SELECT ` + groupBy + `, SUM(` + chargePriceExpr + `)::bigint` + chargesFrom
+
+	conditions, args := totalFilterConditions(tenantID, filter, []interface{}{filter.To, filter.From})
+	query += conditions + " GROUP BY " + groupBy + " ORDER BY " + groupBy
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.GroupedTotal
+
+	for rows.Next() {
+		var gt domain.GroupedTotal
+
+		dest := make([]interface{}, 0, len(filter.GroupBy)+1)
+		for _, g := range filter.GroupBy {
+			switch g {
+			case domain.GroupByServiceName:
+				gt.ServiceName = new(string)
+				dest = append(dest, gt.ServiceName)
+			case domain.GroupByUserID:
+				gt.UserID = new(uuid.UUID)
+				dest = append(dest, gt.UserID)
+			}
+		}
+		dest = append(dest, &gt.Currency, &gt.Total)
+
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		result = append(result, gt)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *SubscriptionRepository) CalculateMonthlyBreakdown(
+	ctx context.Context,
+	filter *domain.TotalFilter,
+) ([]domain.MonthlyTotal, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// месяцы без активных подписок тоже попадают в результат с нулевой суммой
+	// и пустой валютой; подписка считается активной в месяце, даже если
+	// списания в нём нет. Для каждой валюты в месяце - отдельная строка
+	query := `
+        SELECT m.month, COALESCE(s.currency, ''), COALESCE(SUM(charges.amount), 0)::bigint, COUNT(s.id)
+        FROM generate_series(
+            date_trunc('month', $1::timestamp),
+            date_trunc('month', $2::timestamp),
+            interval '1 month'
+        ) AS m(month)
+        LEFT JOIN (subscriptions s JOIN services sv ON sv.id = s.service_id)
+            ON date_trunc('month', s.start_date::timestamp) <= m.month
+           AND (s.end_date IS NULL OR date_trunc('month', s.end_date::timestamp) >= m.month)
+    `
+
+	conditions, args := totalFilterConditions(tenantID, filter, []interface{}{filter.From, filter.To})
+	query += conditions + `
+        LEFT JOIN LATERAL (
+            SELECT COALESCE(SUM(` + chargePriceExpr + `), 0) AS amount
+            FROM generate_series(
+                ` + firstChargeExpr + `,
+                m.month + interval '1 month' - interval '1 day',
+                ` + billingIntervalExpr + `
+            ) AS charge(date)
+            WHERE charge.date >= m.month
+              AND ` + billableChargeCond + `
+        ) AS charges ON true
+        GROUP BY m.month, s.currency
+        ORDER BY m.month, s.currency
+    `
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.MonthlyTotal
+
+	for rows.Next() {
+		var mt domain.MonthlyTotal
+		if err = rows.Scan(&mt.Month, &mt.Currency, &mt.Amount, &mt.Subscriptions); err != nil {
+			return nil, err
+		}
+		result = append(result, mt)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return total, nil
+	return result, nil
 }
@@ -0,0 +1,25 @@
+package postgres
+
+import (
+	"context"
+	"testTask/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EnableRowLevelSecurity передает арендатора запроса в настройку app.tenant_id
+// каждого выдаваемого пулом соединения. По ней политики RLS из миграций
+// дополнительно к условиям в запросах отсекают строки других арендаторов
+func EnableRowLevelSecurity(cfg *pgxpool.Config) {
+	cfg.PrepareConn = func(ctx context.Context, conn *pgx.Conn) (bool, error) {
+		// без арендатора в контексте политики не пропускают ни одной строки
+		tenantID, _ := domain.TenantFromContext(ctx)
+
+		if _, err := conn.Exec(ctx, `SELECT set_config('app.tenant_id', $1, false)`, tenantID); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+}
@@ -0,0 +1,461 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testTask/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WebhookRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookRepository(db *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+const webhookColumns = `id, owner_id, url, secret, event_types, active, created_at, updated_at`
+
+func scanWebhook(row pgx.Row) (*domain.Webhook, error) {
+	var (
+		w      domain.Webhook
+		events []string
+	)
+
+	err := row.Scan(
+		&w.ID,
+		&w.OwnerID,
+		&w.URL,
+		&w.Secret,
+		&events,
+		&w.Active,
+		&w.CreatedAt,
+		&w.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	w.EventTypes = make([]domain.EventType, 0, len(events))
+	for _, e := range events {
+		w.EventTypes = append(w.EventTypes, domain.EventType(e))
+	}
+
+	return &w, nil
+}
+
+func eventTypeStrings(types []domain.EventType) []string {
+	result := make([]string, 0, len(types))
+	for _, t := range types {
+		result = append(result, string(t))
+	}
+
+	return result
+}
+
+// Все запросы ограничены арендатором клиента из контекста
+func (r *WebhookRepository) Create(ctx context.Context, w *domain.Webhook) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO webhooks (id, tenant_id, owner_id, url, secret, event_types, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at, updated_at
+	`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		w.ID,
+		tenantID,
+		w.OwnerID,
+		w.URL,
+		w.Secret,
+		eventTypeStrings(w.EventTypes),
+		w.Active,
+	).Scan(&w.CreatedAt, &w.UpdatedAt)
+}
+
+func (r *WebhookRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1 AND tenant_id = $2`
+
+	w, err := scanWebhook(conn(ctx, r.db).QueryRow(ctx, query, id, tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (r *WebhookRepository) List(ctx context.Context, ownerID *uuid.UUID) ([]domain.Webhook, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + webhookColumns + ` FROM webhooks
+		WHERE tenant_id = $1 AND ($2::uuid IS NULL OR owner_id = $2)
+		ORDER BY created_at, id
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, tenantID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []domain.Webhook
+
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *w)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, w *domain.Webhook) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE webhooks
+		SET url = $1,
+		    secret = $2,
+		    event_types = $3,
+		    active = $4,
+		    updated_at = NOW()
+		WHERE id = $5 AND tenant_id = $6
+		RETURNING updated_at
+	`
+
+	err = conn(ctx, r.db).QueryRow(ctx, query,
+		w.URL,
+		w.Secret,
+		eventTypeStrings(w.EventTypes),
+		w.Active,
+		w.ID,
+		tenantID,
+	).Scan(&w.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrWebhookNotFound
+	}
+
+	return err
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	cmd, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM webhooks WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+type WebhookDeliveryRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookDeliveryRepository(db *pgxpool.Pool) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: db}
+}
+
+func (r *WebhookDeliveryRepository) Enqueue(ctx context.Context, event *domain.Event, ownerID uuid.UUID) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (tenant_id, webhook_id, event_id, event_type, payload)
+		SELECT w.tenant_id, w.id, $2, $3, $4
+		FROM webhooks w
+		WHERE w.tenant_id = $1
+		  AND w.active
+		  AND $3 = ANY (w.event_types)
+		  AND (w.owner_id IS NULL OR w.owner_id = $5)
+		ON CONFLICT (webhook_id, event_id) DO NOTHING
+	`
+
+	_, err = conn(ctx, r.db).Exec(ctx, query, event.TenantID, event.ID, string(event.Type), payload, ownerID)
+	return err
+}
+
+var enqueueColumns = []string{"tenant_id", "webhook_id", "event_id", "event_type", "payload"}
+
+// активные вебхуки арендатора читаются одним запросом, подписчики каждого
+// события выбираются среди них так же, как в Enqueue
+func (r *WebhookDeliveryRepository) EnqueueBatch(ctx context.Context, events []domain.WebhookEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tenantID := events[0].Event.TenantID
+	q := conn(ctx, r.db)
+
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE tenant_id = $1 AND active ORDER BY id`
+
+	list, err := q.Query(ctx, query, tenantID)
+	if err != nil {
+		return err
+	}
+	defer list.Close()
+
+	var webhooks []domain.Webhook
+	for list.Next() {
+		w, err := scanWebhook(list)
+		if err != nil {
+			return err
+		}
+		webhooks = append(webhooks, *w)
+	}
+	list.Close()
+
+	if err = list.Err(); err != nil {
+		return err
+	}
+
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	var rows [][]any
+	for _, e := range events {
+		payload, err := json.Marshal(e.Event)
+		if err != nil {
+			return err
+		}
+
+		for _, w := range webhooks {
+			if slices.Contains(w.EventTypes, e.Event.Type) && (w.OwnerID == nil || *w.OwnerID == e.OwnerID) {
+				rows = append(rows, []any{tenantID, w.ID, e.Event.ID, string(e.Event.Type), payload})
+			}
+		}
+	}
+
+	return insertValues(ctx, q, "webhook_deliveries", enqueueColumns, rows, "ON CONFLICT (webhook_id, event_id) DO NOTHING")
+}
+
+const deliveryColumns = `d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+       d.next_attempt_at, d.last_status_code, d.last_error, d.created_at, d.delivered_at`
+
+func deliveryDest(d *domain.WebhookDelivery) []any {
+	return []any{
+		&d.ID,
+		&d.WebhookID,
+		&d.EventID,
+		&d.EventType,
+		&d.Payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.LastStatusCode,
+		&d.LastError,
+		&d.CreatedAt,
+		&d.DeliveredAt,
+	}
+}
+
+// доставки ищутся только среди вебхуков арендатора клиента
+func (r *WebhookDeliveryRepository) List(
+	ctx context.Context,
+	webhookID uuid.UUID,
+	filter *domain.DeliveryFilter,
+) ([]domain.WebhookDelivery, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.webhook_id = $1 AND d.tenant_id = $2
+		  AND ($3 = '' OR d.status = $3)
+		  AND ($4::bigint IS NULL OR d.id < $4)
+		ORDER BY d.id DESC
+		LIMIT $5
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, webhookID, tenantID, string(filter.Status), filter.BeforeID, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []domain.WebhookDelivery
+
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		if err = rows.Scan(deliveryDest(&d)...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookDeliveryRepository) GetByID(ctx context.Context, webhookID uuid.UUID, id int64) (*domain.WebhookDelivery, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.id = $1 AND d.webhook_id = $2 AND d.tenant_id = $3
+	`
+
+	var d domain.WebhookDelivery
+
+	err = conn(ctx, r.db).QueryRow(ctx, query, id, webhookID, tenantID).Scan(deliveryDest(&d)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+func (r *WebhookDeliveryRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookJob, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = NOW() + $2::double precision * interval '1 millisecond'
+		FROM (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		) due, webhooks w
+		WHERE d.id = due.id AND w.id = d.webhook_id
+		RETURNING ` + deliveryColumns + `, w.url, w.secret
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []domain.WebhookJob
+
+	for rows.Next() {
+		var job domain.WebhookJob
+		dest := append(deliveryDest(&job.Delivery), &job.URL, &job.Secret)
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING не сохраняет порядок подзапроса
+	slices.SortFunc(jobs, func(a, b domain.WebhookJob) int {
+		return cmp.Compare(a.Delivery.ID, b.Delivery.ID)
+	})
+
+	return jobs, nil
+}
+
+// время следующей попытки считается по часам БД, как и выборка в ClaimDue
+func (r *WebhookDeliveryRepository) RecordAttempt(ctx context.Context, id int64, a *domain.DeliveryAttempt) error {
+	var (
+		statusCode *int
+		lastError  *string
+	)
+	if a.StatusCode != 0 {
+		statusCode = &a.StatusCode
+	}
+	if a.Error != "" {
+		lastError = &a.Error
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2,
+		    attempts = attempts + 1,
+		    last_status_code = $3,
+		    last_error = $4,
+		    next_attempt_at = CASE WHEN $2 = 'pending' THEN NOW() + $5::double precision * interval '1 millisecond' END,
+		    delivered_at = CASE WHEN $2 = 'delivered' THEN NOW() END
+		WHERE id = $1
+	`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, id, string(a.Status), statusCode, lastError, a.RetryAfter.Milliseconds())
+	return err
+}
+
+func (r *WebhookDeliveryRepository) Requeue(ctx context.Context, id int64) error {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'pending',
+		    attempts = 0,
+		    next_attempt_at = NOW()
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	cmd, err := conn(ctx, r.db).Exec(ctx, query, id, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrWebhookDeliveryNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"testTask/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PriceHistoryRepository interface {
+	// изменение с тем же месяцем EffectiveFrom заменяет ранее запланированное
+	Upsert(ctx context.Context, change *domain.PriceChange) error
+	List(ctx context.Context, subscriptionID uuid.UUID) ([]domain.PriceChange, error)
+	// возвращает domain.ErrPriceChangeNotFound, если изменения с таким месяцем нет
+	Delete(ctx context.Context, subscriptionID uuid.UUID, effectiveFrom time.Time) error
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"testTask/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReminderRepository interface {
+	// арендаторы, у которых есть подписки; вызывается фоновым процессом без арендатора
+	Tenants(ctx context.Context) ([]string, error)
+	// подписки арендатора, у которых может быть списание в период [from, to],
+	// постранично в порядке id начиная после afterID
+	ListCandidates(ctx context.Context, from, to time.Time, afterID uuid.UUID, limit int) ([]domain.Subscription, error)
+	// закрепляет напоминание за процессом на lease. claimed == false - уведомление уже
+	// отправлено или его отправляет другой процесс; created - напоминание отмечено
+	// впервые и событие о нем еще не публиковалось
+	Claim(ctx context.Context, r *domain.Reminder, lease time.Duration) (claimed, created bool, err error)
+	// отмечает уведомление отправленным
+	MarkNotified(ctx context.Context, r *domain.Reminder) error
+	// снимает закрепление после неудачной отправки, чтобы повторить ее при следующем обходе
+	Release(ctx context.Context, r *domain.Reminder) error
+}
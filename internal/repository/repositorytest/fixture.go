@@ -0,0 +1,157 @@
+// Package repositorytest общие проверки реализаций интерфейсов repository.
+// Одни и те же тесты запускаются для каждого хранилища, поэтому реализации
+// не расходятся в фильтрах, подсчете сумм и ошибках
+package repositorytest
+
+import (
+	"context"
+	"strings"
+	"testTask/internal/domain"
+	"testTask/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Backend репозитории проверяемого хранилища. Каталог сервисов и история цен
+// нужны, потому что подписки ссылаются на сервисы, а суммы зависят от цен
+type Backend struct {
+	Subscriptions repository.SubscriptionRepository
+	Services      repository.ServiceRepository
+	Prices        repository.PriceHistoryRepository
+}
+
+// Factory возвращает репозитории для одного теста. Каждый тест работает
+// в своем арендаторе, поэтому тесты могут делить одну БД
+type Factory func(t *testing.T) Backend
+
+// fixture хранилище теста с отдельным арендатором и созданными в нем данными
+type fixture struct {
+	Backend
+	t *testing.T
+
+	ctx context.Context
+	// контекст другого арендатора того же хранилища
+	otherCtx context.Context
+	// уникальная часть названий сервисов: каталог общий для всех арендаторов
+	suffix string
+
+	subscriptions []uuid.UUID
+	services      []uuid.UUID
+}
+
+func newFixture(t *testing.T, newBackend Factory) *fixture {
+	t.Helper()
+
+	suffix := strings.ReplaceAll(uuid.NewString(), "-", "")[:12]
+
+	f := &fixture{
+		Backend:  newBackend(t),
+		t:        t,
+		ctx:      tenantContext("test-" + suffix),
+		otherCtx: tenantContext("other-" + suffix),
+		suffix:   suffix,
+	}
+
+	t.Cleanup(f.cleanup)
+
+	return f
+}
+
+func tenantContext(tenantID string) context.Context {
+	return domain.WithPrincipal(context.Background(), domain.SystemPrincipal(tenantID))
+}
+
+// cleanup удаляет данные теста, чтобы не копить их в общей БД
+func (f *fixture) cleanup() {
+	for _, id := range f.subscriptions {
+		_ = f.Subscriptions.Delete(f.ctx, id)
+	}
+
+	if _, err := f.Subscriptions.Purge(f.ctx, time.Now().Add(24*time.Hour)); err != nil {
+		f.t.Errorf("purge test subscriptions: %v", err)
+	}
+
+	for _, id := range f.services {
+		if err := f.Services.Delete(context.Background(), id); err != nil {
+			f.t.Errorf("delete test service %s: %v", id, err)
+		}
+	}
+}
+
+// service создает сервис каталога; к названию и синонимам добавляется суффикс теста
+func (f *fixture) service(name string, aliases ...string) *domain.Service {
+	f.t.Helper()
+
+	s := &domain.Service{
+		ID:       uuid.New(),
+		Name:     name + " " + f.suffix,
+		Currency: domain.DefaultCurrency,
+	}
+	for _, alias := range aliases {
+		s.Aliases = append(s.Aliases, alias+" "+f.suffix)
+	}
+
+	if err := f.Services.Create(context.Background(), s); err != nil {
+		f.t.Fatalf("create service %q: %v", s.Name, err)
+	}
+	f.services = append(f.services, s.ID)
+
+	return s
+}
+
+// subscription создает подписку арендатора теста: по умолчанию ежемесячную
+// за 100 RUB с января 2025 без даты окончания
+func (f *fixture) subscription(service *domain.Service, userID uuid.UUID, mod func(s *domain.Subscription)) *domain.Subscription {
+	f.t.Helper()
+
+	s := &domain.Subscription{
+		ID:            uuid.New(),
+		ServiceID:     service.ID,
+		Price:         100,
+		Currency:      domain.DefaultCurrency,
+		BillingPeriod: domain.BillingPeriodMonth,
+		UserID:        userID,
+		StartDate:     month(2025, time.January),
+		Status:        domain.StatusActive,
+	}
+	if mod != nil {
+		mod(s)
+	}
+
+	if err := f.Subscriptions.Create(f.ctx, s); err != nil {
+		f.t.Fatalf("create subscription: %v", err)
+	}
+	f.subscriptions = append(f.subscriptions, s.ID)
+
+	return s
+}
+
+func (f *fixture) get(id uuid.UUID) *domain.Subscription {
+	f.t.Helper()
+
+	s, err := f.Subscriptions.GetByID(f.ctx, id)
+	if err != nil {
+		f.t.Fatalf("get subscription %s: %v", id, err)
+	}
+
+	return s
+}
+
+func (f *fixture) delete(id uuid.UUID) {
+	f.t.Helper()
+
+	if err := f.Subscriptions.Delete(f.ctx, id); err != nil {
+		f.t.Fatalf("delete subscription %s: %v", id, err)
+	}
+}
+
+// month первое число месяца в UTC, как даты MM-YYYY из API
+func month(year int, m time.Month) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
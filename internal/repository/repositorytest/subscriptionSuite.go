@@ -0,0 +1,836 @@
+package repositorytest
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testTask/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RunSubscriptionRepository проверяет реализацию repository.SubscriptionRepository:
+// сохранение и чтение, пакетную запись, фильтры списка, сортировку и пагинацию, границы периода
+// в подсчете сумм, ошибки отсутствия подписки и параллельную запись
+func RunSubscriptionRepository(t *testing.T, newBackend Factory) {
+	t.Run("CRUD", func(t *testing.T) { testCRUD(t, newFixture(t, newBackend)) })
+	t.Run("CreateBatch", func(t *testing.T) { testCreateBatch(t, newFixture(t, newBackend)) })
+	t.Run("StatusTransitions", func(t *testing.T) { testStatusTransitions(t, newFixture(t, newBackend)) })
+	t.Run("SoftDelete", func(t *testing.T) { testSoftDelete(t, newFixture(t, newBackend)) })
+	t.Run("NotFound", func(t *testing.T) { testNotFound(t, newFixture(t, newBackend)) })
+	t.Run("ListFilters", func(t *testing.T) { testListFilters(t, newFixture(t, newBackend)) })
+	t.Run("ListPagination", func(t *testing.T) { testListPagination(t, newFixture(t, newBackend)) })
+	t.Run("CalculateTotal", func(t *testing.T) { testCalculateTotal(t, newFixture(t, newBackend)) })
+	t.Run("ConcurrentWrites", func(t *testing.T) { testConcurrentWrites(t, newFixture(t, newBackend)) })
+}
+
+func testCRUD(t *testing.T, f *fixture) {
+	music := f.service("Yandex Plus")
+	video := f.service("Netflix")
+	userID := uuid.New()
+
+	created := f.subscription(music, userID, func(s *domain.Subscription) {
+		s.Price = 39900
+		s.BillingPeriod = domain.BillingPeriodQuarter
+		s.StartDate = month(2025, time.February)
+		s.EndDate = ptr(month(2025, time.December))
+		s.TrialUntil = ptr(month(2025, time.March))
+	})
+
+	if created.CreatedAt.IsZero() {
+		t.Error("Create did not set CreatedAt")
+	}
+
+	got := f.get(created.ID)
+	assertSubscription(t, got, created, music.Name)
+
+	if got.Status != domain.StatusActive {
+		t.Errorf("Status = %q, want %q", got.Status, domain.StatusActive)
+	}
+	if len(got.Pauses) != 0 || got.CancelAt != nil || got.DeletedAt != nil {
+		t.Errorf("new subscription has pauses %v, cancel_at %v, deleted_at %v", got.Pauses, got.CancelAt, got.DeletedAt)
+	}
+
+	// Update меняет поля подписки, но не дату создания
+	updated := *got
+	updated.ServiceID = video.ID
+	updated.Price = 1299
+	updated.Currency = "USD"
+	updated.BillingPeriod = domain.BillingPeriodMonth
+	updated.StartDate = month(2025, time.January)
+	updated.EndDate = nil
+	updated.TrialUntil = nil
+
+	if err := f.Subscriptions.Update(f.ctx, &updated); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	got = f.get(created.ID)
+	assertSubscription(t, got, &updated, video.Name)
+
+	if !got.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("CreatedAt changed on update: %v -> %v", created.CreatedAt, got.CreatedAt)
+	}
+
+	// пробный период, который еще не закончился, читается как StatusTrial
+	trial := f.subscription(music, userID, func(s *domain.Subscription) {
+		s.TrialUntil = ptr(month(time.Now().Year()+1, time.January))
+	})
+	if got := f.get(trial.ID); got.Status != domain.StatusTrial {
+		t.Errorf("Status in trial = %q, want %q", got.Status, domain.StatusTrial)
+	}
+
+	// подписка на сервис не из каталога не сохраняется
+	missing := &domain.Subscription{
+		ID:            uuid.New(),
+		ServiceID:     uuid.New(),
+		Price:         100,
+		Currency:      domain.DefaultCurrency,
+		BillingPeriod: domain.BillingPeriodMonth,
+		UserID:        userID,
+		StartDate:     month(2025, time.January),
+	}
+	var verr *domain.ValidationError
+	if err := f.Subscriptions.Create(f.ctx, missing); !errors.As(err, &verr) {
+		t.Errorf("Create with unknown service: got %v, want validation error", err)
+	}
+}
+
+func testCreateBatch(t *testing.T, f *fixture) {
+	music := f.service("Yandex Plus")
+	video := f.service("Netflix")
+	userID := uuid.New()
+
+	batch := []domain.Subscription{
+		{
+			ID:            uuid.New(),
+			ServiceID:     music.ID,
+			Price:         29900,
+			Currency:      domain.DefaultCurrency,
+			BillingPeriod: domain.BillingPeriodMonth,
+			UserID:        userID,
+			StartDate:     month(2025, time.January),
+			Status:        domain.StatusActive,
+		},
+		{
+			ID:            uuid.New(),
+			ServiceID:     video.ID,
+			Price:         1299,
+			Currency:      "USD",
+			BillingPeriod: domain.BillingPeriodYear,
+			UserID:        userID,
+			StartDate:     month(2025, time.March),
+			EndDate:       ptr(month(2026, time.February)),
+			TrialUntil:    ptr(month(2025, time.April)),
+			Status:        domain.StatusActive,
+		},
+	}
+
+	if err := f.Subscriptions.CreateBatch(f.ctx, batch); err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+
+	services := []*domain.Service{music, video}
+	for i := range batch {
+		f.subscriptions = append(f.subscriptions, batch[i].ID)
+
+		if batch[i].CreatedAt.IsZero() {
+			t.Errorf("CreateBatch did not set CreatedAt of %s", batch[i].ID)
+		}
+
+		got := f.get(batch[i].ID)
+		assertSubscription(t, got, &batch[i], services[i].Name)
+		if !got.CreatedAt.Equal(batch[i].CreatedAt) {
+			t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, batch[i].CreatedAt)
+		}
+	}
+
+	// подписка на сервис не из каталога отменяет весь пакет
+	valid := batch[0]
+	valid.ID = uuid.New()
+	missing := batch[0]
+	missing.ID = uuid.New()
+	missing.ServiceID = uuid.New()
+
+	var verr *domain.ValidationError
+	if err := f.Subscriptions.CreateBatch(f.ctx, []domain.Subscription{valid, missing}); !errors.As(err, &verr) {
+		t.Errorf("CreateBatch with unknown service: got %v, want validation error", err)
+	}
+	if _, err := f.Subscriptions.GetByID(f.ctx, valid.ID); !errors.Is(err, domain.ErrSubscriptionNotFound) {
+		t.Errorf("GetByID after failed batch: got %v, want ErrSubscriptionNotFound", err)
+	}
+}
+
+func assertSubscription(t *testing.T, got, want *domain.Subscription, serviceName string) {
+	t.Helper()
+
+	if got.ID != want.ID || got.ServiceID != want.ServiceID || got.UserID != want.UserID {
+		t.Errorf("ids = (%s, %s, %s), want (%s, %s, %s)",
+			got.ID, got.ServiceID, got.UserID, want.ID, want.ServiceID, want.UserID)
+	}
+	if got.ServiceName != serviceName {
+		t.Errorf("ServiceName = %q, want %q", got.ServiceName, serviceName)
+	}
+	if got.Price != want.Price || got.Currency != want.Currency || got.BillingPeriod != want.BillingPeriod {
+		t.Errorf("price = %d %s per %s, want %d %s per %s",
+			got.Price, got.Currency, got.BillingPeriod, want.Price, want.Currency, want.BillingPeriod)
+	}
+	if !got.StartDate.Equal(want.StartDate) {
+		t.Errorf("StartDate = %v, want %v", got.StartDate, want.StartDate)
+	}
+	if !equalDates(got.EndDate, want.EndDate) {
+		t.Errorf("EndDate = %v, want %v", got.EndDate, want.EndDate)
+	}
+	if !equalDates(got.TrialUntil, want.TrialUntil) {
+		t.Errorf("TrialUntil = %v, want %v", got.TrialUntil, want.TrialUntil)
+	}
+}
+
+func equalDates(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
+
+func testStatusTransitions(t *testing.T, f *fixture) {
+	sub := f.subscription(f.service("Spotify"), uuid.New(), nil)
+	now := time.Now()
+
+	save := func(apply func(s *domain.Subscription, now time.Time) error) *domain.Subscription {
+		t.Helper()
+
+		s := f.get(sub.ID)
+		if err := apply(s, now); err != nil {
+			t.Fatalf("transition: %v", err)
+		}
+		if err := f.Subscriptions.UpdateStatus(f.ctx, s); err != nil {
+			t.Fatalf("UpdateStatus: %v", err)
+		}
+
+		return f.get(sub.ID)
+	}
+
+	paused := save((*domain.Subscription).Pause)
+	if paused.Status != domain.StatusPaused || len(paused.Pauses) != 1 || paused.Pauses[0].Until != nil {
+		t.Fatalf("after pause: status %q, pauses %v", paused.Status, paused.Pauses)
+	}
+
+	resumed := save((*domain.Subscription).Resume)
+	if resumed.Status != domain.StatusActive || len(resumed.Pauses) != 1 || resumed.Pauses[0].Until == nil {
+		t.Fatalf("after resume: status %q, pauses %v", resumed.Status, resumed.Pauses)
+	}
+
+	cancelled := save((*domain.Subscription).Cancel)
+	if cancelled.Status != domain.StatusCancelled || cancelled.CancelAt == nil || cancelled.EndDate == nil {
+		t.Fatalf("after cancel: status %q, cancel_at %v, end_date %v", cancelled.Status, cancelled.CancelAt, cancelled.EndDate)
+	}
+}
+
+func testSoftDelete(t *testing.T, f *fixture) {
+	service := f.service("Kinopoisk")
+	sub := f.subscription(service, uuid.New(), nil)
+
+	f.delete(sub.ID)
+
+	if _, err := f.Subscriptions.GetByID(f.ctx, sub.ID); !errors.Is(err, domain.ErrSubscriptionNotFound) {
+		t.Errorf("GetByID after delete: got %v, want ErrSubscriptionNotFound", err)
+	}
+	if err := f.Subscriptions.Delete(f.ctx, sub.ID); !errors.Is(err, domain.ErrSubscriptionNotFound) {
+		t.Errorf("second Delete: got %v, want ErrSubscriptionNotFound", err)
+	}
+
+	deleted, err := f.Subscriptions.GetDeleted(f.ctx, sub.ID)
+	if err != nil {
+		t.Fatalf("GetDeleted: %v", err)
+	}
+	if deleted.DeletedAt == nil {
+		t.Error("deleted subscription has no DeletedAt")
+	}
+
+	if err := f.Subscriptions.Restore(f.ctx, sub.ID); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got := f.get(sub.ID); got.DeletedAt != nil {
+		t.Errorf("restored subscription has DeletedAt %v", got.DeletedAt)
+	}
+	if err := f.Subscriptions.Restore(f.ctx, sub.ID); !errors.Is(err, domain.ErrSubscriptionNotFound) {
+		t.Errorf("Restore of not deleted: got %v, want ErrSubscriptionNotFound", err)
+	}
+
+	// Purge удаляет только подписки, удаленные раньше before
+	f.delete(sub.ID)
+
+	purged, err := f.Subscriptions.Purge(f.ctx, time.Now().Add(-24*time.Hour))
+	if err != nil || len(purged) != 0 {
+		t.Fatalf("Purge before deletion = %d, %v; want 0", len(purged), err)
+	}
+
+	purged, err = f.Subscriptions.Purge(f.ctx, time.Now().Add(24*time.Hour))
+	if err != nil || len(purged) != 1 {
+		t.Fatalf("Purge after deletion = %d, %v; want 1", len(purged), err)
+	}
+	if purged[0].ID != sub.ID || purged[0].DeletedAt == nil || purged[0].ServiceName != service.Name {
+		t.Errorf("purged = %+v, want deleted subscription %s", purged[0], sub.ID)
+	}
+
+	if _, err := f.Subscriptions.GetDeleted(f.ctx, sub.ID); !errors.Is(err, domain.ErrSubscriptionNotFound) {
+		t.Errorf("GetDeleted after purge: got %v, want ErrSubscriptionNotFound", err)
+	}
+}
+
+func testNotFound(t *testing.T, f *fixture) {
+	sub := f.subscription(f.service("Okko"), uuid.New(), nil)
+	unknown := *sub
+	unknown.ID = uuid.New()
+
+	calls := map[string]func(ctx context.Context, s *domain.Subscription) error{
+		"GetByID": func(ctx context.Context, s *domain.Subscription) error {
+			_, err := f.Subscriptions.GetByID(ctx, s.ID)
+			return err
+		},
+		"GetDeleted": func(ctx context.Context, s *domain.Subscription) error {
+			_, err := f.Subscriptions.GetDeleted(ctx, s.ID)
+			return err
+		},
+		"Update": func(ctx context.Context, s *domain.Subscription) error {
+			return f.Subscriptions.Update(ctx, s)
+		},
+		"UpdateStatus": func(ctx context.Context, s *domain.Subscription) error {
+			return f.Subscriptions.UpdateStatus(ctx, s)
+		},
+		"Delete": func(ctx context.Context, s *domain.Subscription) error {
+			return f.Subscriptions.Delete(ctx, s.ID)
+		},
+		"Restore": func(ctx context.Context, s *domain.Subscription) error {
+			return f.Subscriptions.Restore(ctx, s.ID)
+		},
+	}
+
+	for name, call := range calls {
+		if err := call(f.ctx, &unknown); !errors.Is(err, domain.ErrSubscriptionNotFound) {
+			t.Errorf("%s of unknown subscription: got %v, want ErrSubscriptionNotFound", name, err)
+		}
+
+		// подписка другого арендатора не отличается от несуществующей
+		if err := call(f.otherCtx, sub); !errors.Is(err, domain.ErrSubscriptionNotFound) {
+			t.Errorf("%s from other tenant: got %v, want ErrSubscriptionNotFound", name, err)
+		}
+
+		// без арендатора хранилище не выполняет запросов
+		if err := call(context.Background(), sub); !errors.Is(err, domain.ErrNoTenant) {
+			t.Errorf("%s without tenant: got %v, want ErrNoTenant", name, err)
+		}
+	}
+
+	// подписка осталась нетронутой запросами другого арендатора
+	if got := f.get(sub.ID); got.DeletedAt != nil {
+		t.Errorf("subscription deleted from other tenant")
+	}
+
+	subs, err := f.Subscriptions.List(f.otherCtx, &domain.ListFilter{Sort: domain.SortByCreatedAt, Limit: 10})
+	if err != nil || len(subs) != 0 {
+		t.Errorf("List from other tenant = %d subscriptions, %v; want none", len(subs), err)
+	}
+
+	totals, err := f.Subscriptions.CalculateTotal(f.otherCtx, &domain.TotalFilter{
+		From: month(2025, time.January),
+		To:   month(2025, time.December),
+	})
+	if err != nil || len(totals) != 0 {
+		t.Errorf("CalculateTotal from other tenant = %v, %v; want none", totals, err)
+	}
+}
+
+// listData подписки для проверки фильтров и сортировки списка
+type listData struct {
+	userA, userB uuid.UUID
+	// s5 удалена
+	s1, s2, s3, s4, s5 *domain.Subscription
+}
+
+func newListData(f *fixture) *listData {
+	plus := f.service("Yandex Plus", "YaPlus")
+	netflix := f.service("Netflix")
+	percent := f.service("100% Music")
+
+	d := &listData{userA: uuid.New(), userB: uuid.New()}
+
+	d.s1 = f.subscription(plus, d.userA, func(s *domain.Subscription) {
+		s.Price = 300
+		s.StartDate = month(2025, time.January)
+		s.EndDate = ptr(month(2025, time.March))
+	})
+	d.s2 = f.subscription(plus, d.userB, func(s *domain.Subscription) {
+		s.Price = 500
+		s.StartDate = month(2025, time.February)
+	})
+	d.s3 = f.subscription(netflix, d.userA, func(s *domain.Subscription) {
+		s.Price = 1000
+		s.StartDate = month(2025, time.April)
+		s.EndDate = ptr(month(2025, time.December))
+	})
+	d.s4 = f.subscription(percent, d.userB, func(s *domain.Subscription) {
+		s.Price = 300
+		s.StartDate = month(2024, time.November)
+		s.EndDate = ptr(month(2025, time.January))
+	})
+	d.s5 = f.subscription(netflix, d.userA, func(s *domain.Subscription) {
+		s.Price = 700
+		s.StartDate = month(2025, time.February)
+	})
+	f.delete(d.s5.ID)
+
+	return d
+}
+
+func testListFilters(t *testing.T, f *fixture) {
+	d := newListData(f)
+	suffix := strings.ToUpper(f.suffix)
+
+	ids := func(subs ...*domain.Subscription) []uuid.UUID {
+		result := make([]uuid.UUID, 0, len(subs))
+		for _, s := range subs {
+			result = append(result, s.ID)
+		}
+		return result
+	}
+
+	cases := []struct {
+		name   string
+		filter domain.ListFilter
+		want   []uuid.UUID
+	}{
+		{"no filters", domain.ListFilter{}, ids(d.s1, d.s2, d.s3, d.s4)},
+		{"include deleted", domain.ListFilter{IncludeDeleted: true}, ids(d.s1, d.s2, d.s3, d.s4, d.s5)},
+		{"owner", domain.ListFilter{OwnerID: &d.userA}, ids(d.s1, d.s3)},
+		{"user", domain.ListFilter{UserID: &d.userB}, ids(d.s2, d.s4)},
+		{"owner and other user", domain.ListFilter{OwnerID: &d.userA, UserID: &d.userB}, nil},
+		{"service name ignores case", domain.ListFilter{ServiceName: ptr("yandex PLUS " + suffix)}, ids(d.s1, d.s2)},
+		{"service alias", domain.ListFilter{ServiceName: ptr("yaplus " + suffix)}, ids(d.s1, d.s2)},
+		{"service name is not a substring", domain.ListFilter{ServiceName: ptr("Yandex")}, nil},
+		{"search by name", domain.ListFilter{Search: "plus " + suffix}, ids(d.s1, d.s2)},
+		{"search by alias", domain.ListFilter{Search: "YAPL"}, ids(d.s1, d.s2)},
+		{"search is literal", domain.ListFilter{Search: "%"}, ids(d.s4)},
+		{"search underscore is literal", domain.ListFilter{Search: "_"}, nil},
+		{"active on end month", domain.ListFilter{ActiveOn: ptr(month(2025, time.January))}, ids(d.s1, d.s4)},
+		{"active on", domain.ListFilter{ActiveOn: ptr(month(2025, time.March))}, ids(d.s1, d.s2)},
+		{"start from", domain.ListFilter{StartFrom: ptr(month(2025, time.February))}, ids(d.s2, d.s3)},
+		{"start to", domain.ListFilter{StartTo: ptr(month(2025, time.January))}, ids(d.s1, d.s4)},
+		{"start range", domain.ListFilter{
+			StartFrom: ptr(month(2025, time.January)),
+			StartTo:   ptr(month(2025, time.February)),
+		}, ids(d.s1, d.s2)},
+		{"end from skips open-ended", domain.ListFilter{EndFrom: ptr(month(2025, time.March))}, ids(d.s1, d.s3)},
+		{"end to", domain.ListFilter{EndTo: ptr(month(2025, time.January))}, ids(d.s4)},
+		{"price min", domain.ListFilter{PriceMin: ptr(500)}, ids(d.s2, d.s3)},
+		{"price max", domain.ListFilter{PriceMax: ptr(300)}, ids(d.s1, d.s4)},
+		{"price range", domain.ListFilter{PriceMin: ptr(300), PriceMax: ptr(500)}, ids(d.s1, d.s2, d.s4)},
+		{"open-ended", domain.ListFilter{OpenEnded: ptr(true)}, ids(d.s2)},
+		{"with end date", domain.ListFilter{OpenEnded: ptr(false)}, ids(d.s1, d.s3, d.s4)},
+		{"owner, search and end date", domain.ListFilter{
+			OwnerID:   &d.userA,
+			Search:    "netflix",
+			OpenEnded: ptr(false),
+		}, ids(d.s3)},
+		{"deleted open-ended of owner", domain.ListFilter{
+			OwnerID:        &d.userA,
+			OpenEnded:      ptr(true),
+			IncludeDeleted: true,
+		}, ids(d.s5)},
+		{"active, price and service", domain.ListFilter{
+			ActiveOn:    ptr(month(2025, time.February)),
+			PriceMax:    ptr(400),
+			ServiceName: ptr("Yandex Plus " + f.suffix),
+		}, ids(d.s1)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter := tc.filter
+			filter.Sort = domain.SortByCreatedAt
+			filter.Limit = domain.MaxListLimit
+
+			subs, err := f.Subscriptions.List(f.ctx, &filter)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+
+			got := make([]uuid.UUID, 0, len(subs))
+			for _, s := range subs {
+				got = append(got, s.ID)
+			}
+
+			if !sameIDs(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// sameIDs совпадение наборов без учета порядка
+func sameIDs(a, b []uuid.UUID) bool {
+	sortIDs := func(ids []uuid.UUID) []uuid.UUID {
+		ids = slices.Clone(ids)
+		slices.SortFunc(ids, func(x, y uuid.UUID) int { return bytes.Compare(x[:], y[:]) })
+		return ids
+	}
+
+	return slices.Equal(sortIDs(a), sortIDs(b))
+}
+
+func testListPagination(t *testing.T, f *fixture) {
+	newListData(f)
+
+	all, err := f.Subscriptions.List(f.ctx, &domain.ListFilter{Sort: domain.SortByCreatedAt, Limit: domain.MaxListLimit})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	sorts := map[domain.ListSort]func(a, b *domain.Subscription) int{
+		domain.SortByStartDate:   func(a, b *domain.Subscription) int { return a.StartDate.Compare(b.StartDate) },
+		domain.SortByPrice:       func(a, b *domain.Subscription) int { return cmp.Compare(a.Price, b.Price) },
+		domain.SortByServiceName: func(a, b *domain.Subscription) int { return strings.Compare(a.ServiceName, b.ServiceName) },
+		domain.SortByCreatedAt:   func(a, b *domain.Subscription) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	}
+
+	for sort, compare := range sorts {
+		for _, desc := range []bool{false, true} {
+			// id - второй ключ сортировки, поэтому порядок однозначен и при равных значениях
+			want := slices.Clone(all)
+			slices.SortFunc(want, func(a, b domain.Subscription) int {
+				c := cmp.Or(compare(&a, &b), bytes.Compare(a.ID[:], b.ID[:]))
+				if desc {
+					return -c
+				}
+				return c
+			})
+
+			filter := &domain.ListFilter{Sort: sort, Desc: desc, Limit: 2}
+
+			var got []domain.Subscription
+			for page := 0; ; page++ {
+				if page > len(all) {
+					t.Fatalf("sort %s desc=%t: pagination does not end", sort, desc)
+				}
+
+				subs, err := f.Subscriptions.List(f.ctx, filter)
+				if err != nil {
+					t.Fatalf("sort %s desc=%t: List: %v", sort, desc, err)
+				}
+				got = append(got, subs...)
+
+				if len(subs) < filter.Limit {
+					break
+				}
+
+				cursor := filter.CursorAfter(&subs[len(subs)-1])
+				filter.Cursor = &cursor
+			}
+
+			if !reflect.DeepEqual(subscriptionIDs(got), subscriptionIDs(want)) {
+				t.Errorf("sort %s desc=%t: got %v, want %v", sort, desc, subscriptionIDs(got), subscriptionIDs(want))
+			}
+		}
+	}
+}
+
+func subscriptionIDs(subs []domain.Subscription) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(subs))
+	for _, s := range subs {
+		ids = append(ids, s.ID)
+	}
+
+	return ids
+}
+
+func testCalculateTotal(t *testing.T, f *fixture) {
+	music := f.service("Apple Music")
+	video := f.service("YouTube Premium")
+	userA, userB := uuid.New(), uuid.New()
+
+	// период отчета - март..май 2025
+	f.subscription(music, userA, func(s *domain.Subscription) {
+		// месяц окончания оплачивается: март
+		s.Price = 100
+		s.EndDate = ptr(month(2025, time.March))
+	})
+	f.subscription(music, userA, func(s *domain.Subscription) {
+		// месяц начала совпадает с концом периода: май
+		s.Price = 200
+		s.StartDate = month(2025, time.May)
+	})
+	f.subscription(music, userA, func(s *domain.Subscription) {
+		// начинается после периода
+		s.Price = 400
+		s.StartDate = month(2025, time.June)
+	})
+	f.subscription(music, userA, func(s *domain.Subscription) {
+		// заканчивается до периода
+		s.Price = 800
+		s.StartDate = month(2024, time.January)
+		s.EndDate = ptr(month(2025, time.February))
+	})
+	f.subscription(video, userA, func(s *domain.Subscription) {
+		// январь, апрель, июль: в период попадает апрель
+		s.Price = 1000
+		s.BillingPeriod = domain.BillingPeriodQuarter
+	})
+	f.subscription(video, userA, func(s *domain.Subscription) {
+		// март 2024 и март 2025
+		s.Price = 5000
+		s.BillingPeriod = domain.BillingPeriodYear
+		s.StartDate = month(2024, time.March)
+	})
+	f.subscription(video, userA, func(s *domain.Subscription) {
+		// 1, 8, 15, 22 и 29 мая
+		s.Price = 10
+		s.BillingPeriod = domain.BillingPeriodWeek
+		s.StartDate = month(2025, time.May)
+	})
+	f.subscription(video, userB, func(s *domain.Subscription) {
+		s.Price = 7
+		s.Currency = "USD"
+		s.StartDate = month(2025, time.April)
+		s.EndDate = ptr(month(2025, time.April))
+	})
+	f.subscription(music, userB, func(s *domain.Subscription) {
+		// пробный период до мая
+		s.Price = 30
+		s.TrialUntil = ptr(month(2025, time.May))
+	})
+	deleted := f.subscription(music, userB, func(s *domain.Subscription) {
+		s.Price = 100000
+	})
+	f.delete(deleted.ID)
+
+	// цена меняется с апреля: 1000 в марте, 2000 в апреле и мае
+	repriced := f.subscription(music, userB, func(s *domain.Subscription) {
+		s.Price = 1000
+	})
+	change := &domain.PriceChange{SubscriptionID: repriced.ID, Price: 2000, EffectiveFrom: month(2025, time.April)}
+	if err := f.Prices.Upsert(f.ctx, change); err != nil {
+		t.Fatalf("Upsert price change: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		filter domain.TotalFilter
+		want   []domain.Money
+	}{
+		{
+			name:   "period",
+			filter: domain.TotalFilter{From: month(2025, time.March), To: month(2025, time.May)},
+			want:   []domain.Money{{Amount: 11380, Currency: "RUB"}, {Amount: 7, Currency: "USD"}},
+		},
+		{
+			name:   "first month of period",
+			filter: domain.TotalFilter{From: month(2025, time.March), To: month(2025, time.March)},
+			want:   []domain.Money{{Amount: 6100, Currency: "RUB"}},
+		},
+		{
+			name:   "last month of period",
+			filter: domain.TotalFilter{From: month(2025, time.May), To: month(2025, time.May)},
+			want:   []domain.Money{{Amount: 2280, Currency: "RUB"}},
+		},
+		{
+			name: "user",
+			filter: domain.TotalFilter{
+				UserID: &userB,
+				From:   month(2025, time.March),
+				To:     month(2025, time.May),
+			},
+			want: []domain.Money{{Amount: 5030, Currency: "RUB"}, {Amount: 7, Currency: "USD"}},
+		},
+		{
+			name: "owner and service",
+			filter: domain.TotalFilter{
+				OwnerID:     &userA,
+				ServiceName: ptr(strings.ToUpper(video.Name)),
+				From:        month(2025, time.March),
+				To:          month(2025, time.May),
+			},
+			want: []domain.Money{{Amount: 6050, Currency: "RUB"}},
+		},
+		{
+			name: "unknown user",
+			filter: domain.TotalFilter{
+				UserID: ptr(uuid.New()),
+				From:   month(2025, time.March),
+				To:     month(2025, time.May),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := f.Subscriptions.CalculateTotal(f.ctx, &tc.filter)
+			if err != nil {
+				t.Fatalf("CalculateTotal: %v", err)
+			}
+
+			if len(got) != 0 || len(tc.want) != 0 {
+				if !reflect.DeepEqual(got, tc.want) {
+					t.Errorf("got %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+
+	t.Run("grouped", func(t *testing.T) {
+		got, err := f.Subscriptions.CalculateGroupedTotal(f.ctx, &domain.TotalFilter{
+			UserID:  &userA,
+			From:    month(2025, time.March),
+			To:      month(2025, time.May),
+			GroupBy: []domain.TotalGroupBy{domain.GroupByServiceName},
+		})
+		if err != nil {
+			t.Fatalf("CalculateGroupedTotal: %v", err)
+		}
+
+		want := []domain.GroupedTotal{
+			{ServiceName: &music.Name, Total: 300, Currency: "RUB"},
+			{ServiceName: &video.Name, Total: 6050, Currency: "RUB"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("monthly breakdown", func(t *testing.T) {
+		got, err := f.Subscriptions.CalculateMonthlyBreakdown(f.ctx, &domain.TotalFilter{
+			UserID:      &userB,
+			ServiceName: &video.Name,
+			From:        month(2025, time.March),
+			To:          month(2025, time.May),
+		})
+		if err != nil {
+			t.Fatalf("CalculateMonthlyBreakdown: %v", err)
+		}
+
+		// месяцы без подписок возвращаются с нулевой суммой и пустой валютой
+		want := []domain.MonthlyTotal{
+			{Month: month(2025, time.March)},
+			{Month: month(2025, time.April), Amount: 7, Currency: "USD", Subscriptions: 1},
+			{Month: month(2025, time.May)},
+		}
+		if len(got) != len(want) {
+			t.Fatalf("got %+v, want %+v", got, want)
+		}
+		for i := range want {
+			if !got[i].Month.Equal(want[i].Month) || got[i].Amount != want[i].Amount ||
+				got[i].Currency != want[i].Currency || got[i].Subscriptions != want[i].Subscriptions {
+				t.Errorf("month %d: got %+v, want %+v", i, got[i], want[i])
+			}
+		}
+	})
+}
+
+func testConcurrentWrites(t *testing.T, f *fixture) {
+	const writers = 20
+
+	service := f.service("Dropbox")
+	userID, otherUserID := uuid.New(), uuid.New()
+	updated := f.subscription(service, otherUserID, nil)
+	deleted := f.subscription(service, otherUserID, nil)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created []uuid.UUID
+		deletes int
+	)
+
+	for i := range writers {
+		wg.Add(3)
+
+		go func() {
+			defer wg.Done()
+
+			s := &domain.Subscription{
+				ID:            uuid.New(),
+				ServiceID:     service.ID,
+				Price:         100 + i,
+				Currency:      domain.DefaultCurrency,
+				BillingPeriod: domain.BillingPeriodMonth,
+				UserID:        userID,
+				StartDate:     month(2025, time.January),
+			}
+			if err := f.Subscriptions.Create(f.ctx, s); err != nil {
+				t.Errorf("concurrent Create: %v", err)
+				return
+			}
+
+			mu.Lock()
+			created = append(created, s.ID)
+			mu.Unlock()
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			s := *updated
+			s.Price = 1000 + i
+			s.StartDate = month(2025, time.Month(i%12+1))
+			if err := f.Subscriptions.Update(f.ctx, &s); err != nil {
+				t.Errorf("concurrent Update: %v", err)
+			}
+		}()
+
+		// удалить подписку может только один из параллельных запросов
+		go func() {
+			defer wg.Done()
+
+			err := f.Subscriptions.Delete(f.ctx, deleted.ID)
+			switch {
+			case err == nil:
+				mu.Lock()
+				deletes++
+				mu.Unlock()
+			case !errors.Is(err, domain.ErrSubscriptionNotFound):
+				t.Errorf("concurrent Delete: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	f.subscriptions = append(f.subscriptions, created...)
+
+	if deletes != 1 {
+		t.Errorf("subscription deleted %d times, want once", deletes)
+	}
+
+	subs, err := f.Subscriptions.List(f.ctx, &domain.ListFilter{
+		UserID: &userID,
+		Sort:   domain.SortByPrice,
+		Limit:  domain.MaxListLimit,
+	})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	if len(subs) != writers {
+		t.Fatalf("List = %d subscriptions, want %d", len(subs), writers)
+	}
+	for i, s := range subs {
+		if s.Price != 100+i {
+			t.Errorf("subscription %d price = %d, want %d", i, s.Price, 100+i)
+		}
+	}
+
+	// сохраняется одно из параллельных изменений целиком, без смешения полей
+	got := f.get(updated.ID)
+	i := got.Price - 1000
+	if i < 0 || i >= writers || !got.StartDate.Equal(month(2025, time.Month(i%12+1))) {
+		t.Errorf("updated subscription has price %d and start date %v", got.Price, got.StartDate)
+	}
+}
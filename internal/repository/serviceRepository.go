@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"testTask/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Если сервис не найден, возвращается domain.ErrServiceNotFound
+type ServiceRepository interface {
+	Create(ctx context.Context, s *domain.Service) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Service, error)
+	// ищет сервис по названию или синониму без учета регистра
+	FindByName(ctx context.Context, name string) (*domain.Service, error)
+	Update(ctx context.Context, s *domain.Service) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, category *string) ([]domain.Service, error)
+}
@@ -3,15 +3,34 @@ package repository
 import (
 	"context"
 	"testTask/internal/domain"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// Методы, работающие с одной подпиской, возвращают domain.ErrSubscriptionNotFound,
+// если ее нет. Удаленные подписки, кроме GetDeleted и Restore, считаются несуществующими
 type SubscriptionRepository interface {
 	Create(ctx context.Context, s *domain.Subscription) error
+	// сохраняет подписки одним пакетом: все или ни одной; заполняет CreatedAt
+	CreateBatch(ctx context.Context, subs []domain.Subscription) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error)
 	Update(ctx context.Context, s *domain.Subscription) error
+	// сохраняет результат перехода состояния: Status, CancelAt, EndDate и последнюю паузу
+	UpdateStatus(ctx context.Context, s *domain.Subscription) error
+	// помечает подписку удаленной; запись остается до Purge
 	Delete(ctx context.Context, id uuid.UUID) error
-	List(ctx context.Context, userId *uuid.UUID, serviceName *string) ([]domain.Subscription, error)
-	CalculateTotal(ctx context.Context, filter *domain.TotalFilter) (int, error)
+	// возвращает удаленную подписку
+	GetDeleted(ctx context.Context, id uuid.UUID) (*domain.Subscription, error)
+	// снимает пометку об удалении
+	Restore(ctx context.Context, id uuid.UUID) error
+	// окончательно удаляет подписки, удаленные раньше before; возвращает их
+	// состояние на момент удаления
+	Purge(ctx context.Context, before time.Time) ([]domain.Subscription, error)
+	// возвращает не больше filter.Limit подписок после filter.Cursor в порядке filter.Sort
+	List(ctx context.Context, filter *domain.ListFilter) ([]domain.Subscription, error)
+	// суммы возвращаются в валюте подписок, по одной на каждую валюту
+	CalculateTotal(ctx context.Context, filter *domain.TotalFilter) ([]domain.Money, error)
+	CalculateGroupedTotal(ctx context.Context, filter *domain.TotalFilter) ([]domain.GroupedTotal, error)
+	CalculateMonthlyBreakdown(ctx context.Context, filter *domain.TotalFilter) ([]domain.MonthlyTotal, error)
 }
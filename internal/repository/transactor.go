@@ -0,0 +1,10 @@
+package repository
+
+import "context"
+
+// Transactor выполняет fn в одной транзакции. Репозитории, вызванные с контекстом
+// из fn, работают внутри нее; ошибка fn откатывает транзакцию. Сетевые запросы
+// в fn не выполняются: транзакция держит блокировки, а в памяти - все хранилище
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"testTask/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Методы, работающие с одним вебхуком, возвращают domain.ErrWebhookNotFound, если его нет
+type WebhookRepository interface {
+	Create(ctx context.Context, w *domain.Webhook) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error)
+	// ownerID == nil - вебхуки всех владельцев арендатора
+	List(ctx context.Context, ownerID *uuid.UUID) ([]domain.Webhook, error)
+	Update(ctx context.Context, w *domain.Webhook) error
+	// удаляет вебхук вместе с журналом доставок
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type WebhookDeliveryRepository interface {
+	// ставит событие в очередь доставки на все активные вебхуки, подписанные на его тип
+	// и видящие подписки владельца ownerID. Вызывается в транзакции изменения
+	Enqueue(ctx context.Context, event *domain.Event, ownerID uuid.UUID) error
+	// то же, что Enqueue, для событий одного арендатора: вебхуки выбираются один раз на пакет
+	EnqueueBatch(ctx context.Context, events []domain.WebhookEvent) error
+	List(ctx context.Context, webhookID uuid.UUID, filter *domain.DeliveryFilter) ([]domain.WebhookDelivery, error)
+	GetByID(ctx context.Context, webhookID uuid.UUID, id int64) (*domain.WebhookDelivery, error)
+	// возвращает до limit доставок, которые пора выполнить, всех арендаторов, и откладывает
+	// их следующую попытку на lease, чтобы их не взял другой процесс, пока идет доставка
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookJob, error)
+	RecordAttempt(ctx context.Context, id int64, attempt *domain.DeliveryAttempt) error
+	// возвращает доставку в очередь с обнуленным счетчиком попыток
+	Requeue(ctx context.Context, id int64) error
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testTask/internal/auth"
+	"testTask/internal/domain"
+	"testTask/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuthService аутентифицирует клиентов по JWT и API-ключам и управляет ключами
+type AuthService struct {
+	keys repository.APIKeyRepository
+	jwt  *auth.JWTVerifier
+	// в однопользовательском режиме все данные принадлежат арендатору по умолчанию
+	multiTenant bool
+}
+
+func NewAuthService(keys repository.APIKeyRepository, jwt *auth.JWTVerifier, multiTenant bool) *AuthService {
+	return &AuthService{keys: keys, jwt: jwt, multiTenant: multiTenant}
+}
+
+// AuthenticateToken проверяет JWT из заголовка Authorization: Bearer
+func (s *AuthService) AuthenticateToken(token string) (*domain.Principal, error) {
+	if !s.jwt.Enabled() {
+		return nil, domain.ErrUnauthorized
+	}
+
+	claims, err := s.jwt.Verify(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrUnauthorized, err)
+	}
+
+	p := &domain.Principal{
+		Subject: claims.Subject,
+		Method:  domain.AuthMethodJWT,
+		Admin:   claims.Role == "admin",
+	}
+
+	if claims.TenantID != "" {
+		if err := domain.ValidateTenantID(claims.TenantID); err != nil {
+			return nil, fmt.Errorf("%w: %w", domain.ErrUnauthorized, err)
+		}
+		p.TenantID = claims.TenantID
+		p.TenantFixed = true
+	}
+
+	// sub обычного пользователя - его user_id в подписках
+	if !p.Admin {
+		if userID, err := uuid.Parse(claims.Subject); err == nil {
+			p.UserID = &userID
+		}
+	}
+
+	return p, nil
+}
+
+// AuthenticateAPIKey проверяет ключ из заголовка X-API-Key
+func (s *AuthService) AuthenticateAPIKey(ctx context.Context, key string) (*domain.Principal, error) {
+	prefix, err := auth.ParseAPIKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrUnauthorized, err)
+	}
+
+	stored, err := s.keys.GetByPrefix(ctx, prefix)
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return nil, fmt.Errorf("%w: %w", domain.ErrUnauthorized, auth.ErrInvalidAPIKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !auth.MatchAPIKey(key, stored.Hash) || !stored.Active(time.Now()) {
+		return nil, fmt.Errorf("%w: %w", domain.ErrUnauthorized, auth.ErrInvalidAPIKey)
+	}
+
+	// ошибка учета времени использования не должна блокировать запрос
+	if err := s.keys.TouchLastUsed(ctx, stored.ID); err != nil {
+		slog.Warn("failed to update api key last_used_at", "key_id", stored.ID, "error", err)
+	}
+
+	p := &domain.Principal{
+		Subject: stored.ID.String(),
+		Method:  domain.AuthMethodAPIKey,
+		Admin:   stored.Admin,
+		Gateway: stored.Gateway,
+	}
+
+	if stored.TenantID != nil {
+		p.TenantID = *stored.TenantID
+		p.TenantFixed = true
+	}
+
+	return p, nil
+}
+
+// ResolveTenant выбирает арендатора запроса. Арендатор из учетных данных
+// заголовком не переопределяется. В многопользовательском режиме остальные клиенты
+// должны быть привязаны к арендатору; только администратор без привязки выбирает
+// арендатора заголовком X-Tenant-ID, по умолчанию - domain.DefaultTenant
+func (s *AuthService) ResolveTenant(p *domain.Principal, header string) error {
+	if !s.multiTenant {
+		p.TenantID = domain.DefaultTenant
+		p.TenantFixed = false
+		return nil
+	}
+
+	if p.TenantFixed {
+		if header != "" && header != p.TenantID {
+			return fmt.Errorf("%w: credentials are bound to another tenant", domain.ErrForbidden)
+		}
+		return nil
+	}
+
+	if !p.Admin {
+		return fmt.Errorf("%w: credentials are not bound to a tenant", domain.ErrForbidden)
+	}
+
+	if header == "" {
+		p.TenantID = domain.DefaultTenant
+		return nil
+	}
+
+	if err := domain.ValidateTenantID(header); err != nil {
+		return domain.NewValidationError("X-Tenant-ID", err.Error())
+	}
+	p.TenantID = header
+
+	return nil
+}
+
+// ActAsUser привязывает запрос к пользователю, переданному доверенным шлюзом.
+// Шлюз аутентифицируется своим API-ключом с признаком gateway; на время запроса
+// права администратора снимаются, чтобы пользователь видел только свои данные
+func (s *AuthService) ActAsUser(p *domain.Principal, userID string) error {
+	if p.Method != domain.AuthMethodAPIKey || !p.Gateway {
+		return fmt.Errorf("%w: user header is accepted only with a gateway api key", domain.ErrForbidden)
+	}
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid user id header", domain.ErrUnauthorized)
+	}
+
+	p.UserID = &id
+	p.Admin = false
+
+	return nil
+}
+
+// IssueAPIKey создает ключ и возвращает его значение. Получить его повторно нельзя
+func (s *AuthService) IssueAPIKey(ctx context.Context, key *domain.APIKey) (string, error) {
+	// администратор арендатора выпускает ключи только своего арендатора
+	if tenant := boundTenant(ctx); tenant != nil {
+		if key.TenantID != nil && *key.TenantID != *tenant {
+			return "", domain.NewValidationError("tenant_id", "cannot issue key for another tenant")
+		}
+		key.TenantID = tenant
+	}
+
+	if err := key.Validate(); err != nil {
+		return "", err
+	}
+
+	value, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return "", err
+	}
+
+	key.ID = uuid.New()
+	key.Prefix = prefix
+	key.Hash = hash
+
+	if err := s.keys.Create(ctx, key); err != nil {
+		return "", err
+	}
+
+	return value, nil
+}
+
+func (s *AuthService) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	return s.keys.List(ctx, boundTenant(ctx))
+}
+
+func (s *AuthService) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	return s.keys.Revoke(ctx, id, boundTenant(ctx))
+}
+
+// арендатор, к которому привязаны учетные данные клиента; nil - клиент
+// управляет ключами всех арендаторов
+func boundTenant(ctx context.Context) *string {
+	p := domain.PrincipalFromContext(ctx)
+	if p == nil || !p.TenantFixed {
+		return nil
+	}
+
+	tenant := p.TenantID
+	return &tenant
+}
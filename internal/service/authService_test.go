@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testTask/internal/auth"
+	"testTask/internal/domain"
+	"testTask/internal/repository/memory"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var testJWTSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// hs256Token JWT с claims, подписанный testJWTSecret
+func hs256Token(t *testing.T, claims map[string]any) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("marshal claims: %v", err)
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, testJWTSecret)
+	mac.Write([]byte(signed))
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuthenticateToken(t *testing.T) {
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{HMACSecret: testJWTSecret})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier: %v", err)
+	}
+	svc := NewAuthService(nil, verifier, true)
+
+	userID := uuid.New()
+	exp := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name   string
+		claims map[string]any
+		want   *domain.Principal // nil - токен отклоняется
+	}{
+		{
+			name:   "user",
+			claims: map[string]any{"sub": userID.String(), "exp": exp},
+			want:   &domain.Principal{Subject: userID.String(), Method: domain.AuthMethodJWT, UserID: &userID},
+		},
+		{
+			name:   "admin",
+			claims: map[string]any{"sub": "ops", "exp": exp, "role": "admin"},
+			want:   &domain.Principal{Subject: "ops", Method: domain.AuthMethodJWT, Admin: true},
+		},
+		{
+			name:   "tenant",
+			claims: map[string]any{"sub": userID.String(), "exp": exp, "tenant_id": "acme"},
+			want: &domain.Principal{
+				Subject: userID.String(), Method: domain.AuthMethodJWT, UserID: &userID,
+				TenantID: "acme", TenantFixed: true,
+			},
+		},
+		{name: "invalid tenant", claims: map[string]any{"sub": userID.String(), "exp": exp, "tenant_id": "Acme Corp"}},
+		{name: "expired", claims: map[string]any{"sub": userID.String(), "exp": time.Now().Add(-time.Hour).Unix()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := svc.AuthenticateToken(hs256Token(t, tt.claims))
+			if tt.want == nil {
+				if !errors.Is(err, domain.ErrUnauthorized) {
+					t.Errorf("error %v, want %v", err, domain.ErrUnauthorized)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("AuthenticateToken: %v", err)
+			}
+			checkPrincipal(t, p, tt.want)
+		})
+	}
+
+	disabled := NewAuthService(nil, mustVerifier(t, auth.JWTConfig{}), true)
+	if _, err := disabled.AuthenticateToken(hs256Token(t, tests[0].claims)); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("token accepted without configured keys: %v", err)
+	}
+}
+
+func mustVerifier(t *testing.T, cfg auth.JWTConfig) *auth.JWTVerifier {
+	t.Helper()
+
+	v, err := auth.NewJWTVerifier(cfg)
+	if err != nil {
+		t.Fatalf("NewJWTVerifier: %v", err)
+	}
+
+	return v
+}
+
+func checkPrincipal(t *testing.T, got, want *domain.Principal) {
+	t.Helper()
+
+	sameUser := (got.UserID == nil) == (want.UserID == nil) && (got.UserID == nil || *got.UserID == *want.UserID)
+	if got.Subject != want.Subject || got.Method != want.Method || got.Admin != want.Admin ||
+		got.Gateway != want.Gateway || got.TenantID != want.TenantID || got.TenantFixed != want.TenantFixed || !sameUser {
+		t.Errorf("principal %+v, want %+v", *got, *want)
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	// ключи всех арендаторов выпускает администратор без привязки
+	ctx := domain.WithPrincipal(context.Background(), &domain.Principal{Subject: "ops", Admin: true, TenantID: domain.DefaultTenant})
+	keys := memory.NewAPIKeyRepository(memory.NewStore())
+	svc := NewAuthService(keys, mustVerifier(t, auth.JWTConfig{}), true)
+
+	issue := func(key *domain.APIKey) string {
+		t.Helper()
+
+		value, err := svc.IssueAPIKey(ctx, key)
+		if err != nil {
+			t.Fatalf("IssueAPIKey: %v", err)
+		}
+		return value
+	}
+
+	tenant := "acme"
+	active := &domain.APIKey{Name: "gateway", Gateway: true, TenantID: &tenant}
+	activeValue := issue(active)
+
+	revoked := &domain.APIKey{Name: "revoked", Admin: true}
+	revokedValue := issue(revoked)
+	if err := svc.RevokeAPIKey(ctx, revoked.ID); err != nil {
+		t.Fatalf("RevokeAPIKey: %v", err)
+	}
+
+	// IssueAPIKey не выпускает уже истекший ключ, поэтому он сохраняется напрямую
+	expiredValue, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey: %v", err)
+	}
+	expiresAt := time.Now().Add(-time.Minute)
+	expired := &domain.APIKey{ID: uuid.New(), Name: "expired", Prefix: prefix, Hash: hash, ExpiresAt: &expiresAt}
+	if err := keys.Create(ctx, expired); err != nil {
+		t.Fatalf("create expired key: %v", err)
+	}
+
+	p, err := svc.AuthenticateAPIKey(context.Background(), activeValue)
+	if err != nil {
+		t.Fatalf("AuthenticateAPIKey: %v", err)
+	}
+	checkPrincipal(t, p, &domain.Principal{
+		Subject: active.ID.String(), Method: domain.AuthMethodAPIKey, Gateway: true,
+		TenantID: tenant, TenantFixed: true,
+	})
+
+	for name, key := range map[string]string{
+		"revoked":        revokedValue,
+		"expired":        expiredValue,
+		"wrong secret":   activeValue[:len(activeValue)-1] + "x",
+		"unknown prefix": "sk_000000000000_secret",
+		"malformed":      "secret",
+	} {
+		if _, err := svc.AuthenticateAPIKey(context.Background(), key); !errors.Is(err, domain.ErrUnauthorized) {
+			t.Errorf("%s key: error %v, want %v", name, err, domain.ErrUnauthorized)
+		}
+	}
+}
+
+func TestResolveTenant(t *testing.T) {
+	tests := []struct {
+		name        string
+		multiTenant bool
+		p           domain.Principal
+		header      string
+		want        string
+		wantErr     error
+	}{
+		{name: "single tenant", p: domain.Principal{TenantID: "acme", TenantFixed: true}, header: "other", want: domain.DefaultTenant},
+		{name: "bound", multiTenant: true, p: domain.Principal{TenantID: "acme", TenantFixed: true}, want: "acme"},
+		{name: "bound same header", multiTenant: true, p: domain.Principal{TenantID: "acme", TenantFixed: true}, header: "acme", want: "acme"},
+		{name: "bound other header", multiTenant: true, p: domain.Principal{TenantID: "acme", TenantFixed: true}, header: "other", wantErr: domain.ErrForbidden},
+		{name: "admin default", multiTenant: true, p: domain.Principal{Admin: true}, want: domain.DefaultTenant},
+		{name: "admin header", multiTenant: true, p: domain.Principal{Admin: true}, header: "other", want: "other"},
+		{name: "admin invalid header", multiTenant: true, p: domain.Principal{Admin: true}, header: "Other Tenant"},
+		{name: "unbound user", multiTenant: true, p: domain.Principal{}, wantErr: domain.ErrForbidden},
+		{name: "unbound user header", multiTenant: true, p: domain.Principal{}, header: "other", wantErr: domain.ErrForbidden},
+		{name: "unbound gateway", multiTenant: true, p: domain.Principal{Gateway: true}, header: "other", wantErr: domain.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewAuthService(nil, nil, tt.multiTenant)
+
+			p := tt.p
+			err := svc.ResolveTenant(&p, tt.header)
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error %v, want %v", err, tt.wantErr)
+				}
+			case tt.want == "":
+				if invalidField(err) != "X-Tenant-ID" {
+					t.Errorf("error %v, want validation error on X-Tenant-ID", err)
+				}
+			case err != nil:
+				t.Errorf("ResolveTenant: %v", err)
+			case p.TenantID != tt.want:
+				t.Errorf("tenant %q, want %q", p.TenantID, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testTask/internal/domain"
+	"testTask/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// CatalogService управляет каталогом сервисов, на которые оформляются подписки.
+// Каталог общий для всех арендаторов, поэтому изменять его может только
+// администратор без привязки к арендатору
+type CatalogService struct {
+	repo repository.ServiceRepository
+}
+
+func NewCatalogService(repo repository.ServiceRepository) *CatalogService {
+	return &CatalogService{repo: repo}
+}
+
+func (s *CatalogService) Create(ctx context.Context, svc *domain.Service) error {
+	if err := requireGlobalAdmin(ctx); err != nil {
+		return err
+	}
+
+	svc.ID = uuid.New()
+	normalize(svc)
+
+	if err := svc.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.checkNamesFree(ctx, svc); err != nil {
+		return err
+	}
+
+	return s.repo.Create(ctx, svc)
+}
+
+func (s *CatalogService) Get(ctx context.Context, id uuid.UUID) (*domain.Service, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *CatalogService) List(ctx context.Context, category *string) ([]domain.Service, error) {
+	return s.repo.List(ctx, category)
+}
+
+func (s *CatalogService) Update(ctx context.Context, svc *domain.Service) error {
+	if err := requireGlobalAdmin(ctx); err != nil {
+		return err
+	}
+
+	normalize(svc)
+
+	if err := svc.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.checkNamesFree(ctx, svc); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, svc)
+}
+
+func (s *CatalogService) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := requireGlobalAdmin(ctx); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// requireGlobalAdmin общие для всех арендаторов данные - каталог сервисов и курсы
+// валют - изменяет только администратор без привязки к арендатору
+func requireGlobalAdmin(ctx context.Context) error {
+	p := domain.PrincipalFromContext(ctx)
+	if p == nil || !p.Admin || p.TenantFixed {
+		return fmt.Errorf("%w: shared data can be changed only by an admin not bound to a tenant", domain.ErrForbidden)
+	}
+
+	return nil
+}
+
+func normalize(svc *domain.Service) {
+	svc.Name = domain.NormalizeServiceName(svc.Name)
+	for i, alias := range svc.Aliases {
+		svc.Aliases[i] = domain.NormalizeServiceName(alias)
+	}
+}
+
+// название и синонимы не должны совпадать с названием или синонимом другого сервиса.
+// Совпадения синонима с синонимом дополнительно ловит уникальный индекс в БД
+func (s *CatalogService) checkNamesFree(ctx context.Context, svc *domain.Service) error {
+	for _, name := range append([]string{svc.Name}, svc.Aliases...) {
+		other, err := s.repo.FindByName(ctx, name)
+		if errors.Is(err, domain.ErrServiceNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if other.ID != svc.ID {
+			return domain.ErrServiceNameTaken
+		}
+	}
+
+	return nil
+}
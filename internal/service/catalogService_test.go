@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testTask/internal/domain"
+	"testTask/internal/repository/memory"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSharedDataRequiresGlobalAdmin(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		p       *domain.Principal
+		allowed bool
+	}{
+		{"no principal", nil, false},
+		{"user", &domain.Principal{TenantID: domain.DefaultTenant, UserID: &userID}, false},
+		{"tenant admin", &domain.Principal{Admin: true, TenantID: "acme", TenantFixed: true}, false},
+		{"admin", &domain.Principal{Admin: true, TenantID: domain.DefaultTenant}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memory.NewStore()
+			catalog := NewCatalogService(memory.NewServiceRepository(store))
+			rates := NewExchangeRateService(memory.NewExchangeRateRepository(store))
+
+			ctx := context.Background()
+			if tt.p != nil {
+				ctx = domain.WithPrincipal(ctx, tt.p)
+			}
+
+			svc := &domain.Service{Name: "Netflix", Currency: domain.DefaultCurrency}
+			rate := &domain.ExchangeRate{BaseCurrency: "USD", QuoteCurrency: "RUB", Rate: 92.5}
+
+			calls := map[string]error{
+				"create service": catalog.Create(ctx, svc),
+				"update service": catalog.Update(ctx, svc),
+				"delete service": catalog.Delete(ctx, svc.ID),
+				"set rate":       rates.Set(ctx, rate),
+				"delete rate":    rates.Delete(ctx, "USD", "RUB"),
+			}
+
+			for call, err := range calls {
+				if forbidden := errors.Is(err, domain.ErrForbidden); forbidden == tt.allowed {
+					t.Errorf("%s: %v, allowed %v", call, err, tt.allowed)
+				}
+			}
+		})
+	}
+}
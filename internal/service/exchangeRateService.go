@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"testTask/internal/domain"
+	"testTask/internal/repository"
+)
+
+// ExchangeRateService курсы валют для пересчета стоимости подписок. Курсы общие
+// для всех арендаторов и изменяются только администратором без привязки к арендатору
+type ExchangeRateService struct {
+	repo repository.ExchangeRateRepository
+}
+
+func NewExchangeRateService(repo repository.ExchangeRateRepository) *ExchangeRateService {
+	return &ExchangeRateService{repo: repo}
+}
+
+func (s *ExchangeRateService) Set(ctx context.Context, rate *domain.ExchangeRate) error {
+	if err := requireGlobalAdmin(ctx); err != nil {
+		return err
+	}
+
+	if err := rate.Validate(); err != nil {
+		return err
+	}
+
+	return s.repo.Upsert(ctx, rate)
+}
+
+func (s *ExchangeRateService) Get(ctx context.Context, base, quote string) (*domain.ExchangeRate, error) {
+	return s.repo.Get(ctx, base, quote)
+}
+
+func (s *ExchangeRateService) List(ctx context.Context) ([]domain.ExchangeRate, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *ExchangeRateService) Delete(ctx context.Context, base, quote string) error {
+	if err := requireGlobalAdmin(ctx); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, base, quote)
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testTask/internal/domain"
+	"testTask/internal/publisher"
+	"testTask/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	outboxBatchSize = 100
+	// на это время выбранные события закрепляются за процессом публикации
+	outboxLease     = 2 * time.Minute
+	minRetryBackoff = time.Second
+	maxRetryBackoff = 10 * time.Minute
+)
+
+// OutboxRelay публикует события, сохраненные в outbox, и отмечает их отправленными.
+// Недоставленные события повторяются с экспоненциальной задержкой. Несколько
+// экземпляров приложения могут работать одновременно: выбранные события
+// закрепляются за процессом на время outboxLease. События одной подписки
+// публикуются в порядке добавления. Если публикация не удалась, остальные события
+// подписки из пачки ее не обгоняют, но между пачками порядок не гарантируется:
+// отложенное событие может выйти позже событий, добавленных после него
+type OutboxRelay struct {
+	outbox    repository.OutboxRepository
+	publisher publisher.Publisher
+	interval  time.Duration
+}
+
+func NewOutboxRelay(
+	outbox repository.OutboxRepository,
+	publisher publisher.Publisher,
+	interval time.Duration,
+) *OutboxRelay {
+	return &OutboxRelay{outbox: outbox, publisher: publisher, interval: interval}
+}
+
+// Run публикует события до отмены ctx
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		// полная пачка - вероятно, есть еще события, не ждем следующего тика
+		n, err := r.ProcessBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("outbox relay failed", "error", err)
+		}
+		if n == outboxBatchSize && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch публикует одну пачку готовых событий и возвращает число обработанных.
+// События публикуются вне транзакции, результат каждой публикации записывается отдельно
+func (r *OutboxRelay) ProcessBatch(ctx context.Context) (int, error) {
+	messages, err := r.outbox.Claim(ctx, outboxBatchSize, outboxLease)
+	if err != nil {
+		return 0, err
+	}
+
+	// после окончания аренды событие может взять другой процесс, поэтому пачка
+	// публикуется не дольше половины аренды; оставшиеся события вернутся в очередь
+	publishCtx, cancel := context.WithTimeout(ctx, outboxLease/2)
+	defer cancel()
+
+	// подписки, событие которых в этой пачке не опубликовано: их следующие события
+	// не должны обогнать его и возвращаются в очередь после окончания аренды
+	failed := make(map[uuid.UUID]bool)
+
+	var processed int
+	for i := range messages {
+		if publishCtx.Err() != nil {
+			break
+		}
+
+		m := &messages[i]
+		if failed[m.Event.SubscriptionID] {
+			continue
+		}
+
+		published, err := r.publish(ctx, publishCtx, m)
+		if err != nil {
+			return processed, err
+		}
+		if !published {
+			failed[m.Event.SubscriptionID] = true
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+// ошибка публикации не прерывает пачку: событие откладывается, остальные публикуются
+func (r *OutboxRelay) publish(ctx, publishCtx context.Context, m *domain.OutboxMessage) (bool, error) {
+	err := r.publisher.Publish(publishCtx, &m.Event)
+	if err == nil {
+		return true, r.outbox.MarkPublished(ctx, m.ID)
+	}
+
+	retryAfter := retryBackoff(m.Attempts)
+	slog.Warn("failed to publish event",
+		"event_id", m.Event.ID,
+		"type", m.Event.Type,
+		"attempt", m.Attempts+1,
+		"retry_after", retryAfter,
+		"error", err,
+	)
+
+	return false, r.outbox.MarkFailed(ctx, m.ID, err.Error(), retryAfter)
+}
+
+// задержка перед попыткой attempts+1: 1s, 2s, 4s, ... не больше maxRetryBackoff
+func retryBackoff(attempts int) time.Duration {
+	backoff := minRetryBackoff
+	for i := 0; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, maxRetryBackoff)
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testTask/internal/domain"
+	"testTask/internal/publisher"
+	"testTask/internal/repository"
+	"testTask/internal/repository/memory"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// failedAttempt вызов MarkFailed
+type failedAttempt struct {
+	id         int64
+	reason     string
+	retryAfter time.Duration
+}
+
+// recordingOutbox outbox в памяти, запоминающий результаты попыток публикации
+type recordingOutbox struct {
+	repository.OutboxRepository
+	failed    []failedAttempt
+	published []int64
+}
+
+func (r *recordingOutbox) MarkPublished(ctx context.Context, id int64) error {
+	r.published = append(r.published, id)
+	return r.OutboxRepository.MarkPublished(ctx, id)
+}
+
+func (r *recordingOutbox) MarkFailed(ctx context.Context, id int64, reason string, retryAfter time.Duration) error {
+	r.failed = append(r.failed, failedAttempt{id: id, reason: reason, retryAfter: retryAfter})
+	return r.OutboxRepository.MarkFailed(ctx, id, reason, retryAfter)
+}
+
+func newTestRelay(t *testing.T) (*OutboxRelay, *recordingOutbox, *publisher.MemoryPublisher) {
+	t.Helper()
+
+	outbox := &recordingOutbox{OutboxRepository: memory.NewOutboxRepository(memory.NewStore())}
+	pub := publisher.NewMemoryPublisher()
+
+	return NewOutboxRelay(outbox, pub, time.Second), outbox, pub
+}
+
+func addEvents(t *testing.T, outbox repository.OutboxRepository, n int) []uuid.UUID {
+	t.Helper()
+
+	ids := make([]uuid.UUID, 0, n)
+	for range n {
+		e := &domain.Event{
+			ID:             uuid.New(),
+			Type:           domain.EventSubscriptionUpdated,
+			TenantID:       domain.DefaultTenant,
+			SubscriptionID: uuid.New(),
+			Data:           json.RawMessage(`{}`),
+		}
+		if err := outbox.Add(context.Background(), e); err != nil {
+			t.Fatalf("add event: %v", err)
+		}
+		ids = append(ids, e.ID)
+	}
+
+	return ids
+}
+
+func publishedIDs(pub *publisher.MemoryPublisher) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, e := range pub.Events() {
+		ids = append(ids, e.ID)
+	}
+
+	return ids
+}
+
+func processBatch(t *testing.T, relay *OutboxRelay, want int) {
+	t.Helper()
+
+	n, err := relay.ProcessBatch(context.Background())
+	if err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+	if n != want {
+		t.Fatalf("ProcessBatch processed %d events, want %d", n, want)
+	}
+}
+
+func TestOutboxRelayPublishesAndMarksPublished(t *testing.T) {
+	relay, outbox, pub := newTestRelay(t)
+	ids := addEvents(t, outbox, 3)
+
+	processBatch(t, relay, 3)
+
+	if got := publishedIDs(pub); len(got) != len(ids) {
+		t.Fatalf("published %d events, want %d", len(got), len(ids))
+	}
+	if len(outbox.published) != 3 || len(outbox.failed) != 0 {
+		t.Errorf("marked published %v, failed %v; want 3 published", outbox.published, outbox.failed)
+	}
+
+	// опубликованные события больше не выбираются
+	processBatch(t, relay, 0)
+	if got := len(pub.Events()); got != 3 {
+		t.Errorf("events published again: %d, want 3", got)
+	}
+}
+
+func TestOutboxRelayFailureSetsBackoff(t *testing.T) {
+	relay, outbox, pub := newTestRelay(t)
+	ids := addEvents(t, outbox, 2)
+
+	pub.SetErr(errors.New("broker unavailable"))
+	processBatch(t, relay, 2)
+
+	if len(outbox.published) != 0 || len(outbox.failed) != 2 {
+		t.Fatalf("marked published %v, failed %v; want 2 failed", outbox.published, outbox.failed)
+	}
+	for _, f := range outbox.failed {
+		if f.reason != "broker unavailable" || f.retryAfter != minRetryBackoff {
+			t.Errorf("MarkFailed(%d, %q, %v), want reason %q and backoff %v",
+				f.id, f.reason, f.retryAfter, "broker unavailable", minRetryBackoff)
+		}
+	}
+
+	// до окончания задержки событие не публикуется, даже если брокер снова доступен
+	pub.SetErr(nil)
+	processBatch(t, relay, 0)
+
+	time.Sleep(minRetryBackoff + 50*time.Millisecond)
+	processBatch(t, relay, 2)
+
+	got := publishedIDs(pub)
+	if len(got) != 2 || got[0] != ids[0] || got[1] != ids[1] {
+		t.Errorf("published after backoff %v, want %v", got, ids)
+	}
+}
+
+func TestOutboxRelayPublishesInOrder(t *testing.T) {
+	relay, outbox, pub := newTestRelay(t)
+	ids := addEvents(t, outbox, outboxBatchSize+5)
+
+	// полная пачка, затем остаток
+	processBatch(t, relay, outboxBatchSize)
+	processBatch(t, relay, 5)
+
+	got := publishedIDs(pub)
+	if len(got) != len(ids) {
+		t.Fatalf("published %d events, want %d", len(got), len(ids))
+	}
+	for i := range ids {
+		if got[i] != ids[i] {
+			t.Fatalf("event %d published out of order: got %s, want %s", i, got[i], ids[i])
+		}
+	}
+}
+
+func TestOutboxRelaySkipsClaimedEvents(t *testing.T) {
+	relay, outbox, pub := newTestRelay(t)
+	ids := addEvents(t, outbox, 2)
+
+	// первое событие уже публикует другой экземпляр приложения
+	claimed, err := outbox.Claim(context.Background(), 1, time.Minute)
+	if err != nil || len(claimed) != 1 || claimed[0].Event.ID != ids[0] {
+		t.Fatalf("Claim = %v, %v; want first event", claimed, err)
+	}
+
+	processBatch(t, relay, 1)
+
+	if got := publishedIDs(pub); len(got) != 1 || got[0] != ids[1] {
+		t.Errorf("published %v, want only %s", got, ids[1])
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{9, 512 * time.Second},
+		{10, maxRetryBackoff},
+		{100, maxRetryBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := retryBackoff(tt.attempts); got != tt.want {
+			t.Errorf("retryBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// failingPublisher не публикует события из fail
+type failingPublisher struct {
+	publisher.Publisher
+	fail map[uuid.UUID]bool
+}
+
+func (p *failingPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	if p.fail[event.ID] {
+		return errors.New("broker rejected event")
+	}
+	return p.Publisher.Publish(ctx, event)
+}
+
+func TestOutboxRelayHoldsSubscriptionAfterFailure(t *testing.T) {
+	outbox := &recordingOutbox{OutboxRepository: memory.NewOutboxRepository(memory.NewStore())}
+	pub := publisher.NewMemoryPublisher()
+
+	// три события одной подписки вперемешку с событием другой
+	subID := uuid.New()
+	var ids []uuid.UUID
+	for _, id := range []uuid.UUID{subID, subID, uuid.New(), subID} {
+		e := &domain.Event{
+			ID:             uuid.New(),
+			Type:           domain.EventSubscriptionUpdated,
+			TenantID:       domain.DefaultTenant,
+			SubscriptionID: id,
+			Data:           json.RawMessage(`{}`),
+		}
+		if err := outbox.Add(context.Background(), e); err != nil {
+			t.Fatalf("add event: %v", err)
+		}
+		ids = append(ids, e.ID)
+	}
+
+	relay := NewOutboxRelay(outbox, &failingPublisher{Publisher: pub, fail: map[uuid.UUID]bool{ids[1]: true}}, time.Second)
+
+	// первое событие опубликовано, второе отложено, четвертое его не обгоняет
+	processBatch(t, relay, 3)
+
+	got := publishedIDs(pub)
+	if len(got) != 2 || got[0] != ids[0] || got[1] != ids[2] {
+		t.Errorf("published %v, want %v", got, []uuid.UUID{ids[0], ids[2]})
+	}
+	if len(outbox.failed) != 1 || outbox.failed[0].reason != "broker rejected event" {
+		t.Errorf("failed attempts %v, want one for the second event", outbox.failed)
+	}
+	if len(outbox.published) != 2 {
+		t.Errorf("marked published %v, want 2 events", outbox.published)
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testTask/internal/domain"
+	"testTask/internal/notifier"
+	"testTask/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	reminderBatchSize = 100
+	// на это время напоминание закрепляется за процессом, отправляющим уведомление
+	reminderLease = 5 * time.Minute
+)
+
+// ReminderScheduler за daysBefore дней до очередного списания по подписке
+// отправляет напоминание через notifier и событие subscription.renewal_upcoming
+// в outbox и вебхуки. Напоминание отмечается вместе с событием в одной транзакции,
+// поэтому событие о списании добавляется один раз. Уведомление отправляется после
+// ее фиксации и доставляется хотя бы один раз: если процесс остановится между
+// отправкой и MarkNotified, после аренды уведомление уйдет повторно
+type ReminderScheduler struct {
+	reminders  repository.ReminderRepository
+	prices     repository.PriceHistoryRepository
+	outbox     repository.OutboxRepository
+	webhooks   repository.WebhookDeliveryRepository
+	tx         repository.Transactor
+	notifier   notifier.Notifier
+	daysBefore int
+	interval   time.Duration
+}
+
+func NewReminderScheduler(
+	reminders repository.ReminderRepository,
+	prices repository.PriceHistoryRepository,
+	outbox repository.OutboxRepository,
+	webhooks repository.WebhookDeliveryRepository,
+	tx repository.Transactor,
+	notifier notifier.Notifier,
+	daysBefore int,
+	interval time.Duration,
+) *ReminderScheduler {
+	return &ReminderScheduler{
+		reminders:  reminders,
+		prices:     prices,
+		outbox:     outbox,
+		webhooks:   webhooks,
+		tx:         tx,
+		notifier:   notifier,
+		daysBefore: daysBefore,
+		interval:   interval,
+	}
+}
+
+// Run отправляет напоминания до отмены ctx
+func (s *ReminderScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.SendDue(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			slog.Error("renewal reminders failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDue отправляет напоминания о списаниях в ближайшие daysBefore дней от now
+// и возвращает число отправленных. Списание, о котором не удалось напомнить,
+// повторяется при следующем вызове, пока его дата не пройдет
+func (s *ReminderScheduler) SendDue(ctx context.Context, now time.Time) (int, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	horizon := today.AddDate(0, 0, s.daysBefore)
+
+	tenants, err := s.reminders.Tenants(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var sent int
+	for _, tenantID := range tenants {
+		n, err := s.sendTenant(domain.WithPrincipal(ctx, domain.SystemPrincipal(tenantID)), tenantID, today, horizon)
+		sent += n
+		if err != nil {
+			return sent, fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+	}
+
+	return sent, nil
+}
+
+func (s *ReminderScheduler) sendTenant(ctx context.Context, tenantID string, today, horizon time.Time) (int, error) {
+	var sent int
+	after := uuid.Nil
+
+	for {
+		subs, err := s.reminders.ListCandidates(ctx, today, horizon, after, reminderBatchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		for i := range subs {
+			sub := &subs[i]
+			after = sub.ID
+
+			charge, ok := sub.NextChargeDate(today, horizon)
+			if !ok {
+				continue
+			}
+
+			// ошибка по одной подписке не останавливает остальные
+			notified, err := s.send(ctx, tenantID, sub, charge)
+			if err != nil {
+				if ctx.Err() != nil {
+					return sent, ctx.Err()
+				}
+				slog.Warn("failed to send renewal reminder",
+					"tenant_id", tenantID,
+					"subscription_id", sub.ID,
+					"charge_date", charge.Format("2006-01-02"),
+					"error", err,
+				)
+				continue
+			}
+			if notified {
+				sent++
+			}
+		}
+
+		if len(subs) < reminderBatchSize {
+			return sent, nil
+		}
+	}
+}
+
+// событие публикуется один раз - вместе с первой отметкой напоминания. Уведомление
+// отправляется вне транзакции; при ошибке закрепление снимается и отправка
+// повторяется. Сбой отметки после отправки приводит к повтору, поэтому
+// уведомление доставляется «хотя бы один раз»
+func (s *ReminderScheduler) send(ctx context.Context, tenantID string, sub *domain.Subscription, charge time.Time) (bool, error) {
+	changes, err := s.prices.List(ctx, sub.ID)
+	if err != nil {
+		return false, err
+	}
+
+	reminder := domain.NewReminder(tenantID, sub, charge, changes)
+
+	var claimed bool
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		ok, created, err := s.reminders.Claim(ctx, reminder, reminderLease)
+		if err != nil {
+			return err
+		}
+
+		claimed = ok
+		if !created {
+			return nil
+		}
+
+		event, err := domain.NewRenewalEvent(reminder)
+		if err != nil {
+			return err
+		}
+
+		if err := s.outbox.Add(ctx, event); err != nil {
+			return err
+		}
+
+		return s.webhooks.Enqueue(ctx, event, sub.UserID)
+	})
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	if err := s.notifier.Notify(ctx, reminder); err != nil {
+		if releaseErr := s.reminders.Release(ctx, reminder); releaseErr != nil {
+			slog.Warn("failed to release renewal reminder", "subscription_id", sub.ID, "error", releaseErr)
+		}
+		return false, fmt.Errorf("notify: %w", err)
+	}
+
+	return true, s.reminders.MarkNotified(ctx, reminder)
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testTask/internal/domain"
+	"testTask/internal/repository"
+	"testTask/internal/repository/memory"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// recordingNotifier запоминает отправленные напоминания; notify вызывается перед отправкой
+type recordingNotifier struct {
+	err      error
+	notify   func()
+	reminded []domain.Reminder
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, r *domain.Reminder) error {
+	if n.notify != nil {
+		n.notify()
+	}
+	if n.err != nil {
+		return n.err
+	}
+
+	n.reminded = append(n.reminded, *r)
+	return nil
+}
+
+type reminderTest struct {
+	scheduler *ReminderScheduler
+	notifier  *recordingNotifier
+	reminders repository.ReminderRepository
+	outbox    repository.OutboxRepository
+	sub       *domain.Subscription
+}
+
+// ежемесячная подписка с 15 января 2025, напоминание за 3 дня до списания 1 марта
+func newReminderTest(t *testing.T) *reminderTest {
+	t.Helper()
+
+	store := memory.NewStore()
+	ctx := domain.WithPrincipal(context.Background(), domain.SystemPrincipal(domain.DefaultTenant))
+
+	service := &domain.Service{ID: uuid.New(), Name: "Netflix", Currency: domain.DefaultCurrency}
+	if err := memory.NewServiceRepository(store).Create(ctx, service); err != nil {
+		t.Fatalf("create service: %v", err)
+	}
+
+	sub := &domain.Subscription{
+		ID:            uuid.New(),
+		ServiceID:     service.ID,
+		Price:         400,
+		Currency:      domain.DefaultCurrency,
+		BillingPeriod: domain.BillingPeriodMonth,
+		UserID:        uuid.New(),
+		StartDate:     time.Date(2025, time.January, 15, 0, 0, 0, 0, time.UTC),
+		Status:        domain.StatusActive,
+	}
+	if err := memory.NewSubscriptionRepository(store).Create(ctx, sub); err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+
+	rt := &reminderTest{
+		notifier:  &recordingNotifier{},
+		reminders: memory.NewReminderRepository(store),
+		outbox:    memory.NewOutboxRepository(store),
+		sub:       sub,
+	}
+	rt.scheduler = NewReminderScheduler(
+		rt.reminders,
+		memory.NewPriceHistoryRepository(store),
+		rt.outbox,
+		memory.NewWebhookDeliveryRepository(store),
+		memory.NewTransactor(store),
+		rt.notifier,
+		3,
+		time.Hour,
+	)
+
+	return rt
+}
+
+func (rt *reminderTest) sendDue(t *testing.T, want int) {
+	t.Helper()
+
+	sent, err := rt.scheduler.SendDue(context.Background(), time.Date(2025, time.February, 27, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("SendDue: %v", err)
+	}
+	if sent != want {
+		t.Fatalf("SendDue sent %d reminders, want %d", sent, want)
+	}
+}
+
+func (rt *reminderTest) events(t *testing.T) []domain.OutboxMessage {
+	t.Helper()
+
+	messages, err := rt.outbox.Claim(context.Background(), outboxBatchSize, 0)
+	if err != nil {
+		t.Fatalf("claim outbox: %v", err)
+	}
+
+	return messages
+}
+
+func TestReminderSchedulerSendsOnce(t *testing.T) {
+	rt := newReminderTest(t)
+
+	rt.sendDue(t, 1)
+	rt.sendDue(t, 0)
+
+	if len(rt.notifier.reminded) != 1 {
+		t.Fatalf("notified %d times, want 1", len(rt.notifier.reminded))
+	}
+	r := rt.notifier.reminded[0]
+	if r.SubscriptionID != rt.sub.ID || !r.ChargeDate.Equal(time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("reminder %+v, want subscription %s charged on 2025-03-01", r, rt.sub.ID)
+	}
+
+	events := rt.events(t)
+	if len(events) != 1 || events[0].Event.Type != domain.EventSubscriptionRenewalUpcoming {
+		t.Errorf("outbox events %+v, want one renewal_upcoming", events)
+	}
+}
+
+func TestReminderSchedulerRetriesFailedNotification(t *testing.T) {
+	rt := newReminderTest(t)
+
+	rt.notifier.err = errors.New("smtp unavailable")
+	rt.sendDue(t, 0)
+
+	rt.notifier.err = nil
+	rt.sendDue(t, 1)
+	rt.sendDue(t, 0)
+
+	if len(rt.notifier.reminded) != 1 {
+		t.Errorf("notified %d times, want 1", len(rt.notifier.reminded))
+	}
+	// событие публикуется при первой отметке и не повторяется вместе с уведомлением
+	if events := rt.events(t); len(events) != 1 {
+		t.Errorf("outbox has %d events, want 1", len(events))
+	}
+}
+
+func TestReminderSchedulerNotifiesOutsideTransaction(t *testing.T) {
+	rt := newReminderTest(t)
+
+	// в памяти транзакция держит все хранилище: запрос к нему из уведомления
+	// завис бы до ее завершения
+	rt.notifier.notify = func() {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = rt.reminders.Tenants(context.Background())
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Error("notification is sent inside a transaction")
+		}
+	}
+
+	rt.sendDue(t, 1)
+}
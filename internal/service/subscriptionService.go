@@ -2,53 +2,885 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"testTask/internal/domain"
 	"testTask/internal/repository"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type SubscriptionService struct {
-	repo repository.SubscriptionRepository
+	repo     repository.SubscriptionRepository
+	services repository.ServiceRepository
+	rates    repository.ExchangeRateRepository
+	prices   repository.PriceHistoryRepository
+	audit    repository.AuditRepository
+	outbox   repository.OutboxRepository
+	webhooks repository.WebhookDeliveryRepository
+	tx       repository.Transactor
+	// сколько удаленные подписки хранятся до окончательного удаления
+	retention time.Duration
 }
 
-func NewSubscriptionService(repo repository.SubscriptionRepository) *SubscriptionService {
-	return &SubscriptionService{repo: repo}
+func NewSubscriptionService(
+	repo repository.SubscriptionRepository,
+	services repository.ServiceRepository,
+	rates repository.ExchangeRateRepository,
+	prices repository.PriceHistoryRepository,
+	audit repository.AuditRepository,
+	outbox repository.OutboxRepository,
+	webhooks repository.WebhookDeliveryRepository,
+	tx repository.Transactor,
+	retention time.Duration,
+) *SubscriptionService {
+	return &SubscriptionService{
+		repo:      repo,
+		services:  services,
+		rates:     rates,
+		prices:    prices,
+		audit:     audit,
+		outbox:    outbox,
+		webhooks:  webhooks,
+		tx:        tx,
+		retention: retention,
+	}
+}
+
+// record пишет изменение подписки в журнал аудита, сохраняет событие о нем в outbox
+// и ставит его в очередь доставки на вебхуки; вызывается в транзакции изменения
+func (s *SubscriptionService) record(ctx context.Context, action domain.AuditAction, sub *domain.Subscription, before, after any) error {
+	entry, err := domain.NewAuditEntry(ctx, action, sub, before, after)
+	if err != nil {
+		return err
+	}
+
+	if err := s.audit.Create(ctx, entry); err != nil {
+		return err
+	}
+
+	event, err := domain.NewSubscriptionEvent(ctx, action.EventType(), sub)
+	if err != nil {
+		return err
+	}
+
+	if err := s.outbox.Add(ctx, event); err != nil {
+		return err
+	}
+
+	return s.webhooks.Enqueue(ctx, event, sub.UserID)
+}
+
+// recordCreated то же, что record, для подписок, созданных импортом: записи аудита,
+// события и доставки на вебхуки сохраняются пакетами, а вебхуки выбираются один раз
+func (s *SubscriptionService) recordCreated(ctx context.Context, subs []domain.Subscription) error {
+	entries := make([]domain.AuditEntry, 0, len(subs))
+	events := make([]domain.Event, 0, len(subs))
+	for i := range subs {
+		entry, err := domain.NewAuditEntry(ctx, domain.AuditCreate, &subs[i], nil, &subs[i])
+		if err != nil {
+			return err
+		}
+		entries = append(entries, *entry)
+
+		event, err := domain.NewSubscriptionEvent(ctx, domain.AuditCreate.EventType(), &subs[i])
+		if err != nil {
+			return err
+		}
+		events = append(events, *event)
+	}
+
+	if err := s.audit.CreateBatch(ctx, entries); err != nil {
+		return err
+	}
+
+	if err := s.outbox.AddBatch(ctx, events); err != nil {
+		return err
+	}
+
+	owned := make([]domain.WebhookEvent, 0, len(events))
+	for i := range events {
+		owned = append(owned, domain.WebhookEvent{Event: &events[i], OwnerID: subs[i].UserID})
+	}
+
+	return s.webhooks.EnqueueBatch(ctx, owned)
 }
 
+// Create создает подписку на сервис из каталога, заданный ServiceID или ServiceName.
+// Если цена не указана, берется цена сервиса по умолчанию вместе с его валютой
 func (s *SubscriptionService) Create(ctx context.Context, sub *domain.Subscription) error {
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.prepare(ctx, sub, owner, s.resolveService); err != nil {
+		return err
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, sub); err != nil {
+			return err
+		}
+
+		return s.record(ctx, domain.AuditCreate, sub, nil, sub)
+	})
+}
+
+// prepare готовит новую подписку к сохранению: проверяет владельца, находит сервис
+// через resolve и подставляет его цену и валюту по умолчанию
+func (s *SubscriptionService) prepare(
+	ctx context.Context,
+	sub *domain.Subscription,
+	owner *uuid.UUID,
+	resolve func(ctx context.Context, sub *domain.Subscription) (*domain.Service, error),
+) error {
+	// пользователь создает подписки только себе; user_id подставляется автоматически
+	if owner != nil {
+		if sub.UserID != uuid.Nil && sub.UserID != *owner {
+			return domain.NewValidationError("user_id", "cannot create subscription for another user")
+		}
+		sub.UserID = *owner
+	}
+
 	sub.ID = uuid.New()
 
-	if err := sub.Validate(); err != nil {
+	svc, err := resolve(ctx, sub)
+	if err != nil {
 		return err
 	}
 
-	return s.repo.Create(ctx, sub)
+	// цена по умолчанию задана в валюте сервиса, в другой валюте ее не подставить
+	if sub.Price == 0 && svc.DefaultPrice != nil {
+		if sub.Currency != "" && sub.Currency != svc.Currency {
+			return domain.NewValidationError("price",
+				fmt.Sprintf("default price of the service is in %s, set price for %s", svc.Currency, sub.Currency))
+		}
+		sub.Price = *svc.DefaultPrice
+		sub.Currency = svc.Currency
+	}
+
+	if sub.Currency == "" {
+		sub.Currency = svc.Currency
+	}
+
+	sub.Status = sub.StatusAt(time.Now())
+
+	return sub.Validate()
 }
 
-func (s *SubscriptionService) List(ctx context.Context, userId *uuid.UUID, serviceName *string) ([]domain.Subscription, error) {
-	return s.repo.List(ctx, userId, serviceName)
+// importBatchSize подписок в одном пакете записи при импорте
+const importBatchSize = 1000
+
+// Import создает подписки из строк файла по тем же правилам, что и Create.
+// Строки с ошибками попадают в отчет, остальные сохраняются в одной транзакции
+// пакетами по importBatchSize. При dryRun подписки только проверяются
+func (s *SubscriptionService) Import(ctx context.Context, rows []domain.ImportRow, dryRun bool) (*domain.ImportResult, error) {
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) > domain.MaxImportRows {
+		return nil, domain.NewValidationError("file", fmt.Sprintf("at most %d rows can be imported at once", domain.MaxImportRows))
+	}
+
+	result := &domain.ImportResult{Rows: len(rows)}
+	resolve := s.cachedResolver()
+
+	subs := make([]domain.Subscription, 0, len(rows))
+	for _, row := range rows {
+		sub := row.Subscription
+
+		err := s.prepare(ctx, &sub, owner, resolve)
+		var verr *domain.ValidationError
+		if errors.As(err, &verr) {
+			result.Errors = append(result.Errors, domain.ImportRowError{Row: row.Row, Fields: verr.Fields})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		subs = append(subs, sub)
+	}
+
+	result.Valid = len(subs)
+	if dryRun || len(subs) == 0 {
+		return result, nil
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		for batch := range slices.Chunk(subs, importBatchSize) {
+			if err := s.repo.CreateBatch(ctx, batch); err != nil {
+				return err
+			}
+		}
+
+		return s.recordCreated(ctx, subs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Imported = len(subs)
+
+	return result, nil
 }
 
+// cachedResolver resolveService, запоминающий результат для каждого названия
+// и ID сервиса: в импорте одни и те же сервисы повторяются во многих строках
+func (s *SubscriptionService) cachedResolver() func(ctx context.Context, sub *domain.Subscription) (*domain.Service, error) {
+	type resolved struct {
+		svc *domain.Service
+		err error
+	}
+	cache := make(map[string]resolved)
+
+	return func(ctx context.Context, sub *domain.Subscription) (*domain.Service, error) {
+		key := "id:" + sub.ServiceID.String()
+		if sub.ServiceID == uuid.Nil {
+			key = "name:" + strings.ToLower(domain.NormalizeServiceName(sub.ServiceName))
+		}
+
+		r, ok := cache[key]
+		if !ok {
+			r.svc, r.err = s.resolveService(ctx, sub)
+			cache[key] = r
+			return r.svc, r.err
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+
+		sub.ServiceID = r.svc.ID
+		sub.ServiceName = r.svc.Name
+
+		return r.svc, nil
+	}
+}
+
+// List возвращает страницу подписок. Запрашивается на одну запись больше лимита,
+// чтобы понять, есть ли следующая страница
+func (s *SubscriptionService) List(ctx context.Context, filter *domain.ListFilter) (*domain.SubscriptionPage, error) {
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.IncludeDeleted && owner != nil {
+		return nil, fmt.Errorf("%w: include_deleted is available only to admins", domain.ErrForbidden)
+	}
+
+	query := *filter
+	query.OwnerID = owner
+	query.Limit++
+
+	subs, err := s.repo.List(ctx, &query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.SubscriptionPage{Items: subs}
+	if len(subs) > filter.Limit {
+		page.Items = subs[:filter.Limit]
+		cursor := filter.CursorAfter(&page.Items[filter.Limit-1])
+		page.NextCursor = &cursor
+	}
+
+	return page, nil
+}
+
+// Get возвращает подписку, если она доступна вызывающему клиенту. Чужая подписка
+// неотличима от несуществующей
 func (s *SubscriptionService) Get(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
-	return s.repo.GetByID(ctx, id)
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if owner != nil && sub.UserID != *owner {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+
+	return sub, nil
 }
 
+// Update сохраняет изменения подписки; владелец подписки не меняется
 func (s *SubscriptionService) Update(ctx context.Context, sub *domain.Subscription) error {
-	if err := sub.Validate(); err != nil {
-		return err
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.Get(ctx, sub.ID)
+		if err != nil {
+			return err
+		}
+		sub.UserID = current.UserID
+		sub.CreatedAt = current.CreatedAt
+		// состояние меняется только переходами Pause, Resume и Cancel
+		sub.Status = current.Status
+		sub.CancelAt = current.CancelAt
+		sub.Pauses = current.Pauses
+
+		if _, err := s.resolveService(ctx, sub); err != nil {
+			return err
+		}
+
+		if err := sub.Validate(); err != nil {
+			return err
+		}
+
+		changes, err := s.prices.List(ctx, sub.ID)
+		if err != nil {
+			return err
+		}
+
+		change, err := priceChangeOnUpdate(sub, current, changes, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if err := s.repo.Update(ctx, sub); err != nil {
+			return err
+		}
+		// пробный период мог измениться
+		sub.Status = sub.StatusAt(time.Now())
+
+		if change == nil {
+			return s.record(ctx, domain.AuditUpdate, sub, current, sub)
+		}
+
+		if err := s.prices.Upsert(ctx, change); err != nil {
+			return err
+		}
+
+		// один запрос - одна запись аудита и одно событие, изменение цены входит в них
+		return s.record(ctx, domain.AuditUpdate, sub, current, updatedState{Subscription: sub, PriceChange: change})
+	})
+}
+
+// updatedState состояние подписки после Update для журнала аудита: цена в подписке
+// остается начальной, а новая цена записывается в историю изменением PriceChange
+type updatedState struct {
+	*domain.Subscription
+	PriceChange *domain.PriceChange
+}
+
+// priceChangeOnUpdate новая цена подписки, которая уже списывалась, действует
+// с текущего месяца: начальная цена остается, чтобы не менять итоги прошлых месяцев.
+// Валюту такой подписки изменить нельзя. Возвращает изменение для истории цен
+// или nil, если цена текущего месяца не меняется или начальную цену можно заменить
+func priceChangeOnUpdate(sub, current *domain.Subscription, changes []domain.PriceChange, now time.Time) (*domain.PriceChange, error) {
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if !sub.StartDate.Before(month) {
+		return nil, nil
+	}
+
+	if sub.Currency != current.Currency {
+		return nil, domain.NewValidationError("currency",
+			"currency cannot be changed after the first charge, create a new subscription instead")
+	}
+
+	price := sub.Price
+	sub.Price = current.Price
+	if price == current.PriceAt(changes, month) {
+		return nil, nil
+	}
+
+	if sub.EndDate != nil && sub.EndDate.Before(month) {
+		return nil, domain.NewValidationError("price",
+			"subscription has ended, change past prices with /subscriptions/{id}/prices")
+	}
+
+	change := &domain.PriceChange{
+		SubscriptionID: sub.ID,
+		Price:          price,
+		EffectiveFrom:  month,
+	}
+
+	if err := change.Validate(sub); err != nil {
+		return nil, err
 	}
 
-	return s.repo.Update(ctx, sub)
+	return change, nil
+}
+
+// Pause приостанавливает подписку с текущего дня: до Resume списания не выставляются
+func (s *SubscriptionService) Pause(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	return s.transition(ctx, id, domain.AuditPause, (*domain.Subscription).Pause)
+}
+
+// Resume возобновляет приостановленную подписку с текущего дня
+func (s *SubscriptionService) Resume(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	return s.transition(ctx, id, domain.AuditResume, (*domain.Subscription).Resume)
+}
+
+// Cancel отменяет подписку в конце оплаченного периода
+func (s *SubscriptionService) Cancel(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	return s.transition(ctx, id, domain.AuditCancel, (*domain.Subscription).Cancel)
+}
+
+// transition применяет переход состояния apply к подписке и сохраняет результат.
+// Недопустимый переход - domain.ErrInvalidStatusTransition
+func (s *SubscriptionService) transition(
+	ctx context.Context,
+	id uuid.UUID,
+	action domain.AuditAction,
+	apply func(sub *domain.Subscription, now time.Time) error,
+) (*domain.Subscription, error) {
+	var sub *domain.Subscription
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		updated := *current
+		updated.Pauses = slices.Clone(current.Pauses)
+
+		if err := apply(&updated, time.Now()); err != nil {
+			return err
+		}
+
+		if err := s.repo.UpdateStatus(ctx, &updated); err != nil {
+			return err
+		}
+
+		sub = &updated
+		return s.record(ctx, action, sub, current, sub)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sub, nil
 }
 
 func (s *SubscriptionService) Delete(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		return s.record(ctx, domain.AuditDelete, current, current, nil)
+	})
+}
+
+// Restore возвращает подписку, удаленную в пределах срока хранения. Для подписки,
+// которая не удалена, - domain.ErrSubscriptionNotDeleted
+func (s *SubscriptionService) Restore(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := s.repo.GetDeleted(ctx, id)
+	if errors.Is(err, domain.ErrSubscriptionNotFound) {
+		if _, getErr := s.Get(ctx, id); getErr == nil {
+			return nil, domain.ErrSubscriptionNotDeleted
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if owner != nil && sub.UserID != *owner {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+
+	// после срока хранения подписка ждет Purge и считается удаленной окончательно
+	if sub.DeletedAt != nil && sub.DeletedAt.Before(time.Now().Add(-s.retention)) {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+
+	before := *sub
+	sub.DeletedAt = nil
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Restore(ctx, id); err != nil {
+			return err
+		}
+
+		return s.record(ctx, domain.AuditRestore, sub, &before, sub)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// Purge окончательно удаляет подписки, удаленные раньше срока хранения,
+// и записывает удаление каждой в журнал аудита. Доступно только администратору
+func (s *SubscriptionService) Purge(ctx context.Context) (int64, error) {
+	p := domain.PrincipalFromContext(ctx)
+	if p == nil {
+		return 0, domain.ErrUnauthorized
+	}
+	if !p.Admin {
+		return 0, fmt.Errorf("%w: purge is available only to admins", domain.ErrForbidden)
+	}
+
+	var purged []domain.Subscription
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if purged, err = s.repo.Purge(ctx, time.Now().Add(-s.retention)); err != nil {
+			return err
+		}
+
+		for i := range purged {
+			if err := s.record(ctx, domain.AuditPurge, &purged[i], &purged[i], nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(purged)), nil
+}
+
+// ownerFromContext возвращает пользователя, подписками которого ограничен клиент,
+// или nil для администратора. Клиент без пользователя и без прав администратора
+// к подпискам доступа не имеет
+func ownerFromContext(ctx context.Context) (*uuid.UUID, error) {
+	p := domain.PrincipalFromContext(ctx)
+
+	switch {
+	case p == nil:
+		return nil, domain.ErrUnauthorized
+	case p.UserID != nil:
+		return p.UserID, nil
+	case p.Admin:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%w: no user identity", domain.ErrForbidden)
+	}
+}
+
+// находит сервис подписки в каталоге: по ServiceID, если он задан, иначе по названию
+// или синониму. Подставляет в подписку ID и каноническое название сервиса
+func (s *SubscriptionService) resolveService(ctx context.Context, sub *domain.Subscription) (*domain.Service, error) {
+	var (
+		svc   *domain.Service
+		err   error
+		field = "service_id"
+	)
+
+	if sub.ServiceID != uuid.Nil {
+		svc, err = s.services.GetByID(ctx, sub.ServiceID)
+	} else {
+		field = "service_name"
+		svc, err = s.services.FindByName(ctx, domain.NormalizeServiceName(sub.ServiceName))
+	}
+	if errors.Is(err, domain.ErrServiceNotFound) {
+		// сервис подписки - поле запроса, а не запрошенный ресурс
+		return nil, domain.NewValidationError(field, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sub.ServiceID = svc.ID
+	sub.ServiceName = svc.Name
+
+	return svc, nil
+}
+
+// SchedulePriceChange планирует новую цену подписки id начиная с месяца change.EffectiveFrom
+func (s *SubscriptionService) SchedulePriceChange(ctx context.Context, id uuid.UUID, change *domain.PriceChange) error {
+	sub, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	change.SubscriptionID = sub.ID
+
+	if err := change.Validate(sub); err != nil {
+		return err
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.prices.Upsert(ctx, change); err != nil {
+			return err
+		}
+
+		return s.record(ctx, domain.AuditPriceChangeSchedule, sub, nil, change)
+	})
+}
+
+// ListPriceChanges возвращает историю цен; для недоступной подписки -
+// domain.ErrSubscriptionNotFound, а не пустую историю
+func (s *SubscriptionService) ListPriceChanges(ctx context.Context, id uuid.UUID) ([]domain.PriceChange, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.prices.List(ctx, id)
+}
+
+func (s *SubscriptionService) CancelPriceChange(ctx context.Context, id uuid.UUID, effectiveFrom time.Time) error {
+	sub, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// отменяемая цена нужна только для журнала аудита
+	changes, err := s.prices.List(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var cancelled *domain.PriceChange
+	for i := range changes {
+		if changes[i].EffectiveFrom.Equal(effectiveFrom) {
+			cancelled = &changes[i]
+			break
+		}
+	}
+	if cancelled == nil {
+		return domain.ErrPriceChangeNotFound
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.prices.Delete(ctx, id, effectiveFrom); err != nil {
+			return err
+		}
+
+		return s.record(ctx, domain.AuditPriceChangeCancel, sub, cancelled, nil)
+	})
+}
+
+// History возвращает журнал изменений подписки, в том числе удаленной
+func (s *SubscriptionService) History(ctx context.Context, id uuid.UUID, filter *domain.AuditFilter) (*domain.AuditPage, error) {
+	if _, err := s.Get(ctx, id); errors.Is(err, domain.ErrSubscriptionNotFound) {
+		owner, ownerErr := ownerFromContext(ctx)
+		if ownerErr != nil {
+			return nil, ownerErr
+		}
+
+		deleted, err := s.repo.GetDeleted(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if owner != nil && deleted.UserID != *owner {
+			return nil, domain.ErrSubscriptionNotFound
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	query := *filter
+	query.SubscriptionID = &id
+
+	return s.listAudit(ctx, &query)
+}
+
+// Audit возвращает журнал изменений всех подписок арендатора; доступно только администратору
+func (s *SubscriptionService) Audit(ctx context.Context, filter *domain.AuditFilter) (*domain.AuditPage, error) {
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if owner != nil {
+		return nil, fmt.Errorf("%w: audit log is available only to admins", domain.ErrForbidden)
+	}
+
+	return s.listAudit(ctx, filter)
+}
+
+// запрашивается на одну запись больше лимита, чтобы понять, есть ли следующая страница
+func (s *SubscriptionService) listAudit(ctx context.Context, filter *domain.AuditFilter) (*domain.AuditPage, error) {
+	query := *filter
+	query.Limit++
+
+	entries, err := s.audit.List(ctx, &query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.AuditPage{Items: entries}
+	if len(entries) > filter.Limit {
+		page.Items = entries[:filter.Limit]
+		next := page.Items[filter.Limit-1].ID
+		page.NextBeforeID = &next
+	}
+
+	return page, nil
 }
 
 func (s *SubscriptionService) CalculateTotal(
 	ctx context.Context,
 	filter *domain.TotalFilter,
 ) (int, error) {
-	return s.repo.CalculateTotal(ctx, filter)
+	filter, err := scopeTotalFilter(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	amounts, err := s.repo.CalculateTotal(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	rates, err := s.exchangeRates(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	for _, m := range amounts {
+		amount, err := convert(rates, m, filter.Currency)
+		if err != nil {
+			return 0, err
+		}
+		total += amount
+	}
+
+	return total, nil
+}
+
+func (s *SubscriptionService) CalculateGroupedTotal(
+	ctx context.Context,
+	filter *domain.TotalFilter,
+) ([]domain.GroupedTotal, error) {
+	filter, err := scopeTotalFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.repo.CalculateGroupedTotal(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := s.exchangeRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// строки одной группы в разных валютах идут подряд, схлопываем их в одну
+	var result []domain.GroupedTotal
+	for _, g := range groups {
+		amount, err := convert(rates, domain.Money{Amount: g.Total, Currency: g.Currency}, filter.Currency)
+		if err != nil {
+			return nil, err
+		}
+
+		if n := len(result); n > 0 && sameGroup(&result[n-1], &g) {
+			result[n-1].Total += amount
+			continue
+		}
+
+		g.Total = amount
+		g.Currency = filter.Currency
+		result = append(result, g)
+	}
+
+	return result, nil
+}
+
+func (s *SubscriptionService) CalculateMonthlyBreakdown(
+	ctx context.Context,
+	filter *domain.TotalFilter,
+) ([]domain.MonthlyTotal, error) {
+	filter, err := scopeTotalFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	months, err := s.repo.CalculateMonthlyBreakdown(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := s.exchangeRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// строки одного месяца в разных валютах идут подряд, схлопываем их в одну
+	var result []domain.MonthlyTotal
+	for _, mt := range months {
+		amount, err := convert(rates, domain.Money{Amount: mt.Amount, Currency: mt.Currency}, filter.Currency)
+		if err != nil {
+			return nil, err
+		}
+
+		if n := len(result); n > 0 && result[n-1].Month.Equal(mt.Month) {
+			result[n-1].Amount += amount
+			result[n-1].Subscriptions += mt.Subscriptions
+			continue
+		}
+
+		mt.Amount = amount
+		mt.Currency = filter.Currency
+		result = append(result, mt)
+	}
+
+	return result, nil
+}
+
+// копия фильтра, ограниченная подписками вызывающего пользователя
+func scopeTotalFilter(ctx context.Context, filter *domain.TotalFilter) (*domain.TotalFilter, error) {
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := *filter
+	scoped.OwnerID = owner
+
+	return &scoped, nil
+}
+
+func (s *SubscriptionService) exchangeRates(ctx context.Context) (domain.ExchangeRates, error) {
+	rates, err := s.rates.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewExchangeRates(rates), nil
+}
+
+// пересчитывает сумму в валюту отчета; для нулевых сумм курс не нужен
+func convert(rates domain.ExchangeRates, m domain.Money, currency string) (int, error) {
+	if m.Amount == 0 {
+		return 0, nil
+	}
+
+	return rates.Convert(m, currency)
+}
+
+func sameGroup(a, b *domain.GroupedTotal) bool {
+	if (a.ServiceName == nil) != (b.ServiceName == nil) || (a.UserID == nil) != (b.UserID == nil) {
+		return false
+	}
+
+	if a.ServiceName != nil && *a.ServiceName != *b.ServiceName {
+		return false
+	}
+
+	if a.UserID != nil && *a.UserID != *b.UserID {
+		return false
+	}
+
+	return true
 }
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testTask/internal/domain"
+	"testTask/internal/repository"
+	"testTask/internal/repository/memory"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type subscriptionTest struct {
+	service    *SubscriptionService
+	subs       repository.SubscriptionRepository
+	audit      repository.AuditRepository
+	outbox     repository.OutboxRepository
+	deliveries repository.WebhookDeliveryRepository
+	webhook    *domain.Webhook
+	ctx        context.Context
+}
+
+// хранилище с сервисом Netflix и вебхуком администратора на создание подписок
+func newSubscriptionTest(t *testing.T) *subscriptionTest {
+	t.Helper()
+
+	store := memory.NewStore()
+	ctx := domain.WithPrincipal(context.Background(), domain.SystemPrincipal(domain.DefaultTenant))
+
+	services := memory.NewServiceRepository(store)
+	if err := services.Create(ctx, &domain.Service{ID: uuid.New(), Name: "Netflix", Currency: domain.DefaultCurrency}); err != nil {
+		t.Fatalf("create service: %v", err)
+	}
+
+	webhook := &domain.Webhook{
+		ID:         uuid.New(),
+		URL:        "https://hooks.example.com",
+		Secret:     "0123456789abcdef",
+		EventTypes: []domain.EventType{domain.EventSubscriptionCreated},
+		Active:     true,
+	}
+	if err := memory.NewWebhookRepository(store).Create(ctx, webhook); err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+
+	it := &subscriptionTest{
+		subs:       memory.NewSubscriptionRepository(store),
+		audit:      memory.NewAuditRepository(store),
+		outbox:     memory.NewOutboxRepository(store),
+		deliveries: memory.NewWebhookDeliveryRepository(store),
+		webhook:    webhook,
+		ctx:        ctx,
+	}
+	it.service = NewSubscriptionService(
+		it.subs,
+		services,
+		memory.NewExchangeRateRepository(store),
+		memory.NewPriceHistoryRepository(store),
+		it.audit,
+		it.outbox,
+		it.deliveries,
+		memory.NewTransactor(store),
+		30*24*time.Hour,
+	)
+
+	return it
+}
+
+// строки 2 и 4 - подписки на Netflix, строка 3 - на сервис, которого нет в каталоге
+func importRows() []domain.ImportRow {
+	row := func(line int, service string, price int) domain.ImportRow {
+		return domain.ImportRow{Row: line, Subscription: domain.Subscription{
+			ServiceName:   service,
+			Price:         price,
+			BillingPeriod: domain.BillingPeriodMonth,
+			UserID:        uuid.New(),
+			StartDate:     time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		}}
+	}
+
+	return []domain.ImportRow{row(2, "Netflix", 39900), row(3, "Unknown", 100), row(4, "netflix", 49900)}
+}
+
+func (it *subscriptionTest) auditEntries(t *testing.T) []domain.AuditEntry {
+	t.Helper()
+
+	entries, err := it.audit.List(it.ctx, &domain.AuditFilter{Limit: 100})
+	if err != nil {
+		t.Fatalf("list audit: %v", err)
+	}
+
+	return entries
+}
+
+func (it *subscriptionTest) checkResult(t *testing.T, result *domain.ImportResult, imported int) {
+	t.Helper()
+
+	if result.Rows != 3 || result.Valid != 2 || result.Imported != imported {
+		t.Errorf("result rows %d, valid %d, imported %d; want 3, 2, %d",
+			result.Rows, result.Valid, result.Imported, imported)
+	}
+
+	if len(result.Errors) != 1 || result.Errors[0].Row != 3 ||
+		len(result.Errors[0].Fields) != 1 || result.Errors[0].Fields[0].Field != "service_name" {
+		t.Errorf("errors %+v, want service_name error in row 3", result.Errors)
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	it := newSubscriptionTest(t)
+
+	result, err := it.service.Import(it.ctx, importRows(), true)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	it.checkResult(t, result, 0)
+
+	if entries := it.auditEntries(t); len(entries) != 0 {
+		t.Errorf("dry run wrote %d audit entries", len(entries))
+	}
+	if events, _ := it.outbox.Claim(it.ctx, outboxBatchSize, 0); len(events) != 0 {
+		t.Errorf("dry run added %d events", len(events))
+	}
+}
+
+func TestImport(t *testing.T) {
+	it := newSubscriptionTest(t)
+
+	result, err := it.service.Import(it.ctx, importRows(), false)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	it.checkResult(t, result, 2)
+
+	// каждая подписка сохранена с записью аудита, событием и доставкой на вебхук
+	entries := it.auditEntries(t)
+	if len(entries) != 2 {
+		t.Fatalf("audit has %d entries, want 2", len(entries))
+	}
+
+	prices := make(map[int]bool)
+	for _, e := range entries {
+		if e.Action != domain.AuditCreate {
+			t.Errorf("audit action %s, want %s", e.Action, domain.AuditCreate)
+		}
+
+		sub, err := it.subs.GetByID(it.ctx, e.SubscriptionID)
+		if err != nil {
+			t.Fatalf("get imported subscription: %v", err)
+		}
+		if sub.ServiceName != "Netflix" || sub.UserID != e.OwnerID {
+			t.Errorf("subscription %+v does not match audit entry %+v", sub, e)
+		}
+		prices[sub.Price] = true
+	}
+	if !prices[39900] || !prices[49900] {
+		t.Errorf("imported prices %v, want 39900 and 49900", prices)
+	}
+
+	events, err := it.outbox.Claim(it.ctx, outboxBatchSize, 0)
+	if err != nil {
+		t.Fatalf("claim outbox: %v", err)
+	}
+	if len(events) != 2 {
+		t.Errorf("outbox has %d events, want 2", len(events))
+	}
+	for _, m := range events {
+		if m.Event.Type != domain.EventSubscriptionCreated {
+			t.Errorf("event type %s, want %s", m.Event.Type, domain.EventSubscriptionCreated)
+		}
+	}
+
+	deliveries, err := it.deliveries.List(it.ctx, it.webhook.ID, &domain.DeliveryFilter{Limit: domain.MaxDeliveryLimit})
+	if err != nil {
+		t.Fatalf("list deliveries: %v", err)
+	}
+	if len(deliveries) != 2 {
+		t.Errorf("webhook has %d deliveries, want 2", len(deliveries))
+	}
+}
+
+// invalidField поле единственной ошибки валидации err; "" - err не ошибка валидации
+func invalidField(err error) string {
+	var verr *domain.ValidationError
+	if !errors.As(err, &verr) || len(verr.Fields) != 1 {
+		return ""
+	}
+
+	return verr.Fields[0].Field
+}
+
+func TestPriceChangeOnUpdate(t *testing.T) {
+	now := time.Date(2025, time.June, 15, 12, 0, 0, 0, time.UTC)
+	month := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	march := []domain.PriceChange{{Price: 200, EffectiveFrom: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)}}
+
+	tests := []struct {
+		name      string
+		start     time.Time
+		end       *time.Time
+		changes   []domain.PriceChange
+		price     int
+		currency  string
+		wantPrice int // начальная цена подписки после изменения
+		wantNew   int // цена изменения с текущего месяца; 0 - изменения нет
+		wantField string
+	}{
+		{name: "same price", start: start, price: 100, wantPrice: 100},
+		{name: "not started", start: month, price: 300, wantPrice: 300},
+		{name: "starts next month", start: month.AddDate(0, 1, 0), price: 300, wantPrice: 300},
+		{name: "started", start: start, price: 300, wantPrice: 100, wantNew: 300},
+		{name: "same as current month price", start: start, changes: march, price: 200, wantPrice: 100},
+		{name: "revert to original price", start: start, changes: march, price: 100, wantPrice: 100, wantNew: 100},
+		{name: "ends this month", start: start, end: &month, price: 300, wantPrice: 100, wantNew: 300},
+		{name: "ended", start: start, end: &march[0].EffectiveFrom, price: 300, wantField: "price"},
+		{name: "ended same price", start: start, end: &march[0].EffectiveFrom, price: 100, wantPrice: 100},
+		{name: "currency of started", start: start, price: 100, currency: "USD", wantField: "currency"},
+		{name: "currency of not started", start: month, price: 100, currency: "USD", wantPrice: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := &domain.Subscription{
+				ID:        uuid.New(),
+				Price:     100,
+				Currency:  domain.DefaultCurrency,
+				StartDate: tt.start,
+				EndDate:   tt.end,
+			}
+			sub := *current
+			sub.Price = tt.price
+			if tt.currency != "" {
+				sub.Currency = tt.currency
+			}
+
+			change, err := priceChangeOnUpdate(&sub, current, tt.changes, now)
+			if tt.wantField != "" {
+				if field := invalidField(err); field != tt.wantField {
+					t.Fatalf("error %v, want validation error on %s", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("priceChangeOnUpdate: %v", err)
+			}
+
+			if sub.Price != tt.wantPrice {
+				t.Errorf("subscription price %d, want %d", sub.Price, tt.wantPrice)
+			}
+
+			switch {
+			case tt.wantNew == 0 && change != nil:
+				t.Errorf("unexpected price change %+v", change)
+			case tt.wantNew != 0 && change == nil:
+				t.Errorf("no price change, want %d from %s", tt.wantNew, month.Format(time.DateOnly))
+			case change != nil && (change.Price != tt.wantNew || !change.EffectiveFrom.Equal(month) || change.SubscriptionID != sub.ID):
+				t.Errorf("price change %+v, want %d from %s", change, tt.wantNew, month.Format(time.DateOnly))
+			}
+		})
+	}
+}
+
+func TestUpdatePriceHistory(t *testing.T) {
+	it := newSubscriptionTest(t)
+
+	now := time.Now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	sub := &domain.Subscription{
+		ServiceName:   "Netflix",
+		Price:         100,
+		Currency:      domain.DefaultCurrency,
+		BillingPeriod: domain.BillingPeriodMonth,
+		UserID:        uuid.New(),
+		StartDate:     month.AddDate(-1, 0, 0),
+	}
+	if err := it.service.Create(it.ctx, sub); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	update := func(price int, currency string) error {
+		patch := *sub
+		patch.Price = price
+		patch.Currency = currency
+		return it.service.Update(it.ctx, &patch)
+	}
+
+	checkHistory := func(want int) {
+		t.Helper()
+
+		stored, err := it.subs.GetByID(it.ctx, sub.ID)
+		if err != nil {
+			t.Fatalf("get subscription: %v", err)
+		}
+		if stored.Price != 100 {
+			t.Errorf("original price %d, want 100", stored.Price)
+		}
+
+		changes, err := it.service.ListPriceChanges(it.ctx, sub.ID)
+		if err != nil {
+			t.Fatalf("ListPriceChanges: %v", err)
+		}
+		if len(changes) != 1 || changes[0].Price != want || !changes[0].EffectiveFrom.Equal(month) {
+			t.Errorf("price history %+v, want %d from %s", changes, want, month.Format(time.DateOnly))
+		}
+	}
+
+	if err := update(300, domain.DefaultCurrency); err != nil {
+		t.Fatalf("Update price: %v", err)
+	}
+	checkHistory(300)
+
+	// возврат к начальной цене - тоже изменение цены текущего месяца
+	if err := update(100, domain.DefaultCurrency); err != nil {
+		t.Fatalf("Update to original price: %v", err)
+	}
+	checkHistory(100)
+
+	if err := update(100, "USD"); invalidField(err) != "currency" {
+		t.Errorf("currency change: %v, want validation error on currency", err)
+	}
+	checkHistory(100)
+}
+
+func TestUpdateWithPriceChangeRecordsOnce(t *testing.T) {
+	it := newSubscriptionTest(t)
+
+	now := time.Now().UTC()
+	sub := &domain.Subscription{
+		ServiceName:   "Netflix",
+		Price:         100,
+		Currency:      domain.DefaultCurrency,
+		BillingPeriod: domain.BillingPeriodMonth,
+		UserID:        uuid.New(),
+		StartDate:     time.Date(now.Year()-1, now.Month(), 1, 0, 0, 0, 0, time.UTC),
+	}
+	if err := it.service.Create(it.ctx, sub); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := it.outbox.Claim(it.ctx, outboxBatchSize, time.Hour); err != nil {
+		t.Fatalf("claim create event: %v", err)
+	}
+
+	patch := *sub
+	patch.Price = 300
+	if err := it.service.Update(it.ctx, &patch); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	var updates []domain.AuditEntry
+	for _, e := range it.auditEntries(t) {
+		if e.Action != domain.AuditCreate {
+			updates = append(updates, e)
+		}
+	}
+	if len(updates) != 1 || updates[0].Action != domain.AuditUpdate {
+		t.Fatalf("audit entries %+v, want one %s", updates, domain.AuditUpdate)
+	}
+
+	var after struct {
+		Price       int
+		PriceChange *domain.PriceChange
+	}
+	if err := json.Unmarshal(updates[0].After, &after); err != nil {
+		t.Fatalf("unmarshal audit state: %v", err)
+	}
+	if after.Price != 100 || after.PriceChange == nil || after.PriceChange.Price != 300 {
+		t.Errorf("audit state after %s, want price 100 and price change to 300", updates[0].After)
+	}
+
+	events, err := it.outbox.Claim(it.ctx, outboxBatchSize, 0)
+	if err != nil {
+		t.Fatalf("claim outbox: %v", err)
+	}
+	if len(events) != 1 || events[0].Event.Type != domain.EventSubscriptionUpdated {
+		t.Errorf("outbox has %d events, want one %s", len(events), domain.EventSubscriptionUpdated)
+	}
+}
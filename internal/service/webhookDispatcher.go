@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"testTask/internal/domain"
+	"testTask/internal/publisher"
+	"testTask/internal/repository"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	webhookBatchSize = 20
+	// на это время выбранные доставки закрепляются за процессом доставки
+	webhookLease = 5 * time.Minute
+	// длина текста ошибки, сохраняемого в журнале доставок
+	maxDeliveryErrorLength = 1000
+)
+
+// WebhookDispatcher доставляет события на вебхуки клиентов. Неудачная доставка
+// повторяется с экспоненциальной задержкой, после maxAttempts попыток доставка
+// переходит в состояние dead. Несколько экземпляров приложения могут работать
+// одновременно: выбранные доставки закрепляются за процессом на время webhookLease
+type WebhookDispatcher struct {
+	deliveries  repository.WebhookDeliveryRepository
+	client      *http.Client
+	maxAttempts int
+	interval    time.Duration
+}
+
+func NewWebhookDispatcher(
+	deliveries repository.WebhookDeliveryRepository,
+	client *http.Client,
+	maxAttempts int,
+	interval time.Duration,
+) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		deliveries:  deliveries,
+		client:      client,
+		maxAttempts: maxAttempts,
+		interval:    interval,
+	}
+}
+
+// Run доставляет события до отмены ctx
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := d.ProcessBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("webhook dispatcher failed", "error", err)
+		}
+		if n == webhookBatchSize && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch выполняет одну пачку доставок и возвращает число обработанных.
+// Запросы выполняются вне транзакции, результат каждой попытки записывается отдельно
+func (d *WebhookDispatcher) ProcessBatch(ctx context.Context) (int, error) {
+	jobs, err := d.deliveries.ClaimDue(ctx, webhookBatchSize, webhookLease)
+	if err != nil {
+		return 0, err
+	}
+
+	// после окончания аренды доставку может взять другой процесс, поэтому новые
+	// запросы начинаются не позже половины аренды и не переживают ее;
+	// оставшиеся доставки вернутся в очередь
+	claimedAt := time.Now()
+	postCtx, cancel := context.WithDeadline(ctx, claimedAt.Add(webhookLease))
+	defer cancel()
+
+	var processed int
+	for i := range jobs {
+		if time.Since(claimedAt) > webhookLease/2 {
+			break
+		}
+
+		attempt := d.deliver(postCtx, &jobs[i])
+		if err := d.deliveries.RecordAttempt(ctx, jobs[i].Delivery.ID, attempt); err != nil {
+			return processed, err
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, job *domain.WebhookJob) *domain.DeliveryAttempt {
+	delivery := &job.Delivery
+
+	header := http.Header{}
+	header.Set(publisher.EventIDHeader, delivery.EventID.String())
+	header.Set(publisher.EventTypeHeader, string(delivery.EventType))
+	header.Set(publisher.DeliveryIDHeader, strconv.FormatInt(delivery.ID, 10))
+
+	status, err := publisher.PostJSON(ctx, d.client, job.URL, delivery.Payload, []byte(job.Secret), header)
+	if err == nil && status >= 200 && status < 300 {
+		return &domain.DeliveryAttempt{Status: domain.DeliveryDelivered, StatusCode: status}
+	}
+
+	attempt := &domain.DeliveryAttempt{Status: domain.DeliveryPending, StatusCode: status}
+	if err != nil {
+		attempt.Error = truncate(err.Error(), maxDeliveryErrorLength)
+	} else {
+		attempt.Error = fmt.Sprintf("unexpected status %d", status)
+	}
+
+	if delivery.Attempts+1 >= d.maxAttempts {
+		attempt.Status = domain.DeliveryDead
+	} else {
+		attempt.RetryAfter = retryBackoff(delivery.Attempts)
+	}
+
+	slog.Warn("webhook delivery failed",
+		"delivery_id", delivery.ID,
+		"webhook_id", delivery.WebhookID,
+		"attempt", delivery.Attempts+1,
+		"status", attempt.Status,
+		"error", attempt.Error,
+	)
+
+	return attempt
+}
+
+// truncate обрезает s до n байт, не разрывая символ UTF-8
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testTask/internal/domain"
+	"testTask/internal/publisher"
+	"testTask/internal/repository"
+	"testTask/internal/repository/memory"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"error", 10, "error"},
+		{"error", 5, "error"},
+		{"error", 3, "err"},
+		{"ошибка", 12, "ошибка"},
+		// 5 байт приходятся на середину третьего символа
+		{"ошибка", 5, "ош"},
+		{"ошибка", 4, "ош"},
+		{"ошибка", 1, ""},
+		{"€uro", 2, ""},
+	}
+
+	for _, tt := range tests {
+		got := truncate(tt.s, tt.n)
+		if got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncate(%q, %d) = %q is not valid UTF-8", tt.s, tt.n, got)
+		}
+	}
+}
+
+func newTestDispatcher(t *testing.T, handler http.HandlerFunc) (*WebhookDispatcher, repository.WebhookDeliveryRepository, *domain.Webhook) {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	store := memory.NewStore()
+	webhooks := memory.NewWebhookRepository(store)
+	deliveries := memory.NewWebhookDeliveryRepository(store)
+
+	ctx := domain.WithPrincipal(context.Background(), domain.SystemPrincipal(domain.DefaultTenant))
+	w := &domain.Webhook{
+		ID:         uuid.New(),
+		URL:        srv.URL,
+		Secret:     "0123456789abcdef",
+		EventTypes: []domain.EventType{domain.EventSubscriptionCreated},
+		Active:     true,
+	}
+	if err := webhooks.Create(ctx, w); err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+
+	// тестовый сервер слушает loopback, поэтому клиент без проверки адреса
+	return NewWebhookDispatcher(deliveries, srv.Client(), 2, time.Second), deliveries, w
+}
+
+func enqueueEvent(t *testing.T, deliveries repository.WebhookDeliveryRepository) {
+	t.Helper()
+
+	e := &domain.Event{
+		ID:             uuid.New(),
+		Type:           domain.EventSubscriptionCreated,
+		TenantID:       domain.DefaultTenant,
+		SubscriptionID: uuid.New(),
+		Data:           json.RawMessage(`{}`),
+	}
+	if err := deliveries.Enqueue(context.Background(), e, uuid.New()); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+}
+
+func listDeliveries(t *testing.T, deliveries repository.WebhookDeliveryRepository, webhookID uuid.UUID) []domain.WebhookDelivery {
+	t.Helper()
+
+	ctx := domain.WithPrincipal(context.Background(), domain.SystemPrincipal(domain.DefaultTenant))
+	list, err := deliveries.List(ctx, webhookID, &domain.DeliveryFilter{Limit: domain.MaxDeliveryLimit})
+	if err != nil {
+		t.Fatalf("list deliveries: %v", err)
+	}
+
+	return list
+}
+
+func processDeliveries(t *testing.T, d *WebhookDispatcher, want int) {
+	t.Helper()
+
+	n, err := d.ProcessBatch(context.Background())
+	if err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+	if n != want {
+		t.Fatalf("ProcessBatch processed %d deliveries, want %d", n, want)
+	}
+}
+
+func TestWebhookDispatcherDelivers(t *testing.T) {
+	var requests atomic.Int32
+	d, deliveries, w := newTestDispatcher(t, func(rw http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get(publisher.SignatureHeader) == "" {
+			t.Error("request is not signed")
+		}
+	})
+	enqueueEvent(t, deliveries)
+	enqueueEvent(t, deliveries)
+
+	processDeliveries(t, d, 2)
+
+	if got := requests.Load(); got != 2 {
+		t.Errorf("webhook received %d requests, want 2", got)
+	}
+	for _, delivery := range listDeliveries(t, deliveries, w.ID) {
+		if delivery.Status != domain.DeliveryDelivered || delivery.Attempts != 1 {
+			t.Errorf("delivery %d: status %s, attempts %d; want delivered after 1 attempt",
+				delivery.ID, delivery.Status, delivery.Attempts)
+		}
+	}
+
+	// доставленные не выбираются повторно
+	processDeliveries(t, d, 0)
+}
+
+func TestWebhookDispatcherRetriesThenDies(t *testing.T) {
+	d, deliveries, w := newTestDispatcher(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	})
+	enqueueEvent(t, deliveries)
+
+	processDeliveries(t, d, 1)
+
+	list := listDeliveries(t, deliveries, w.ID)
+	if len(list) != 1 || list[0].Status != domain.DeliveryPending || list[0].Attempts != 1 {
+		t.Fatalf("after first attempt: %+v, want pending with 1 attempt", list)
+	}
+	if list[0].LastStatusCode == nil || *list[0].LastStatusCode != http.StatusInternalServerError {
+		t.Errorf("last status code %v, want 500", list[0].LastStatusCode)
+	}
+
+	// до окончания задержки доставка не выполняется
+	processDeliveries(t, d, 0)
+
+	time.Sleep(minRetryBackoff + 50*time.Millisecond)
+	processDeliveries(t, d, 1)
+
+	list = listDeliveries(t, deliveries, w.ID)
+	if list[0].Status != domain.DeliveryDead || list[0].Attempts != 2 {
+		t.Errorf("after last attempt: status %s, attempts %d; want dead after 2 attempts", list[0].Status, list[0].Attempts)
+	}
+}
+
+func TestWebhookDispatcherSkipsClaimedDeliveries(t *testing.T) {
+	d, deliveries, _ := newTestDispatcher(t, func(rw http.ResponseWriter, r *http.Request) {})
+	enqueueEvent(t, deliveries)
+	enqueueEvent(t, deliveries)
+
+	// первую доставку уже выполняет другой экземпляр приложения
+	claimed, err := deliveries.ClaimDue(context.Background(), 1, time.Minute)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("ClaimDue = %v, %v; want one delivery", claimed, err)
+	}
+
+	processDeliveries(t, d, 1)
+	processDeliveries(t, d, 0)
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"testTask/internal/domain"
+	"testTask/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// WebhookService управляет вебхуками клиентов. Пользователь видит и меняет только
+// свои вебхуки, администратор - все вебхуки арендатора
+type WebhookService struct {
+	webhooks   repository.WebhookRepository
+	deliveries repository.WebhookDeliveryRepository
+}
+
+func NewWebhookService(webhooks repository.WebhookRepository, deliveries repository.WebhookDeliveryRepository) *WebhookService {
+	return &WebhookService{webhooks: webhooks, deliveries: deliveries}
+}
+
+// Create регистрирует вебхук и возвращает секрет подписи. Если секрет не задан,
+// он генерируется; получить его повторно нельзя
+func (s *WebhookService) Create(ctx context.Context, w *domain.Webhook) (string, error) {
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if w.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return "", err
+		}
+		w.Secret = hex.EncodeToString(secret)
+	}
+
+	w.ID = uuid.New()
+	w.OwnerID = owner
+
+	if err := w.Validate(); err != nil {
+		return "", err
+	}
+
+	if err := s.webhooks.Create(ctx, w); err != nil {
+		return "", err
+	}
+
+	return w.Secret, nil
+}
+
+// Get возвращает вебхук, если он доступен клиенту; чужой вебхук неотличим от несуществующего
+func (s *WebhookService) Get(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := s.webhooks.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if owner != nil && (w.OwnerID == nil || *w.OwnerID != *owner) {
+		return nil, domain.ErrWebhookNotFound
+	}
+
+	return w, nil
+}
+
+func (s *WebhookService) List(ctx context.Context) ([]domain.Webhook, error) {
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.webhooks.List(ctx, owner)
+}
+
+// Update сохраняет изменения вебхука; владелец не меняется
+func (s *WebhookService) Update(ctx context.Context, w *domain.Webhook) error {
+	current, err := s.Get(ctx, w.ID)
+	if err != nil {
+		return err
+	}
+	w.OwnerID = current.OwnerID
+
+	if err := w.Validate(); err != nil {
+		return err
+	}
+
+	return s.webhooks.Update(ctx, w)
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+
+	return s.webhooks.Delete(ctx, id)
+}
+
+// Deliveries журнал доставок вебхука. Запрашивается на одну запись больше лимита,
+// чтобы понять, есть ли следующая страница
+func (s *WebhookService) Deliveries(ctx context.Context, webhookID uuid.UUID, filter *domain.DeliveryFilter) (*domain.DeliveryPage, error) {
+	if _, err := s.Get(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	query := *filter
+	query.Limit++
+
+	deliveries, err := s.deliveries.List(ctx, webhookID, &query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.DeliveryPage{Items: deliveries}
+	if len(deliveries) > filter.Limit {
+		page.Items = deliveries[:filter.Limit]
+		next := page.Items[filter.Limit-1].ID
+		page.NextBeforeID = &next
+	}
+
+	return page, nil
+}
+
+// Redeliver возвращает в очередь доставку, исчерпавшую попытки
+func (s *WebhookService) Redeliver(ctx context.Context, webhookID uuid.UUID, deliveryID int64) (*domain.WebhookDelivery, error) {
+	if _, err := s.Get(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	d, err := s.deliveries.GetByID(ctx, webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.Status != domain.DeliveryDead {
+		return nil, domain.ErrWebhookDeliveryNotDead
+	}
+
+	if err := s.deliveries.Requeue(ctx, deliveryID); err != nil {
+		return nil, err
+	}
+
+	return s.deliveries.GetByID(ctx, webhookID, deliveryID)
+}
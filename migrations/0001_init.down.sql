@@ -1 +1,3 @@
-DROP TABLE IF EXISTS subscriptions;
\ No newline at end of file
+DROP TABLE IF EXISTS subscriptions;
+
+DROP EXTENSION IF EXISTS pgcrypto;
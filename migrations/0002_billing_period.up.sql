@@ -0,0 +1,4 @@
+-- периодичность списаний; все существующие подписки считаются помесячными
+ALTER TABLE subscriptions
+    ADD COLUMN billing_period TEXT NOT NULL DEFAULT 'month'
+        CHECK (billing_period IN ('week', 'month', 'quarter', 'year'));
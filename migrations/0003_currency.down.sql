@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS currency_rates;
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS currency;
+
+UPDATE subscriptions
+SET price = price / 100;
+
+ALTER TABLE subscriptions
+    ALTER COLUMN price TYPE INTEGER;
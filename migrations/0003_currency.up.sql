@@ -0,0 +1,21 @@
+-- цены хранятся в минорных единицах валюты (копейки, центы)
+ALTER TABLE subscriptions
+    ALTER COLUMN price TYPE BIGINT;
+
+UPDATE subscriptions
+SET price = price * 100;
+
+ALTER TABLE subscriptions
+    ADD COLUMN currency TEXT NOT NULL DEFAULT 'RUB'
+        CHECK (currency ~ '^[A-Z]{3}$');
+
+-- 1 единица base_currency стоит rate единиц quote_currency
+CREATE TABLE currency_rates
+(
+    base_currency  TEXT            NOT NULL CHECK (base_currency ~ '^[A-Z]{3}$'),
+    quote_currency TEXT            NOT NULL CHECK (quote_currency ~ '^[A-Z]{3}$'),
+    rate           NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    updated_at     TIMESTAMP       NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (base_currency, quote_currency),
+    CHECK (base_currency <> quote_currency)
+);
@@ -0,0 +1,10 @@
+-- история цен: subscriptions.price действует с start_date до первого изменения,
+-- далее - цена последнего изменения с effective_from не позже даты списания
+CREATE TABLE subscription_prices
+(
+    subscription_id UUID      NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    effective_from  DATE      NOT NULL,
+    price           BIGINT    NOT NULL CHECK (price > 0),
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (subscription_id, effective_from)
+);
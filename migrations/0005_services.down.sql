@@ -0,0 +1,19 @@
+ALTER TABLE subscriptions
+    ADD COLUMN service_name TEXT;
+
+UPDATE subscriptions s
+SET service_name = sv.name
+FROM services sv
+WHERE sv.id = s.service_id;
+
+ALTER TABLE subscriptions
+    ALTER COLUMN service_name SET NOT NULL;
+
+CREATE INDEX idx_subscriptions_service_name
+    ON subscriptions (service_name);
+
+DROP INDEX IF EXISTS idx_subscriptions_service_id;
+ALTER TABLE subscriptions DROP COLUMN service_id;
+
+DROP TABLE IF EXISTS service_aliases;
+DROP TABLE IF EXISTS services;
@@ -0,0 +1,48 @@
+-- каталог сервисов: подписка ссылается на сервис по UUID вместо произвольного названия
+CREATE TABLE services
+(
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name          TEXT      NOT NULL CHECK (name <> ''),
+    category      TEXT      NOT NULL DEFAULT '',
+--    цена по умолчанию для новых подписок, в минорных единицах currency
+    default_price BIGINT CHECK (default_price > 0),
+    currency      TEXT      NOT NULL DEFAULT 'RUB' CHECK (currency ~ '^[A-Z]{3}$'),
+    website       TEXT      NOT NULL DEFAULT '',
+    created_at    TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- "Yandex Plus" и "yandex plus" - один сервис
+CREATE UNIQUE INDEX idx_services_name ON services (lower(name));
+
+-- другие названия того же сервиса, например "Яндекс Плюс"
+CREATE TABLE service_aliases
+(
+    service_id UUID NOT NULL REFERENCES services (id) ON DELETE CASCADE,
+    alias      TEXT NOT NULL CHECK (alias <> '')
+);
+
+CREATE UNIQUE INDEX idx_service_aliases_alias ON service_aliases (lower(alias));
+CREATE INDEX idx_service_aliases_service_id ON service_aliases (service_id);
+
+-- переносим существующие названия в каталог, варианты в разном регистре склеиваются
+INSERT INTO services (name)
+SELECT min(service_name)
+FROM subscriptions
+GROUP BY lower(service_name);
+
+ALTER TABLE subscriptions
+    ADD COLUMN service_id UUID REFERENCES services (id);
+
+UPDATE subscriptions s
+SET service_id = sv.id
+FROM services sv
+WHERE lower(sv.name) = lower(s.service_name);
+
+ALTER TABLE subscriptions
+    ALTER COLUMN service_id SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_subscriptions_service_name;
+ALTER TABLE subscriptions DROP COLUMN service_name;
+
+CREATE INDEX idx_subscriptions_service_id ON subscriptions (service_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_subscriptions_created_at_id;
+
+ALTER TABLE subscriptions
+    ALTER COLUMN created_at DROP NOT NULL;
@@ -0,0 +1,9 @@
+-- created_at участвует в keyset-пагинации списка, поэтому не может быть NULL
+UPDATE subscriptions
+SET created_at = NOW()
+WHERE created_at IS NULL;
+
+ALTER TABLE subscriptions
+    ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX idx_subscriptions_created_at_id ON subscriptions (created_at, id);
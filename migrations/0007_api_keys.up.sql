@@ -0,0 +1,12 @@
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    -- открытая часть ключа для поиска; сам ключ хранится только в виде хеша
+    prefix TEXT NOT NULL UNIQUE,
+    key_hash BYTEA NOT NULL,
+    admin BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    last_used_at TIMESTAMP
+);
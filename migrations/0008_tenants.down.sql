@@ -0,0 +1,15 @@
+DROP POLICY IF EXISTS subscription_prices_tenant_isolation ON subscription_prices;
+ALTER TABLE subscription_prices DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS subscriptions_tenant_isolation ON subscriptions;
+ALTER TABLE subscriptions DISABLE ROW LEVEL SECURITY;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS tenant_id;
+
+DROP INDEX IF EXISTS idx_subscriptions_tenant_user_id;
+CREATE INDEX idx_subscriptions_user_id ON subscriptions (user_id);
+
+DROP INDEX IF EXISTS idx_subscriptions_tenant_created_at_id;
+CREATE INDEX idx_subscriptions_created_at_id ON subscriptions (created_at, id);
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,33 @@
+-- существующие данные попадают в арендатора по умолчанию
+ALTER TABLE subscriptions
+    ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default'
+        CHECK (tenant_id ~ '^[a-z0-9][a-z0-9_-]{0,62}$');
+
+-- дальше арендатор всегда задается приложением явно
+ALTER TABLE subscriptions
+    ALTER COLUMN tenant_id DROP DEFAULT;
+
+DROP INDEX idx_subscriptions_created_at_id;
+CREATE INDEX idx_subscriptions_tenant_created_at_id ON subscriptions (tenant_id, created_at, id);
+
+DROP INDEX idx_subscriptions_user_id;
+CREATE INDEX idx_subscriptions_tenant_user_id ON subscriptions (tenant_id, user_id);
+
+-- ключ, привязанный к арендатору, дает доступ только к его данным
+ALTER TABLE api_keys
+    ADD COLUMN tenant_id TEXT;
+
+-- Row level security - дополнительная защита на случай ошибки в запросе.
+-- Политики действуют, только если приложение подключается не владельцем таблиц
+-- и задает app.tenant_id (DB_ROW_LEVEL_SECURITY=true)
+ALTER TABLE subscriptions ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY subscriptions_tenant_isolation ON subscriptions
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+-- история цен видна, только если видна сама подписка
+ALTER TABLE subscription_prices ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY subscription_prices_tenant_isolation ON subscription_prices
+    USING (EXISTS (SELECT 1 FROM subscriptions s WHERE s.id = subscription_id));
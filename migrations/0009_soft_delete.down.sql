@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_subscriptions_deleted_at;
+
+-- удаленные подписки при откате удаляются окончательно
+DELETE FROM subscriptions WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE subscriptions
+    DROP COLUMN deleted_at;
@@ -0,0 +1,8 @@
+-- удаленная подписка остается в БД до очистки по истечении срока хранения
+ALTER TABLE subscriptions
+    ADD COLUMN deleted_at TIMESTAMP;
+
+-- для очистки удаленных подписок; активные в индекс не попадают
+CREATE INDEX idx_subscriptions_deleted_at
+    ON subscriptions (tenant_id, deleted_at)
+    WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS subscription_audit;
+
+DROP FUNCTION IF EXISTS subscription_audit_immutable();
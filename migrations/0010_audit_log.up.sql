@@ -0,0 +1,40 @@
+-- журнал изменений подписок. Внешнего ключа на subscriptions нет:
+-- записи остаются и после окончательного удаления подписки
+CREATE TABLE subscription_audit (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    subscription_id UUID NOT NULL,
+    owner_id UUID NOT NULL,
+    action TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    actor_type TEXT NOT NULL DEFAULT '',
+    actor_user_id UUID,
+    state_before JSONB,
+    state_after JSONB,
+    request_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_subscription_audit_tenant_id ON subscription_audit (tenant_id, id);
+CREATE INDEX idx_subscription_audit_subscription ON subscription_audit (tenant_id, subscription_id, id);
+
+-- записи журнала нельзя изменить или удалить даже в обход приложения
+CREATE FUNCTION subscription_audit_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'subscription_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER subscription_audit_no_update
+    BEFORE UPDATE OR DELETE ON subscription_audit
+    FOR EACH ROW EXECUTE FUNCTION subscription_audit_immutable();
+
+CREATE TRIGGER subscription_audit_no_truncate
+    BEFORE TRUNCATE ON subscription_audit
+    FOR EACH STATEMENT EXECUTE FUNCTION subscription_audit_immutable();
+
+ALTER TABLE subscription_audit ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY subscription_audit_tenant_isolation ON subscription_audit
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
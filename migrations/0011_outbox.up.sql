@@ -0,0 +1,17 @@
+-- события, сохраненные в транзакции изменения подписки и ожидающие публикации.
+-- Таблицу читает фоновый процесс без арендатора, поэтому RLS на ней не включается
+CREATE TABLE outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE,
+    tenant_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    subscription_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    published_at TIMESTAMP
+);
+
+CREATE INDEX idx_outbox_pending ON outbox (next_attempt_at, id) WHERE published_at IS NULL;
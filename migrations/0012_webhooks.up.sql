@@ -0,0 +1,38 @@
+-- вебхуки клиентов; owner_id NULL - вебхук администратора, получает события
+-- обо всех подписках арендатора. Секрет хранится открыто: он нужен для подписи
+CREATE TABLE webhooks (
+    id UUID PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    owner_id UUID,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types TEXT[] NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhooks_tenant_owner ON webhooks (tenant_id, owner_id);
+
+-- доставки событий на вебхуки. Таблицы читает фоновый процесс без арендатора,
+-- поэтому RLS на них не включается
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    webhook_id UUID NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP DEFAULT NOW(),
+    last_status_code INTEGER,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP,
+    -- повторная постановка того же события не создает дубль
+    UNIQUE (webhook_id, event_id)
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at, id) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, id);
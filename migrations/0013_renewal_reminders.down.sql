@@ -0,0 +1,2 @@
+DROP FUNCTION IF EXISTS subscription_tenants();
+DROP TABLE IF EXISTS renewal_reminders;
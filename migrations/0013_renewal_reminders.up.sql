@@ -0,0 +1,26 @@
+-- отправленные напоминания о списаниях. Запись создается в транзакции отправки:
+-- первичный ключ не дает отправить напоминание об одном списании дважды
+-- ни после перезапуска, ни с нескольких экземпляров приложения
+CREATE TABLE renewal_reminders (
+    subscription_id UUID NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    charge_date DATE NOT NULL,
+    tenant_id TEXT NOT NULL,
+    sent_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (subscription_id, charge_date)
+);
+
+ALTER TABLE renewal_reminders ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY renewal_reminders_tenant_isolation ON renewal_reminders
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+-- арендаторы с подписками для фонового процесса напоминаний. Он работает вне
+-- запроса клиента, поэтому функция выполняется от владельца таблиц в обход RLS;
+-- сами подписки процесс читает уже от имени каждого арендатора
+CREATE FUNCTION subscription_tenants() RETURNS SETOF TEXT
+    LANGUAGE sql STABLE SECURITY DEFINER
+    SET search_path = pg_catalog, public
+AS $$
+    SELECT DISTINCT tenant_id FROM subscriptions WHERE deleted_at IS NULL
+$$;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS subscription_pauses;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS cancel_at,
+    DROP COLUMN IF EXISTS trial_until,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,24 @@
+-- состояние подписки. Пробный период - не отдельное состояние, а активная
+-- подписка до trial_until: списания раньше этой даты не выставляются.
+-- cancel_at - первое списание, которое уже не выставляется после отмены
+ALTER TABLE subscriptions
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'paused', 'cancelled')),
+    ADD COLUMN trial_until DATE,
+    ADD COLUMN cancel_at DATE;
+
+-- приостановки подписки: списания с paused_from до resumed_at не выставляются;
+-- resumed_at NULL - подписка на паузе сейчас
+CREATE TABLE subscription_pauses (
+    subscription_id UUID NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    tenant_id TEXT NOT NULL,
+    paused_from DATE NOT NULL,
+    resumed_at DATE,
+    PRIMARY KEY (subscription_id, paused_from),
+    CHECK (resumed_at IS NULL OR resumed_at >= paused_from)
+);
+
+ALTER TABLE subscription_pauses ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY subscription_pauses_tenant_isolation ON subscription_pauses
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
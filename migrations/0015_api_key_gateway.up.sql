@@ -0,0 +1,2 @@
+-- заголовок пользователя TRUSTED_USER_HEADER принимается только от ключей шлюза
+ALTER TABLE api_keys ADD COLUMN gateway BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+ALTER TABLE renewal_reminders
+    DROP COLUMN IF EXISTS locked_until,
+    DROP COLUMN IF EXISTS notified_at;
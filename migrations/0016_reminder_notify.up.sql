@@ -0,0 +1,9 @@
+-- уведомление отправляется после фиксации отметки, вне транзакции: запись
+-- закрепляется за процессом до locked_until, а notified_at ставится после отправки.
+-- Напоминание без notified_at с истекшей арендой отправляется повторно
+ALTER TABLE renewal_reminders
+    ADD COLUMN notified_at TIMESTAMP,
+    ADD COLUMN locked_until TIMESTAMP;
+
+-- прежде запись создавалась только вместе с отправленным уведомлением
+UPDATE renewal_reminders SET notified_at = sent_at;
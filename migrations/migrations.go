@@ -0,0 +1,9 @@
+// Package migrations SQL-миграции схемы БД, встроенные в бинарник приложения
+package migrations
+
+import "embed"
+
+// FS файлы вида 0001_init.up.sql и 0001_init.down.sql
+//
+//go:embed *.sql
+var FS embed.FS